@@ -2,82 +2,218 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
 	"flag"
 	"fmt"
 	"log"
+	"monopoly/pkg/bots"
 	"monopoly/pkg/consoleCLI"
 	"monopoly/pkg/monopoly"
 	neatnetwork "monopoly/pkg/neat"
 	"monopoly/pkg/server"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
 
 	"github.com/yaricom/goNEAT/v4/neat"
-	"github.com/yaricom/goNEAT/v4/neat/genetics"
 )
 
+// botSpecs collects the repeated --bot flags
+type botSpecs []string
+
+func (b *botSpecs) String() string {
+	return strings.Join(*b, " ")
+}
+
+func (b *botSpecs) Set(spec string) error {
+	if _, _, err := bots.ParseSpec(spec); err != nil {
+		return err
+	}
+	*b = append(*b, spec)
+	return nil
+}
+
 func main() {
 	runConsoleMonopoly()
 }
 
 func runConsoleMonopoly() {
 	cliMode := flag.Bool("cli", false, "run in CLI client mode")
+	serveMode := flag.Bool("serve", false, "run a persistent server hosting many tables")
+	webAddr := flag.String("web", ":8080", "address of the browser board viewer, empty to disable")
+	serverAddr := flag.String("addr", "localhost:12345", "server address used by the CLI client")
+	tableID := flag.String("table", "default", "table to join in CLI client mode")
+	humans := flag.Int("humans", 0, "CLI client mode: create the table with this many human players if it does not exist")
+	password := flag.String("password", "", "join password, required from clients if set on the server")
+	token := flag.String("token", "", "CLI client mode: invite token for a seat")
+	invites := flag.Bool("invites", false, "generate a single-use invite token for every human seat")
+	certFile := flag.String("cert", "", "TLS certificate file, enables TLS together with --key")
+	keyFile := flag.String("key", "", "TLS key file")
+	useTLS := flag.Bool("tls", false, "CLI client mode: connect over TLS")
+	insecure := flag.Bool("insecure", false, "CLI client mode: do not verify the server certificate (self-signed certificates)")
+	hotSeat := flag.Int("hotseat", 0, "play with this many humans sharing one terminal, without the server")
+	allowUndo := flag.Bool("undo", false, "casual game: players may take back their last decision if nothing random happened since (new tables and hot-seat games)")
+	var specs botSpecs
+	flag.Var(&specs, "bot", "bot spec, e.g. heuristic or neat:path=genomes/trained, repeat for several bots; free seats take the bots in turn (available: "+strings.Join(bots.Names(), ", ")+")")
+	simulate := flag.Int("simulate", 0, "play this many games between the --bot bots (one seat per --bot) and print the results")
+	seed := flag.Int64("seed", 0, "simulation mode: seed of the first game, 0 for random games")
+	migrateGenome := flag.String("migrate-genome", "", "rewrite an older NEAT genome to the whole current sensor layout")
+	train := flag.String("train", "", "train NEAT networks as described by this training config, e.g. training.yaml")
+	resume := flag.String("resume", "", "continue the training saved in this output directory from its last checkpoint")
 	flag.Parse()
+	if *migrateGenome != "" {
+		if err := neatnetwork.MigrateGenomeFile(*migrateGenome); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	if *train != "" {
+		neatnetwork.TrainNetwork(*train)
+		return
+	}
+	if *resume != "" {
+		neatnetwork.ResumeTraining(*resume)
+		return
+	}
+	if len(specs) == 0 {
+		specs = botSpecs{"neat:path=" + neatnetwork.DEFAULT_GENOME}
+	}
 	if *cliMode {
-		consoleCLI.StartClient()
+		opts := consoleCLI.ClientOptions{
+			Addr:         *serverAddr,
+			TableID:      *tableID,
+			HumanPlayers: *humans,
+			AllowUndo:    *allowUndo,
+			Password:     *password,
+			Token:        *token,
+		}
+		if *useTLS {
+			opts.TLSConfig = &tls.Config{InsecureSkipVerify: *insecure}
+		}
+		consoleCLI.StartClient(opts)
 		return
 	}
 	neat.InitLogger("error")
-	bots := []server.PlayerIO{
-		loadNEATPlayer("./genomes/trained"),
-		loadNEATPlayer("./genomes/trained"),
-		loadNEATPlayer("./genomes/trained"),
-		loadNEATPlayer("./genomes/trained"),
+	if *simulate > 0 {
+		runSimulation(specs, *simulate, *seed)
+		return
 	}
-
-	// Get number of human players from user
-	var numHumanPlayers int
-	for {
-		fmt.Print("Enter number of human players (0-4): ")
-		_, err := fmt.Scan(&numHumanPlayers)
-		if err != nil || numHumanPlayers < 0 || numHumanPlayers > 4 {
-			fmt.Println("Invalid input for number of human players")
-			fmt.Println("Number must be between 0 and 4")
-			continue
+	// resolve the specs once, so that mistakes are reported before any game starts
+	for _, spec := range specs {
+		if _, err := bots.New(spec); err != nil {
+			log.Fatal(err)
+		}
+	}
+	newBots := func(ctx context.Context, count int) []server.PlayerIO {
+		players := make([]server.PlayerIO, count)
+		for i := range players {
+			bot, err := bots.New(specs[i%len(specs)])
+			if err != nil {
+				log.Fatal(err)
+			}
+			if setter, ok := bot.(bots.ContextSetter); ok {
+				setter.SetContext(ctx)
+			}
+			players[i] = bot
+		}
+		return players
+	}
+	if *hotSeat > 0 {
+		if *hotSeat > server.TABLE_SEATS {
+			log.Fatalf("At most %d human players can play", server.TABLE_SEATS)
+		}
+		ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM)
+		defer stop()
+		hotSeatIO := consoleCLI.NewHotSeatIO(*hotSeat, newBots(ctx, server.TABLE_SEATS-*hotSeat))
+		hotSeatIO.AllowUndo = *allowUndo
+		hotSeatIO.Play(ctx)
+		return
+	}
+	var tlsConfig *tls.Config
+	if *certFile != "" || *keyFile != "" {
+		var err error
+		tlsConfig, err = server.LoadTLSConfig(*certFile, *keyFile)
+		if err != nil {
+			log.Fatal("Failed to load TLS certificate: ", err)
 		}
-		break
 	}
 
-	io := server.NewConsoleServer(numHumanPlayers, bots[:4-numHumanPlayers])
-	logger := monopoly.ConsoleLogger{}
-	logger.Init()
-	ctx := context.Background()
-	game := monopoly.NewGame(ctx, io, &logger, 0)
-	game.Start()
-
-}
+	numHumanPlayers := 0
+	if !*serveMode {
+		// Get number of human players from user
+		for {
+			fmt.Print("Enter number of human players (0-4): ")
+			_, err := fmt.Scan(&numHumanPlayers)
+			if err != nil || numHumanPlayers < 0 || numHumanPlayers > 4 {
+				fmt.Println("Invalid input for number of human players")
+				fmt.Println("Number must be between 0 and 4")
+				continue
+			}
+			break
+		}
+	}
 
-func trainNEATNetwork() {
-	neatOptionsFile := "neat_options.yaml"
-	neatGenomeFile := "./genomes/base_genome.yaml"
-	outputDir := "output"
-	neatnetwork.TrainNetwork(0, neatOptionsFile, neatGenomeFile, outputDir)
-}
+	// Ctrl+C cancels all games, clients are notified and snapshots are saved before exit
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	newLogger := func(tableID string) monopoly.Logger {
+		logger := monopoly.ConsoleLogger{}
+		logger.Init()
+		if *serveMode {
+			logger.Prefix = "[" + tableID + "] "
+		}
+		return &logger
+	}
+	manager := server.NewGameManager(ctx, newBots, newLogger)
+	manager.SnapshotDir = "snapshots"
+	manager.TLSConfig = tlsConfig
+	manager.Password = *password
+	manager.InviteTokens = *invites
+	go func() {
+		if err := manager.ListenTCP(":12345"); err != nil && ctx.Err() == nil {
+			log.Fatal("TCP server error: ", err)
+		}
+	}()
+	if *webAddr != "" {
+		go func() {
+			if err := manager.ListenWeb(*webAddr); err != nil && ctx.Err() == nil {
+				log.Fatal("Web server error: ", err)
+			}
+		}()
+	}
+	if *serveMode {
+		// Tables are created by clients joining a table that does not exist yet
+		<-ctx.Done()
+		fmt.Println("Shutting down...")
+		manager.Wait()
+		return
+	}
 
-func loadNEATPlayer(filePath string) *neatnetwork.NEATMonopolyPlayer {
-	genomeReader, err := genetics.NewGenomeReaderFromFile(filePath)
+	table, err := manager.CreateTable("default", numHumanPlayers, *allowUndo)
 	if err != nil {
-		log.Fatal("Failed to create genome reader:", err)
+		log.Fatal(err)
 	}
-	genome, err := genomeReader.Read()
-	if err != nil {
-		log.Fatal("Failed to read genome:", err)
+	for _, token := range table.Invites() {
+		fmt.Println("Invite token:", token)
 	}
-	organism, err := genetics.NewOrganism(0.0, genome, 0)
-	if err != nil {
-		log.Fatal("Failed to create organism from genome:", err)
+	<-table.Done()
+}
+
+func runSimulation(specs []string, games int, seed int64) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	result, err := bots.Simulate(ctx, specs, games, seed)
+	if err != nil && ctx.Err() == nil {
+		log.Fatal(err)
 	}
-	bot, err := neatnetwork.NewNEATMonopolyPlayer(organism)
-	if err != nil {
-		log.Fatal("Failed to create NEAT player from organism:", err)
+	fmt.Printf("%d games, %d draws, %d cancelled", result.Games, result.Draws, result.Cancelled)
+	if finished := result.Games - result.Cancelled; finished > 0 {
+		fmt.Printf(", %.1f rounds on average", float64(result.Rounds)/float64(finished))
+	}
+	fmt.Println()
+	for i, spec := range result.Specs {
+		fmt.Printf("Seat %d %-30s wins: %5d (%5.1f%%)  richest at round limit: %5d\n", i, spec, result.Wins[i],
+			100*float64(result.Wins[i])/float64(max(result.Games, 1)), result.Leads[i])
 	}
-	return bot
 }
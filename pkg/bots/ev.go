@@ -0,0 +1,322 @@
+package bots
+
+import (
+	"fmt"
+	"math"
+	"monopoly/pkg/monopoly"
+	"slices"
+)
+
+const DEVELOPED_HOUSES = 3 // houses assumed on a completed set, most of the rent gain comes with the third house
+
+func init() {
+	Register("ev", func(opts Options) (Bot, error) {
+		if err := opts.Allow("horizon", "risk"); err != nil {
+			return nil, err
+		}
+		horizon, err := opts.Int("horizon", 20)
+		if err != nil {
+			return nil, err
+		}
+		risk, err := opts.Float("risk", 0.05)
+		if err != nil {
+			return nil, err
+		}
+		if horizon <= 0 || risk < 0 || risk > 1 {
+			return nil, fmt.Errorf("horizon must be positive and risk between 0 and 1")
+		}
+		return NewEVBot(horizon, risk), nil
+	})
+}
+
+// EVBot values properties by the rent they are expected to bring, computed from the landing
+// statistics of the board, see monopoly.ExpectedRent. A property is worth its mortgage value plus
+// the rent it brings in Horizon turns of every opponent, or keeps an opponent from bringing in.
+// Sets which have a single owner so far count with a part of the rent of the developed set.
+// The bot spends money while the chance of landing on a charge it cannot pay in cash stays below Risk.
+type EVBot struct {
+	Horizon int     // turns of every opponent in which a property has to pay off
+	Risk    float64 // accepted chance per turn of having to raise money for a charge
+	board   monopoly.Board
+	fields  []monopoly.FieldInfo // property index -> field
+}
+
+func NewEVBot(horizon int, risk float64) *EVBot {
+	bot := &EVBot{Horizon: horizon, Risk: risk, board: monopoly.GetBoard()}
+	for _, field := range bot.board.Fields {
+		if field.PropertyIndex >= 0 {
+			bot.fields = append(bot.fields, field)
+		}
+	}
+	slices.SortFunc(bot.fields, func(a, b monopoly.FieldInfo) int { return a.PropertyIndex - b.PropertyIndex })
+	return bot
+}
+
+// expectedRents returns the rent every property is expected to bring per opponent turn.
+// A set with a single owner counts with the rent of the developed set, weighted by the
+// squared share of the set the owner holds, as the missing properties still have to be got.
+func (bot *EVBot) expectedRents(state monopoly.GameState, owners []int) []float64 {
+	rents := make([]float64, len(owners))
+	landings := monopoly.Landings()
+	for set, properties := range bot.board.Sets {
+		owner, owned, shared := -1, 0, false
+		for _, propertyId := range properties {
+			rents[propertyId] = monopoly.ExpectedRent(state, propertyId, owners)
+			switch {
+			case owners[propertyId] < 0:
+			case owner < 0 || owner == owners[propertyId]:
+				owner = owners[propertyId]
+				owned++
+			default:
+				shared = true // nobody gets the set without a trade
+			}
+		}
+		if set == monopoly.RAILROAD || set == monopoly.UTILITY || owned == 0 || shared {
+			continue
+		}
+		share := float64(owned) / float64(len(properties))
+		for _, propertyId := range properties {
+			property, field := state.Properties[propertyId], bot.fields[propertyId]
+			if owners[propertyId] != owner || property.IsMortgaged || len(field.Rents) <= 1+DEVELOPED_HOUSES {
+				continue
+			}
+			developed := landings.PerTurn[field.FieldIndex] * float64(field.Rents[1+max(property.Houses, DEVELOPED_HOUSES)])
+			rents[propertyId] += share * share * max(developed-rents[propertyId], 0)
+		}
+	}
+	return rents
+}
+
+// payers is the number of opponents expected to pay rents during the horizon. An opponent who is
+// likely to go bankrupt sooner counts only for the part of the horizon it is expected to survive.
+func (bot *EVBot) payers(state monopoly.GameState, player int) float64 {
+	payers := 0.0
+	for _, opponent := range activeOpponents(state, player) {
+		survival := 1 / max(monopoly.BankruptcyRisk(state, opponent), 1e-9)
+		payers += min(survival/float64(bot.Horizon), 1)
+	}
+	return payers
+}
+
+// position is the money the player expects to gain per turn of every player, rents from all the opponents
+// minus the rents paid to them. Taxes are the same whoever owns what, so they are left out.
+func (bot *EVBot) position(state monopoly.GameState, player int, owners []int) float64 {
+	rents := bot.expectedRents(state, owners)
+	opponents := bot.payers(state, player)
+	position := 0.0
+	for propertyId, owner := range owners {
+		switch {
+		case owner == player:
+			position += opponents * rents[propertyId]
+		case owner >= 0 && !state.Players[owner].IsBankrupt:
+			position -= rents[propertyId]
+		}
+	}
+	return position
+}
+
+// gain is what having the property instead of other (-1 for the bank) is worth to the player.
+func (bot *EVBot) gain(state monopoly.GameState, player int, propertyId int, other int) float64 {
+	owners := monopoly.Owners(state)
+	owners[propertyId] = player
+	with := bot.position(state, player, owners)
+	owners[propertyId] = other
+	without := bot.position(state, player, owners)
+	value := float64(bot.Horizon) * (with - without)
+	if !state.Properties[propertyId].IsMortgaged {
+		value += float64(state.Properties[propertyId].Price / 2)
+	}
+	return value
+}
+
+// value is what getting the property is worth to the player. A property of the bank would otherwise
+// go to one of the opponents, the one it helps most is assumed.
+func (bot *EVBot) value(state monopoly.GameState, player int, propertyId int) float64 {
+	if owner := state.Properties[propertyId].Owner; owner != nil {
+		return bot.gain(state, player, propertyId, owner.ID)
+	}
+	value := bot.gain(state, player, propertyId, -1)
+	for _, opponent := range activeOpponents(state, player) {
+		value = max(value, bot.gain(state, player, propertyId, opponent))
+	}
+	return value
+}
+
+// budget is the money the player can spend, so that the chance of landing on a charge above the cash left stays below Risk.
+func (bot *EVBot) budget(state monopoly.GameState, player int) int {
+	money := state.Players[player].Money
+	if money <= 0 || monopoly.ChargeRisk(state, player, money) > bot.Risk {
+		return 0
+	}
+	low, high := 0, money
+	for low < high {
+		spend := (low + high + 1) / 2
+		if monopoly.ChargeRisk(state, player, money-spend) <= bot.Risk {
+			low = spend
+		} else {
+			high = spend - 1
+		}
+	}
+	return low
+}
+
+// change returns how the position of the player changes over the horizon if the state is modified.
+func (bot *EVBot) change(state monopoly.GameState, player int, modify func(s monopoly.GameState)) float64 {
+	before := bot.position(state, player, monopoly.Owners(state))
+	modified := state.Clone()
+	modify(modified)
+	return float64(bot.Horizon) * (bot.position(modified, player, monopoly.Owners(modified)) - before)
+}
+
+func (bot *EVBot) GetStdAction(player int, state monopoly.GameState, availableActions monopoly.FullActionList) monopoly.ActionDetails {
+	if state.Charge > 0 {
+		return bot.raiseMoney(player, state, availableActions)
+	}
+	budget := bot.budget(state, player)
+
+	// the house which pays for itself first, together with the houses it leads to
+	best, bestPayback := -1, math.Inf(1)
+	for _, propertyId := range availableActions.BuyHouseList {
+		if state.Properties[propertyId].HousePrice > budget {
+			continue
+		}
+		for houses := 1; houses <= state.Settings.MaxHouses; houses++ {
+			if payback := monopoly.HousePayback(state, propertyId, houses); payback < bestPayback {
+				best, bestPayback = propertyId, payback
+			}
+		}
+	}
+	if best >= 0 && bestPayback/bot.payers(state, player) <= float64(bot.Horizon) {
+		return monopoly.ActionDetails{Action: monopoly.BUYHOUSE, PropertyId: best}
+	}
+
+	for _, propertyId := range availableActions.BuyOutList {
+		cost := int(float64(state.Properties[propertyId].Price) * 1.1)
+		gain := bot.change(state, player, func(s monopoly.GameState) { s.Properties[propertyId].IsMortgaged = false })
+		if cost <= budget && gain+float64(state.Properties[propertyId].Price/2) >= float64(cost) {
+			return monopoly.ActionDetails{Action: monopoly.BUYOUT, PropertyId: propertyId}
+		}
+	}
+
+	if slices.Contains(availableActions.Actions, monopoly.BUYOFFER) {
+		if offer, ok := bot.buyOffer(player, state, availableActions.BuyPropertyList, budget); ok {
+			return offer
+		}
+	}
+	if slices.Contains(availableActions.Actions, monopoly.SELLOFFER) {
+		if offer, ok := bot.sellOffer(player, state, availableActions.SellPropertyList); ok {
+			return offer
+		}
+	}
+	return monopoly.ActionDetails{Action: monopoly.NOACTION}
+}
+
+// raiseMoney mortgages the property or sells the house which costs the least rent per dollar raised.
+func (bot *EVBot) raiseMoney(player int, state monopoly.GameState, availableActions monopoly.FullActionList) monopoly.ActionDetails {
+	var best monopoly.ActionDetails
+	bestLoss := math.Inf(1)
+	consider := func(action monopoly.StdAction, propertyId int, raised int, modify func(s monopoly.GameState)) {
+		loss := -bot.change(state, player, modify) / float64(max(raised, 1))
+		if loss < bestLoss {
+			best, bestLoss = monopoly.ActionDetails{Action: action, PropertyId: propertyId}, loss
+		}
+	}
+	for _, propertyId := range availableActions.MortgageList {
+		consider(monopoly.MORTGAGE, propertyId, state.Properties[propertyId].Price/2, func(s monopoly.GameState) {
+			s.Properties[propertyId].IsMortgaged = true
+		})
+	}
+	for _, propertyId := range availableActions.SellHouseList {
+		consider(monopoly.SELLHOUSE, propertyId, state.Properties[propertyId].HousePrice/2, func(s monopoly.GameState) {
+			s.Properties[propertyId].Houses--
+		})
+	}
+	if math.IsInf(bestLoss, 1) && len(availableActions.Actions) > 0 {
+		best.Action = availableActions.Actions[0]
+	}
+	return best
+}
+
+// buyOffer offers the owner what the property is worth to them, for the property which leaves the player the largest surplus.
+func (bot *EVBot) buyOffer(player int, state monopoly.GameState, properties []int, budget int) (monopoly.ActionDetails, bool) {
+	offer, surplus := monopoly.ActionDetails{Action: monopoly.BUYOFFER}, 0.0
+	for _, propertyId := range properties {
+		owner := state.Properties[propertyId].Owner.ID
+		price := int(math.Ceil(1.1 * bot.gain(state, owner, propertyId, player)))
+		price = max(price, state.Settings.MinPrice)
+		if price > budget {
+			continue
+		}
+		if gain := bot.value(state, player, propertyId) - float64(price); gain > surplus {
+			offer.PropertyId, offer.Price, surplus = propertyId, price, gain
+		}
+	}
+	return offer, surplus > 0
+}
+
+// sellOffer asks a premium over what the property is worth to the player from the opponents who value it even more.
+func (bot *EVBot) sellOffer(player int, state monopoly.GameState, properties []int) (monopoly.ActionDetails, bool) {
+	for _, propertyId := range properties {
+		offer := monopoly.ActionDetails{Action: monopoly.SELLOFFER, PropertyId: propertyId}
+		for _, buyer := range activeOpponents(state, player) {
+			keep := bot.gain(state, player, propertyId, buyer)
+			price := int(math.Ceil(1.2 * max(keep, 0)))
+			if price > state.Properties[propertyId].Price && price <= state.Players[buyer].Money && bot.gain(state, buyer, propertyId, player) >= float64(price) {
+				offer.Price = max(offer.Price, price)
+				offer.Players = append(offer.Players, buyer)
+			}
+		}
+		if len(offer.Players) > 0 {
+			return offer, true
+		}
+	}
+	return monopoly.ActionDetails{}, false
+}
+
+// GetJailAction leaves jail while there are properties to buy, later it stays in jail
+// while the rents on the board cost more than passing GO brings.
+func (bot *EVBot) GetJailAction(player int, state monopoly.GameState, available []monopoly.JailAction) monopoly.JailAction {
+	forSale := slices.ContainsFunc(state.Properties, func(p *monopoly.Property) bool { return p.Owner == nil })
+	owners := monopoly.Owners(state)
+	dangerous := monopoly.ExpectedCharges(state, player, owners) > float64(state.Settings.StartPassMoney)*monopoly.Landings().LapsPerTurn
+	if slices.Contains(available, monopoly.ROLL_DICE) && (dangerous && !forSale || bot.budget(state, player) < state.Settings.JailBail) {
+		return monopoly.ROLL_DICE
+	}
+	if slices.Contains(available, monopoly.CARD) {
+		return monopoly.CARD
+	}
+	return monopoly.BAIL
+}
+
+func (bot *EVBot) BuyDecision(player int, state monopoly.GameState, propertyId int) bool {
+	price := state.Properties[propertyId].Price
+	return price <= bot.budget(state, player) && bot.value(state, player, propertyId) >= float64(price)
+}
+
+func (bot *EVBot) BuyFromPlayerDecision(player int, state monopoly.GameState, propertyId int, price int) bool {
+	return price <= bot.budget(state, player) && bot.value(state, player, propertyId) >= float64(price)
+}
+
+func (bot *EVBot) SellToPlayerDecision(player int, state monopoly.GameState, propertyId int, price int) bool {
+	return float64(price) >= bot.gain(state, player, propertyId, state.CurrentPlayerIdx)
+}
+
+// BiddingDecision raises by the smallest step while the price stays below the value of the property and the budget.
+func (bot *EVBot) BiddingDecision(player int, state monopoly.GameState, propertyId int, currentPrice int, currentWinner int) int {
+	bid := currentPrice + BID_STEP
+	if bid > bot.budget(state, player) || float64(bid) > bot.value(state, player, propertyId) {
+		return 0
+	}
+	return bid
+}
+
+// activeOpponents returns the ids of the other players still in the game.
+func activeOpponents(state monopoly.GameState, player int) []int {
+	var opponents []int
+	for id, other := range state.Players {
+		if id != player && !other.IsBankrupt {
+			opponents = append(opponents, id)
+		}
+	}
+	return opponents
+}
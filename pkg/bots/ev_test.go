@@ -0,0 +1,81 @@
+package bots
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"testing"
+
+	"monopoly/pkg/config"
+	"monopoly/pkg/monopoly"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testState returns the state of a new game for the players with the properties owned as given.
+func testState(players int, owners map[int]int) monopoly.GameState {
+	state := monopoly.GameState{Settings: config.NewGameSettings()}
+	for id := range players {
+		state.Players = append(state.Players, monopoly.NewPlayer(id, fmt.Sprint(id), 1500))
+	}
+	for _, field := range monopoly.GetBoard().Fields {
+		if field.PropertyIndex >= 0 {
+			state.Properties = append(state.Properties, monopoly.NewProperty(field.FieldIndex, field.PropertyIndex, field.Name, field.Price, field.HousePrice, field.HousePrice > 0, field.Set))
+		}
+	}
+	slices.SortFunc(state.Properties, func(a, b *monopoly.Property) int { return a.PropertyIndex - b.PropertyIndex })
+	for propertyId, owner := range owners {
+		state.Properties[propertyId].Owner = state.Players[owner]
+		state.Players[owner].Properties = append(state.Players[owner].Properties, propertyId)
+	}
+	return state
+}
+
+func TestEVBotLegalMoves(t *testing.T) {
+	for seed := int64(1); seed <= 20; seed++ {
+		table, err := newBotTable(context.Background(), []string{"ev", "random:seed=3", "ev:horizon=5,risk=0.5"}, 0)
+		assert.NoError(t, err)
+		checker := &legalityChecker{botTable: table, t: t}
+		game := monopoly.NewGame(context.Background(), checker, punishmentLogger{t: t}, seed)
+		assert.NotPanics(t, game.Start)
+		assert.NotEqual(t, monopoly.CANCELLED, table.finish)
+	}
+}
+
+func TestEVBotValue(t *testing.T) {
+	bot := NewEVBot(20, 0.05)
+	state := testState(3, map[int]int{11: 0, 12: 0})
+	// completing the orange set is worth more than starting the pink one
+	assert.Greater(t, bot.value(state, 0, 13), bot.value(state, 0, 6))
+	assert.True(t, bot.BuyDecision(0, state, 13))
+	assert.Greater(t, bot.BiddingDecision(0, state, 13, 300, 1), 0, "The set is worth bidding over the list price")
+
+	// keeping an opponent from completing a set is worth something too
+	assert.Greater(t, bot.value(state, 1, 13), bot.value(state, 1, 6))
+	state.CurrentPlayerIdx = 1
+	assert.False(t, bot.SellToPlayerDecision(0, state, 11, 200), "Breaking up an own set should cost more than the list price")
+}
+
+func TestEVBotBudget(t *testing.T) {
+	bot := NewEVBot(20, 0.05)
+	state := testState(2, map[int]int{26: 1, 27: 1})
+	before := bot.budget(state, 0)
+	assert.Less(t, before, 1500)
+	state.Properties[27].Houses = 5
+	state.Properties[26].Houses = 5
+	after := bot.budget(state, 0)
+	assert.Less(t, after, before, "Hotels on the board call for more cash")
+	assert.False(t, bot.BuyDecision(0, state, 16), "The bot should not spend the reserve")
+	state.Players[0].Money = 10
+	assert.Equal(t, 0, bot.BiddingDecision(0, state, 6, 0, -1))
+}
+
+func TestEVBotRaisesMoneyCheaply(t *testing.T) {
+	bot := NewEVBot(20, 0.05)
+	state := testState(2, map[int]int{0: 0, 1: 0, 25: 0})
+	state.Charge = 100
+	list := monopoly.FullActionList{Actions: []monopoly.StdAction{monopoly.MORTGAGE}, MortgageList: []int{0, 1, 25}}
+	// a lone railroad brings less rent per dollar raised than a set ready for houses
+	details := bot.GetStdAction(0, state, list)
+	assert.Equal(t, monopoly.ActionDetails{Action: monopoly.MORTGAGE, PropertyId: 25}, details)
+}
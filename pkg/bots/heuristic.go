@@ -0,0 +1,299 @@
+package bots
+
+import (
+	"math/rand/v2"
+	"monopoly/pkg/monopoly"
+	"slices"
+	"time"
+)
+
+func init() {
+	Register("heuristic", func(opts Options) (Bot, error) {
+		if err := opts.Allow("seed"); err != nil {
+			return nil, err
+		}
+		seed, err := opts.Int("seed", 0)
+		if err != nil {
+			return nil, err
+		}
+		return NewHeuristicBot(int64(seed)), nil
+	})
+}
+
+// HeuristicBot is a hand-tuned strategy: it completes sets, builds houses while it keeps
+// a cash reserve and gets rid of lone properties when it needs money.
+type HeuristicBot struct {
+	rng *rand.Rand // picks among equally good properties
+}
+
+// NewHeuristicBot creates a bot with its own random generator, seed 0 means a time based seed.
+func NewHeuristicBot(seed int64) *HeuristicBot {
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	return &HeuristicBot{rng: rand.New(rand.NewPCG(uint64(seed), 2))}
+}
+
+// Seed replaces the random generator of the bot, see Seeder.
+func (bot *HeuristicBot) Seed(seed int64) {
+	bot.rng = rand.New(rand.NewPCG(uint64(seed), 2))
+}
+
+func (bot *HeuristicBot) GetStdAction(player int, state monopoly.GameState, availableActions monopoly.FullActionList) monopoly.ActionDetails {
+	retValue := monopoly.ActionDetails{}
+	if state.Charge > 0 {
+		return raiseMoney(state, player, availableActions)
+	}
+	if state.StdActionsUsed >= state.Settings.MaxStdActionsPerTurn {
+		retValue.Action = monopoly.NOACTION
+		return retValue
+	}
+	playerCash := state.Players[player].Money
+	need_money := playerCash < 300
+
+	// Buying houses
+	if len(availableActions.BuyHouseList) > 0 {
+		randIdx := bot.rng.IntN(len(availableActions.BuyHouseList))
+		propertyId := availableActions.BuyHouseList[randIdx]
+		property := state.Properties[propertyId]
+		if playerCash-property.HousePrice >= 200 {
+			retValue.Action = monopoly.BUYHOUSE
+			retValue.PropertyId = propertyId
+			return retValue
+		} else {
+			need_money = true
+		}
+	}
+
+	// Unmortgaging properties in full sets
+	fullSetProperties := findPropertiesInFullSets(state, player)
+	for _, propertyId := range fullSetProperties {
+		if slices.Contains(availableActions.BuyOutList, propertyId) {
+			propertyBuyOut := int(float64(state.Properties[propertyId].Price) * 1.1)
+			if playerCash-propertyBuyOut >= 200 {
+				retValue.Action = monopoly.BUYOUT
+				retValue.PropertyId = propertyId
+				return retValue
+			} else {
+				need_money = true
+			}
+		}
+	}
+
+	// Buying key properties
+	if state.BuyOfferTries < state.Settings.MaxOfferTries {
+		keyProperties := findKeyProperties(state, player)
+		for _, propertyId := range keyProperties {
+			if slices.Contains(availableActions.BuyPropertyList, propertyId) {
+				price := state.Properties[propertyId].Price / 2
+				if playerCash-price >= 200 {
+					retValue.Action = monopoly.BUYOFFER
+					retValue.PropertyId = propertyId
+					retValue.Price = price
+					return retValue
+				} else {
+					need_money = true
+				}
+			}
+		}
+	}
+
+	unwantedProperties := findUnwantedProperties(state, player)
+	if need_money && len(unwantedProperties) > 0 {
+		randIdx := bot.rng.IntN(len(unwantedProperties))
+		propertyId := unwantedProperties[randIdx]
+
+		// Selling properties
+		if state.SellOfferTries < state.Settings.MaxOfferTries && slices.Contains(availableActions.SellPropertyList, propertyId) {
+			retValue.Action = monopoly.SELLOFFER
+			retValue.PropertyId = propertyId
+			property := state.Properties[propertyId]
+			retValue.Price = int(float64(property.Price) * 1.5)
+			for id := range state.Players {
+				if id != player {
+					retValue.Players = append(retValue.Players, id)
+				}
+			}
+			return retValue
+		}
+
+		// Mortgaging properties
+		if slices.Contains(availableActions.MortgageList, propertyId) {
+			retValue.Action = monopoly.MORTGAGE
+			retValue.PropertyId = propertyId
+			return retValue
+		}
+	}
+
+	// Unmortgaging rest of properties
+	for _, propertyId := range availableActions.BuyOutList {
+		buyOut := int(float64(state.Properties[propertyId].Price) * 1.1)
+		if playerCash-buyOut >= 200 {
+			retValue.Action = monopoly.BUYOUT
+			retValue.PropertyId = propertyId
+			return retValue
+		}
+	}
+
+	// Trying to buy properties for free
+	if state.BuyOfferTries < state.Settings.MaxOfferTries && len(availableActions.BuyPropertyList) > 0 {
+		randIdx := bot.rng.IntN(len(availableActions.BuyPropertyList))
+		propertyId := availableActions.BuyPropertyList[randIdx]
+		retValue.Action = monopoly.BUYOFFER
+		retValue.PropertyId = propertyId
+		retValue.Price = 0
+		return retValue
+	}
+
+	retValue.Action = monopoly.NOACTION
+	return retValue
+}
+
+func (bot *HeuristicBot) GetJailAction(player int, state monopoly.GameState, available []monopoly.JailAction) monopoly.JailAction {
+	if !slices.Contains(available, monopoly.ROLL_DICE) {
+		if slices.Contains(available, monopoly.CARD) {
+			return monopoly.CARD
+		}
+		return monopoly.BAIL
+	}
+	if state.Round > 20 {
+		return monopoly.ROLL_DICE
+	}
+	if slices.Contains(available, monopoly.CARD) {
+		return monopoly.CARD
+	}
+	return monopoly.BAIL
+}
+func (bot *HeuristicBot) BuyDecision(player int, state monopoly.GameState, propertyId int) bool {
+	if state.Players[player].Money-state.Properties[propertyId].Price >= 200 {
+		return true
+	}
+	return false
+}
+func (bot *HeuristicBot) BuyFromPlayerDecision(player int, state monopoly.GameState, propertyId int, price int) bool {
+	if state.Players[player].Money-price < 200 {
+		return false
+	}
+	if price < state.Properties[propertyId].Price {
+		return true
+	}
+	isKeyProperty := slices.Contains(findKeyProperties(state, player), propertyId)
+	if isKeyProperty && price <= 2*state.Properties[propertyId].Price {
+		return true
+	}
+	return false
+}
+
+func (bot *HeuristicBot) SellToPlayerDecision(player int, state monopoly.GameState, propertyId int, price int) bool {
+	fullSetProperties := findPropertiesInFullSets(state, player)
+	if slices.Contains(fullSetProperties, propertyId) {
+		return false
+	}
+	unwantedProperties := findUnwantedProperties(state, player)
+	if slices.Contains(unwantedProperties, propertyId) && price > state.Properties[propertyId].Price {
+		return true
+	}
+	if price > 2*state.Properties[propertyId].Price {
+		return true
+	}
+	return false
+}
+
+func (bot *HeuristicBot) BiddingDecision(player int, state monopoly.GameState, propertyId int, currentPrice int, currentWinner int) int {
+	if state.Players[player].Money-currentPrice < 200 {
+		return 0
+	}
+	isKeyProperty := slices.Contains(findKeyProperties(state, player), propertyId)
+	if isKeyProperty {
+		return currentPrice + 20
+	}
+	if currentPrice < state.Properties[propertyId].Price {
+		return currentPrice + 10
+	}
+	return 0
+}
+
+// raiseMoney mortgages lone properties first, houses are sold last. During a charge NOACTION is not available.
+func raiseMoney(state monopoly.GameState, player int, availableActions monopoly.FullActionList) monopoly.ActionDetails {
+	for _, propertyId := range findUnwantedProperties(state, player) {
+		if slices.Contains(availableActions.MortgageList, propertyId) {
+			return monopoly.ActionDetails{Action: monopoly.MORTGAGE, PropertyId: propertyId}
+		}
+	}
+	if len(availableActions.MortgageList) > 0 {
+		return monopoly.ActionDetails{Action: monopoly.MORTGAGE, PropertyId: availableActions.MortgageList[0]}
+	}
+	return monopoly.ActionDetails{Action: monopoly.SELLHOUSE, PropertyId: availableActions.SellHouseList[0]}
+}
+
+func findKeyProperties(state monopoly.GameState, playerId int) []int {
+	_, missing := getSetMaps(state, playerId)
+	keyProperties := []int{}
+	for _, properties := range missing {
+		if len(properties) == 1 {
+			keyProperties = append(keyProperties, properties[0])
+		}
+	}
+	slices.Sort(keyProperties)
+	return keyProperties
+}
+
+func findUnwantedProperties(state monopoly.GameState, playerId int) []int {
+	have, _ := getSetMaps(state, playerId)
+	unwanted := []int{}
+	for set, properties := range have {
+		if len(properties) == 1 && set != "DarkBlue" && set != "Brown" {
+			unwanted = append(unwanted, properties[0])
+		}
+	}
+	slices.Sort(unwanted)
+	return unwanted
+}
+
+func findPropertiesInFullSets(state monopoly.GameState, playerId int) []int {
+	have, missing := getSetMaps(state, playerId)
+	fullSetProperties := []int{}
+	for set, properties := range missing {
+		if len(properties) == 0 {
+			fullSetProperties = append(fullSetProperties, have[set]...)
+		}
+	}
+	slices.Sort(fullSetProperties)
+	return fullSetProperties
+}
+
+// getSetMaps splits the properties of every color set into the ones the player has and the missing ones.
+// The maps iterate in random order, so the functions above sort what they collect from them.
+func getSetMaps(state monopoly.GameState, playerId int) (have map[string][]int, missing map[string][]int) {
+	have = map[string][]int{
+		"Brown":     {},
+		"LightBlue": {},
+		"Pink":      {},
+		"Orange":    {},
+		"Red":       {},
+		"Yellow":    {},
+		"Green":     {},
+		"DarkBlue":  {},
+	}
+	missing = map[string][]int{
+		"Brown":     {},
+		"LightBlue": {},
+		"Pink":      {},
+		"Orange":    {},
+		"Red":       {},
+		"Yellow":    {},
+		"Green":     {},
+		"DarkBlue":  {},
+	}
+	for idx, property := range state.Properties {
+		if property.Set == monopoly.RAILROAD || property.Set == monopoly.UTILITY {
+			continue
+		}
+		if property.Owner == nil || property.Owner.ID != playerId {
+			missing[property.Set] = append(missing[property.Set], idx)
+		} else {
+			have[property.Set] = append(have[property.Set], idx)
+		}
+	}
+	return
+}
@@ -0,0 +1,37 @@
+package bots
+
+import (
+	"testing"
+
+	"monopoly/pkg/config"
+	"monopoly/pkg/monopoly"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHeuristicBotRaisesMoney(t *testing.T) {
+	bot, err := New("heuristic")
+	if !assert.NoError(t, err) {
+		return
+	}
+	state := monopoly.GameState{
+		Players:    []*monopoly.Player{monopoly.NewPlayer(0, "0", 0), monopoly.NewPlayer(1, "1", 1500)},
+		Properties: []*monopoly.Property{monopoly.NewProperty(6, 0, "LightBlue1", 100, 50, true, "Light Blue")},
+		Settings:   config.NewGameSettings(),
+	}
+	state.Properties[0].Owner = state.Players[0]
+	state.Players[0].Properties = []int{0}
+	available := monopoly.FullActionList{
+		Actions:          []monopoly.StdAction{monopoly.SELLOFFER, monopoly.MORTGAGE},
+		SellPropertyList: []int{0},
+		MortgageList:     []int{0},
+	}
+
+	action := bot.GetStdAction(0, state, available)
+	assert.Equal(t, monopoly.SELLOFFER, action.Action, "A lone property should be offered to the other players")
+	assert.Equal(t, []int{1}, action.Players, "The bot should not offer the property to itself or to missing seats")
+
+	state.Charge = 100
+	action = bot.GetStdAction(0, state, available)
+	assert.Equal(t, monopoly.ActionDetails{Action: monopoly.MORTGAGE, PropertyId: 0}, action, "A charge should be paid by mortgaging")
+}
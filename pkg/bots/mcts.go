@@ -0,0 +1,326 @@
+package bots
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"monopoly/pkg/monopoly"
+	"slices"
+	"time"
+)
+
+const BID_STEP = 10 // smallest raise tried in auctions
+
+func init() {
+	Register("mcts", func(opts Options) (Bot, error) {
+		if err := opts.Allow("iterations", "time", "depth", "c", "rollout", "seed"); err != nil {
+			return nil, err
+		}
+		iterations, err := opts.Int("iterations", 200)
+		if err != nil {
+			return nil, err
+		}
+		budget, err := opts.Int("time", 200)
+		if err != nil {
+			return nil, err
+		}
+		depth, err := opts.Int("depth", 10)
+		if err != nil {
+			return nil, err
+		}
+		exploration, err := opts.Float("c", math.Sqrt2)
+		if err != nil {
+			return nil, err
+		}
+		seed, err := opts.Int("seed", 0)
+		if err != nil {
+			return nil, err
+		}
+		if iterations <= 0 || depth < 0 || budget < 0 {
+			return nil, fmt.Errorf("iterations must be positive, time and depth not negative")
+		}
+		rollout := opts.String("rollout", "heuristic")
+		if Name(rollout) == "mcts" {
+			return nil, fmt.Errorf("rollout policy cannot be mcts")
+		}
+		policy, err := New(rollout)
+		if err != nil {
+			return nil, err
+		}
+		return NewMCTSBot(iterations, time.Duration(budget)*time.Millisecond, depth, exploration, policy, int64(seed)), nil
+	})
+}
+
+// MCTSBot decides by playing the game further: for every decision it restores the game from the
+// state, tries the possible answers in determinized rollouts (the dice and cards of every rollout are
+// drawn anew) and picks the answer which won most often. A rollout stopped at the round limit counts
+// as a partial win, the share of the player in the net worth of the players still in the game. The answers are tried with UCB1, so that
+// promising answers get more rollouts. In the rollouts every player is played by the rollout policy.
+type MCTSBot struct {
+	Iterations  int           // rollouts per decision
+	Budget      time.Duration // time per decision, 0 for no limit
+	Depth       int           // rounds played in a rollout, the richest player wins at the limit; 0 plays until the game is over
+	Exploration float64       // UCB1 exploration constant
+	policy      Bot           // rollout policy, it also makes the decisions which are not searched
+	rng         *rand.Rand
+	rollout     *rolloutIO
+	ctx         context.Context // context of the game the bot plays in, the rollouts stop with it
+}
+
+// NewMCTSBot creates the bot, seed 0 means a time based seed.
+func NewMCTSBot(iterations int, budget time.Duration, depth int, exploration float64, policy Bot, seed int64) *MCTSBot {
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	return &MCTSBot{
+		Iterations:  iterations,
+		Budget:      budget,
+		Depth:       depth,
+		Exploration: exploration,
+		policy:      policy,
+		rng:         rand.New(rand.NewPCG(uint64(seed), 1)),
+		rollout:     &rolloutIO{policy: policy},
+		ctx:         context.Background(),
+	}
+}
+
+// SetContext makes the rollouts stop when the game the bot plays in is cancelled, see ContextSetter.
+func (bot *MCTSBot) SetContext(ctx context.Context) {
+	bot.ctx = ctx
+}
+
+// Seed replaces the random generator of the bot and seeds its rollout policy, see Seeder. The decisions
+// repeat only without a time budget, which stops the search after a varying number of rollouts.
+func (bot *MCTSBot) Seed(seed int64) {
+	bot.rng = rand.New(rand.NewPCG(uint64(seed), 1))
+	if seeder, ok := bot.policy.(Seeder); ok {
+		seeder.Seed(seed)
+	}
+}
+
+// candidate is one possible answer, apply plays it in a restored game.
+type candidate struct {
+	apply  func(g *monopoly.Game)
+	visits int
+	wins   float64
+}
+
+func (c *candidate) rate() float64 {
+	return c.wins / float64(c.visits)
+}
+
+// search returns the index of the candidate with the best win rate for the player. On a tie the first
+// candidate, which should be the answer of the policy, is kept.
+func (bot *MCTSBot) search(player int, state monopoly.GameState, candidates []*candidate) int {
+	if len(candidates) == 1 {
+		return 0
+	}
+	root := monopoly.NewGameFromState(bot.ctx, bot.rollout, nopLogger{}, state, bot.newSeed())
+	if bot.Depth > 0 {
+		root.LimitRounds(bot.Depth)
+	}
+	start := time.Now()
+	for i := range bot.Iterations {
+		if bot.ctx.Err() != nil {
+			break // the game is cancelled, it stops at its next step
+		}
+		if bot.Budget > 0 && i >= len(candidates) && time.Since(start) > bot.Budget {
+			break
+		}
+		c := bot.selectCandidate(candidates, i)
+		game := root.Clone()
+		game.Reseed(bot.newSeed())
+		c.apply(game)
+		game.Start()
+		c.visits++
+		score := bot.rollout.scores[player]
+		c.wins += score
+	}
+	best := 0
+	for idx, c := range candidates {
+		if c.visits > 0 && c.rate() > candidates[best].rate() {
+			best = idx
+		}
+	}
+	return best
+}
+
+func (bot *MCTSBot) selectCandidate(candidates []*candidate, played int) *candidate {
+	var best *candidate
+	bestValue := math.Inf(-1)
+	for _, c := range candidates {
+		if c.visits == 0 {
+			return c
+		}
+		value := c.wins/float64(c.visits) + bot.Exploration*math.Sqrt(math.Log(float64(played))/float64(c.visits))
+		if value > bestValue {
+			best, bestValue = c, value
+		}
+	}
+	return best
+}
+
+func (bot *MCTSBot) newSeed() int64 {
+	return bot.rng.Int64N(math.MaxInt64) + 1
+}
+
+func (bot *MCTSBot) GetStdAction(player int, state monopoly.GameState, availableActions monopoly.FullActionList) monopoly.ActionDetails {
+	// raising money for a charge happens in the middle of a payment, which cannot be restored
+	if state.Charge > 0 || player != state.CurrentPlayerIdx {
+		return bot.policy.GetStdAction(player, state, availableActions)
+	}
+	answers := []monopoly.ActionDetails{bot.policy.GetStdAction(player, state, availableActions)}
+	if answers[0].Action != monopoly.NOACTION {
+		answers = append(answers, monopoly.ActionDetails{Action: monopoly.NOACTION})
+	}
+	for _, action := range availableActions.Actions {
+		if action != monopoly.BUYHOUSE && action != monopoly.BUYOUT {
+			continue // offers need a price and selling without a charge rarely pays off, only the policy suggests them
+		}
+		for _, propertyId := range actionProperties(action, availableActions) {
+			answer := monopoly.ActionDetails{Action: action, PropertyId: propertyId}
+			if answer.Action != answers[0].Action || answer.PropertyId != answers[0].PropertyId {
+				answers = append(answers, answer)
+			}
+		}
+	}
+	candidates := make([]*candidate, len(answers))
+	for i, answer := range answers {
+		candidates[i] = &candidate{apply: func(g *monopoly.Game) { g.AnswerStdAction(answer) }}
+	}
+	return answers[bot.search(player, state, candidates)]
+}
+
+func (bot *MCTSBot) GetJailAction(player int, state monopoly.GameState, available []monopoly.JailAction) monopoly.JailAction {
+	actions := []monopoly.JailAction{bot.policy.GetJailAction(player, state, available)}
+	for _, action := range available {
+		if action != actions[0] {
+			actions = append(actions, action)
+		}
+	}
+	candidates := make([]*candidate, len(actions))
+	for i, action := range actions {
+		candidates[i] = &candidate{apply: func(g *monopoly.Game) { g.AnswerJailAction(action) }}
+	}
+	return actions[bot.search(player, state, candidates)]
+}
+
+func (bot *MCTSBot) BuyDecision(player int, state monopoly.GameState, propertyId int) bool {
+	suggested := bot.policy.BuyDecision(player, state, propertyId)
+	return bot.decideYesNo(player, state, suggested, func(g *monopoly.Game, yes bool) { g.AnswerBuyDecision(propertyId, yes) })
+}
+
+func (bot *MCTSBot) BuyFromPlayerDecision(player int, state monopoly.GameState, propertyId int, price int) bool {
+	if state.Players[player].Money < price {
+		return false
+	}
+	suggested := bot.policy.BuyFromPlayerDecision(player, state, propertyId, price)
+	return bot.decideYesNo(player, state, suggested, func(g *monopoly.Game, yes bool) { g.AnswerTradeOffer(player, propertyId, price, yes) })
+}
+
+func (bot *MCTSBot) SellToPlayerDecision(player int, state monopoly.GameState, propertyId int, price int) bool {
+	buyer := state.CurrentPlayerIdx
+	suggested := bot.policy.SellToPlayerDecision(player, state, propertyId, price)
+	return bot.decideYesNo(player, state, suggested, func(g *monopoly.Game, yes bool) { g.AnswerTradeOffer(buyer, propertyId, price, yes) })
+}
+
+func (bot *MCTSBot) decideYesNo(player int, state monopoly.GameState, suggested bool, answer func(g *monopoly.Game, yes bool)) bool {
+	candidates := []*candidate{
+		{apply: func(g *monopoly.Game) { answer(g, suggested) }},
+		{apply: func(g *monopoly.Game) { answer(g, !suggested) }},
+	}
+	return (bot.search(player, state, candidates) == 0) == suggested
+}
+
+// BiddingDecision tries the bid of the policy, passing, the smallest raise and raising to the list price of the property.
+func (bot *MCTSBot) BiddingDecision(player int, state monopoly.GameState, propertyId int, currentPrice int, currentWinner int) int {
+	cash := state.Players[player].Money
+	bids := []int{bot.policy.BiddingDecision(player, state, propertyId, currentPrice, currentWinner)}
+	if bids[0] <= currentPrice || bids[0] > cash {
+		bids[0] = 0
+	}
+	for _, bid := range []int{0, currentPrice + BID_STEP, state.Properties[propertyId].Price} {
+		if !slices.Contains(bids, bid) && (bid == 0 || bid > currentPrice && bid <= cash) {
+			bids = append(bids, bid)
+		}
+	}
+	candidates := make([]*candidate, len(bids))
+	for i, bid := range bids {
+		candidates[i] = &candidate{apply: func(g *monopoly.Game) {
+			g.AnswerBiddingDecision(player, propertyId, bid, currentPrice, currentWinner)
+		}}
+	}
+	return bids[bot.search(player, state, candidates)]
+}
+
+// rolloutIO plays every player of a rollout with the policy and remembers the score of every player.
+type rolloutIO struct {
+	policy Bot
+	scores []float64
+}
+
+func (r *rolloutIO) Init() []string {
+	return nil
+}
+
+func (r *rolloutIO) GetStdAction(player int, state monopoly.GameState, availableActions monopoly.FullActionList) monopoly.ActionDetails {
+	return r.policy.GetStdAction(player, state, availableActions)
+}
+
+func (r *rolloutIO) GetJailAction(player int, state monopoly.GameState, available []monopoly.JailAction) monopoly.JailAction {
+	return r.policy.GetJailAction(player, state, available)
+}
+
+func (r *rolloutIO) BuyDecision(player int, state monopoly.GameState, propertyId int) bool {
+	return r.policy.BuyDecision(player, state, propertyId)
+}
+
+func (r *rolloutIO) BuyFromPlayerDecision(player int, state monopoly.GameState, propertyId int, price int) bool {
+	return r.policy.BuyFromPlayerDecision(player, state, propertyId, price)
+}
+
+func (r *rolloutIO) SellToPlayerDecision(player int, state monopoly.GameState, propertyId int, price int) bool {
+	return r.policy.SellToPlayerDecision(player, state, propertyId, price)
+}
+
+func (r *rolloutIO) BiddingDecision(player int, state monopoly.GameState, propertyId int, currentPrice int, currentWinner int) int {
+	return r.policy.BiddingDecision(player, state, propertyId, currentPrice, currentWinner)
+}
+
+// Finish scores 1 for the winner of the game. At the round limit the players still in the game score
+// their share of the total net worth.
+func (r *rolloutIO) Finish(f monopoly.FinishOption, winner int, state monopoly.GameState) {
+	r.scores = make([]float64, len(state.Players))
+	switch f {
+	case monopoly.WIN:
+		r.scores[winner] = 1
+	case monopoly.ROUND_LIMIT:
+		total := 0
+		for _, player := range state.Players {
+			if !player.IsBankrupt {
+				total += netWorth(player, state)
+			}
+		}
+		for i, player := range state.Players {
+			if !player.IsBankrupt && total > 0 {
+				r.scores[i] = float64(netWorth(player, state)) / float64(total)
+			}
+		}
+	}
+}
+
+// netWorth counts the properties and houses at their price, unlike the liquidation value with which the
+// game picks the richest player. Within a few rounds the liquidation value would punish every purchase.
+func netWorth(player *monopoly.Player, state monopoly.GameState) int {
+	worth := player.Money
+	for _, id := range player.Properties {
+		property := state.Properties[id]
+		if property.IsMortgaged {
+			worth += property.Price / 2
+			continue
+		}
+		worth += property.Price + property.Houses*property.HousePrice
+	}
+	return worth
+}
@@ -0,0 +1,91 @@
+package bots
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"monopoly/pkg/monopoly"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMCTSBotLegalMoves(t *testing.T) {
+	for seed := int64(1); seed <= 3; seed++ {
+		table, err := newBotTable(context.Background(), []string{"mcts:iterations=4,depth=1,time=0,seed=1", "random:seed=2", "mcts:iterations=4,depth=1,time=0,rollout=random,seed=3"}, 0)
+		assert.NoError(t, err)
+		checker := &legalityChecker{botTable: table, t: t}
+		game := monopoly.NewGame(context.Background(), checker, punishmentLogger{t: t}, seed)
+		assert.NotPanics(t, game.Start)
+		assert.NotEqual(t, monopoly.CANCELLED, table.finish)
+	}
+}
+
+func TestMCTSBotOptions(t *testing.T) {
+	bot, err := New("mcts:iterations=50,time=20,depth=3,c=0.5")
+	assert.NoError(t, err)
+	mcts := bot.(*MCTSBot)
+	assert.Equal(t, 50, mcts.Iterations)
+	assert.Equal(t, 3, mcts.Depth)
+	assert.InDelta(t, 0.5, mcts.Exploration, 1e-9)
+
+	_, err = New("mcts:rollout=mcts")
+	assert.Error(t, err)
+	_, err = New("mcts:iterations=0")
+	assert.Error(t, err)
+}
+
+func TestMCTSBotAvoidsBankruptcy(t *testing.T) {
+	bot := NewMCTSBot(40, 0, 2, 1.4, NewHeuristicBot(1), 1)
+	table, err := newBotTable(context.Background(), []string{"heuristic", "heuristic"}, 0)
+	assert.NoError(t, err)
+	var state monopoly.GameState
+	grab := &stateGrabber{botTable: table, grab: func(s monopoly.GameState) { state = s }}
+	monopoly.NewGame(context.Background(), grab, nopLogger{}, 4).Start()
+	if !assert.NotNil(t, state.Players) {
+		return
+	}
+	// paying the whole cash of a player for a property is never better than refusing
+	seller := state.CurrentPlayerIdx
+	buyer := 1 - seller
+	propertyId := state.Players[seller].Properties[0]
+	assert.False(t, bot.BuyFromPlayerDecision(buyer, state, propertyId, state.Players[buyer].Money))
+}
+
+func TestMCTSBotStopsWithTheGame(t *testing.T) {
+	table, err := newBotTable(context.Background(), []string{"heuristic", "heuristic"}, 0)
+	assert.NoError(t, err)
+	var state monopoly.GameState
+	grab := &stateGrabber{botTable: table, grab: func(s monopoly.GameState) { state = s }}
+	monopoly.NewGame(context.Background(), grab, nopLogger{}, 4).Start()
+	if !assert.NotNil(t, state.Players) {
+		return
+	}
+	// in a cancelled game the bot plays no rollouts, so it takes the answer of the policy at once
+	policy := NewHeuristicBot(1)
+	bot := NewMCTSBot(100000, 0, 0, 1.4, policy, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	bot.SetContext(ctx)
+	player := state.CurrentPlayerIdx
+	propertyId := slices.IndexFunc(state.Properties, func(p *monopoly.Property) bool { return p.Owner == nil })
+	if !assert.NotEqual(t, -1, propertyId) {
+		return
+	}
+	assert.Equal(t, policy.BuyDecision(player, state, propertyId), bot.BuyDecision(player, state, propertyId))
+}
+
+// stateGrabber passes a copy of the first state from round 5 on where the player owns a property.
+type stateGrabber struct {
+	*botTable
+	grab    func(state monopoly.GameState)
+	grabbed bool
+}
+
+func (s *stateGrabber) GetStdAction(player int, state monopoly.GameState, availableActions monopoly.FullActionList) monopoly.ActionDetails {
+	if !s.grabbed && state.Round >= 5 && state.Charge == 0 && len(state.Players[player].Properties) > 0 {
+		s.grabbed = true
+		s.grab(state.Clone())
+	}
+	return s.botTable.GetStdAction(player, state, availableActions)
+}
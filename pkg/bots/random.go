@@ -0,0 +1,109 @@
+package bots
+
+import (
+	"math/rand/v2"
+	"monopoly/pkg/monopoly"
+	"time"
+)
+
+func init() {
+	Register("random", func(opts Options) (Bot, error) {
+		if err := opts.Allow("seed"); err != nil {
+			return nil, err
+		}
+		seed, err := opts.Int("seed", 0)
+		if err != nil {
+			return nil, err
+		}
+		return NewRandomBot(int64(seed)), nil
+	})
+}
+
+// RandomBot plays uniformly random legal moves. It is the baseline every other strategy should beat.
+type RandomBot struct {
+	rng *rand.Rand
+}
+
+// NewRandomBot creates a bot with its own random generator, seed 0 means a time based seed.
+func NewRandomBot(seed int64) *RandomBot {
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	return &RandomBot{rng: rand.New(rand.NewPCG(uint64(seed), 0))}
+}
+
+// Seed replaces the random generator of the bot, see Seeder.
+func (bot *RandomBot) Seed(seed int64) {
+	bot.rng = rand.New(rand.NewPCG(uint64(seed), 0))
+}
+
+// GetStdAction picks one entry of the list: NOACTION or an action together with one of its properties.
+func (bot *RandomBot) GetStdAction(player int, state monopoly.GameState, availableActions monopoly.FullActionList) monopoly.ActionDetails {
+	var choices []monopoly.ActionDetails
+	for _, action := range availableActions.Actions {
+		if action == monopoly.NOACTION {
+			choices = append(choices, monopoly.ActionDetails{Action: monopoly.NOACTION})
+			continue
+		}
+		for _, propertyId := range actionProperties(action, availableActions) {
+			choices = append(choices, monopoly.ActionDetails{Action: action, PropertyId: propertyId})
+		}
+	}
+	if len(choices) == 0 {
+		return monopoly.ActionDetails{Action: monopoly.NOACTION}
+	}
+	choice := choices[bot.rng.IntN(len(choices))]
+	cash := state.Players[player].Money
+	switch choice.Action {
+	case monopoly.BUYOFFER:
+		// the engine punishes offers the buyer cannot pay
+		choice.Price = bot.rng.IntN(max(cash, 0) + 1)
+	case monopoly.SELLOFFER:
+		choice.Price = bot.rng.IntN(2*state.Properties[choice.PropertyId].Price + 1)
+		for id, other := range state.Players {
+			if id != player && !other.IsBankrupt && bot.rng.IntN(2) == 0 {
+				choice.Players = append(choice.Players, id)
+			}
+		}
+	}
+	return choice
+}
+
+func actionProperties(action monopoly.StdAction, list monopoly.FullActionList) []int {
+	switch action {
+	case monopoly.MORTGAGE:
+		return list.MortgageList
+	case monopoly.BUYOUT:
+		return list.BuyOutList
+	case monopoly.SELLOFFER:
+		return list.SellPropertyList
+	case monopoly.BUYOFFER:
+		return list.BuyPropertyList
+	case monopoly.BUYHOUSE:
+		return list.BuyHouseList
+	case monopoly.SELLHOUSE:
+		return list.SellHouseList
+	}
+	return nil
+}
+
+func (bot *RandomBot) GetJailAction(player int, state monopoly.GameState, available []monopoly.JailAction) monopoly.JailAction {
+	return available[bot.rng.IntN(len(available))]
+}
+
+func (bot *RandomBot) BuyDecision(player int, state monopoly.GameState, propertyId int) bool {
+	return bot.rng.IntN(2) == 0
+}
+
+func (bot *RandomBot) BuyFromPlayerDecision(player int, state monopoly.GameState, propertyId int, price int) bool {
+	return state.Players[player].Money >= price && bot.rng.IntN(2) == 0
+}
+
+func (bot *RandomBot) SellToPlayerDecision(player int, state monopoly.GameState, propertyId int, price int) bool {
+	return bot.rng.IntN(2) == 0
+}
+
+// BiddingDecision bids a random amount up to the bot's cash, amounts not above the current price pass.
+func (bot *RandomBot) BiddingDecision(player int, state monopoly.GameState, propertyId int, currentPrice int, currentWinner int) int {
+	return bot.rng.IntN(max(state.Players[player].Money, 0) + 1)
+}
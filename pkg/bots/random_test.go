@@ -0,0 +1,75 @@
+package bots
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"monopoly/pkg/monopoly"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// legalityChecker passes the decisions of its bots to the game and reports the illegal ones.
+type legalityChecker struct {
+	*botTable
+	t *testing.T
+}
+
+func (c *legalityChecker) GetStdAction(player int, state monopoly.GameState, availableActions monopoly.FullActionList) monopoly.ActionDetails {
+	details := c.botTable.GetStdAction(player, state, availableActions)
+	assert.Contains(c.t, availableActions.Actions, details.Action)
+	if details.Action != monopoly.NOACTION {
+		assert.Contains(c.t, actionProperties(details.Action, availableActions), details.PropertyId)
+	}
+	assert.GreaterOrEqual(c.t, details.Price, 0)
+	if details.Action == monopoly.BUYOFFER {
+		assert.LessOrEqual(c.t, details.Price, state.Players[player].Money)
+	}
+	for _, id := range details.Players {
+		assert.True(c.t, id >= 0 && id < len(state.Players) && id != player, "Invalid offer recipient %d", id)
+	}
+	return details
+}
+
+func (c *legalityChecker) GetJailAction(player int, state monopoly.GameState, available []monopoly.JailAction) monopoly.JailAction {
+	action := c.botTable.GetJailAction(player, state, available)
+	assert.True(c.t, slices.Contains(available, action), "Invalid jail action %v", action)
+	return action
+}
+
+func (c *legalityChecker) BiddingDecision(player int, state monopoly.GameState, propertyId int, currentPrice int, currentWinner int) int {
+	bid := c.botTable.BiddingDecision(player, state, propertyId, currentPrice, currentWinner)
+	assert.LessOrEqual(c.t, bid, state.Players[player].Money)
+	return bid
+}
+
+type punishmentLogger struct {
+	nopLogger
+	t *testing.T
+}
+
+func (l punishmentLogger) Log(message string) {
+	assert.False(l.t, strings.Contains(message, "attempted an invalid"), message)
+}
+
+func TestRandomBotLegalMoves(t *testing.T) {
+	for seed := int64(1); seed <= 20; seed++ {
+		table, err := newBotTable(context.Background(), []string{"random:seed=1", "random:seed=2", "random:seed=3", "random:seed=4"}, 0)
+		assert.NoError(t, err)
+		checker := &legalityChecker{botTable: table, t: t}
+		game := monopoly.NewGame(context.Background(), checker, punishmentLogger{t: t}, seed)
+		assert.NotPanics(t, game.Start)
+		assert.NotEqual(t, monopoly.CANCELLED, table.finish)
+	}
+}
+
+func TestRandomBotSeed(t *testing.T) {
+	play := func() []int {
+		result, err := Simulate(context.Background(), []string{"random:seed=7", "random:seed=8"}, 1, 3)
+		assert.NoError(t, err)
+		return append(result.Wins, result.Leads...)
+	}
+	assert.Equal(t, play(), play(), "Seeded bots should play the same game")
+}
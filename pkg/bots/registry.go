@@ -0,0 +1,156 @@
+package bots
+
+import (
+	"context"
+	"fmt"
+	"monopoly/pkg/monopoly"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Bot makes every decision of a computer player. It matches server.PlayerIO, so any bot can take a seat at a table.
+type Bot interface {
+	GetStdAction(player int, state monopoly.GameState, availableActions monopoly.FullActionList) monopoly.ActionDetails
+	GetJailAction(player int, state monopoly.GameState, available []monopoly.JailAction) monopoly.JailAction
+	BuyDecision(player int, state monopoly.GameState, propertyId int) bool
+	BuyFromPlayerDecision(player int, state monopoly.GameState, propertyId int, price int) bool
+	SellToPlayerDecision(player int, state monopoly.GameState, propertyId int, price int) bool
+	BiddingDecision(player int, state monopoly.GameState, propertyId int, currentPrice int, currentWinner int) int
+}
+
+// Seeder is implemented by the bots which make random decisions. Seeding every bot of a seeded game
+// makes the whole game repeatable.
+type Seeder interface {
+	Seed(seed int64)
+}
+
+// ContextSetter is implemented by the bots which play games of their own to decide. They get the
+// context of the game they sit at, so that their games stop when it is cancelled.
+type ContextSetter interface {
+	SetContext(ctx context.Context)
+}
+
+// Options are the key=value pairs of a bot spec.
+type Options map[string]string
+
+// Factory creates a new bot. Every call returns a bot with its own state, so bots can play concurrent games.
+type Factory func(opts Options) (Bot, error)
+
+var (
+	registryMutex sync.RWMutex
+	registry      = map[string]Factory{}
+)
+
+// Register makes a bot strategy available under the name. It is meant to be called from init functions.
+func Register(name string, factory Factory) {
+	registryMutex.Lock()
+	defer registryMutex.Unlock()
+	if _, ok := registry[name]; ok {
+		panic("bot registered twice: " + name)
+	}
+	registry[name] = factory
+}
+
+// Names returns the registered strategies in alphabetical order.
+func Names() []string {
+	registryMutex.RLock()
+	defer registryMutex.RUnlock()
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// ParseSpec splits a bot spec of the form "name" or "name:key=value,key=value".
+func ParseSpec(spec string) (name string, opts Options, err error) {
+	name, params, _ := strings.Cut(strings.TrimSpace(spec), ":")
+	if name == "" {
+		return "", nil, fmt.Errorf("empty bot spec")
+	}
+	opts = Options{}
+	if params == "" {
+		return name, opts, nil
+	}
+	for _, param := range strings.Split(params, ",") {
+		key, value, ok := strings.Cut(param, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return "", nil, fmt.Errorf("bot spec %q: option %q is not key=value", spec, param)
+		}
+		if _, dup := opts[key]; dup {
+			return "", nil, fmt.Errorf("bot spec %q: option %q given twice", spec, key)
+		}
+		opts[key] = strings.TrimSpace(value)
+	}
+	return name, opts, nil
+}
+
+// New creates a bot from its spec, e.g. "heuristic" or "neat:path=genomes/trained".
+func New(spec string) (Bot, error) {
+	name, opts, err := ParseSpec(spec)
+	if err != nil {
+		return nil, err
+	}
+	registryMutex.RLock()
+	factory, ok := registry[name]
+	registryMutex.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown bot %q, available: %s", name, strings.Join(Names(), ", "))
+	}
+	bot, err := factory(opts)
+	if err != nil {
+		return nil, fmt.Errorf("bot %q: %w", spec, err)
+	}
+	return bot, nil
+}
+
+// Name returns the strategy name of a spec, e.g. "neat" for "neat:path=genomes/trained".
+func Name(spec string) string {
+	name, _, _ := strings.Cut(strings.TrimSpace(spec), ":")
+	return name
+}
+
+// Allow returns an error if the options contain a key which is not listed.
+func (o Options) Allow(keys ...string) error {
+	for key := range o {
+		if !slices.Contains(keys, key) {
+			return fmt.Errorf("unknown option %q", key)
+		}
+	}
+	return nil
+}
+
+func (o Options) String(key string, def string) string {
+	if value, ok := o[key]; ok {
+		return value
+	}
+	return def
+}
+
+func (o Options) Int(key string, def int) (int, error) {
+	value, ok := o[key]
+	if !ok {
+		return def, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("option %s: %q is not a number", key, value)
+	}
+	return n, nil
+}
+
+func (o Options) Float(key string, def float64) (float64, error) {
+	value, ok := o[key]
+	if !ok {
+		return def, nil
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, fmt.Errorf("option %s: %q is not a number", key, value)
+	}
+	return f, nil
+}
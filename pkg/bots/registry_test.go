@@ -0,0 +1,75 @@
+package bots
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSpec(t *testing.T) {
+	var tests = []struct {
+		spec         string
+		expectedName string
+		expectedOpts Options
+		expectError  bool
+	}{
+		{"heuristic", "heuristic", Options{}, false},
+		{"neat:path=genomes/trained", "neat", Options{"path": "genomes/trained"}, false},
+		{"mcts:iterations=500, depth=3", "mcts", Options{"iterations": "500", "depth": "3"}, false},
+		{"", "", nil, true},
+		{"mcts:iterations", "", nil, true},
+		{"mcts:iterations=1,iterations=2", "", nil, true},
+	}
+	for _, tt := range tests {
+		name, opts, err := ParseSpec(tt.spec)
+		if tt.expectError {
+			assert.Error(t, err, tt.spec)
+			continue
+		}
+		assert.NoError(t, err, tt.spec)
+		assert.Equal(t, tt.expectedName, name)
+		assert.Equal(t, tt.expectedOpts, opts)
+	}
+}
+
+func TestNew(t *testing.T) {
+	bot, err := New("heuristic")
+	assert.NoError(t, err)
+	assert.IsType(t, &HeuristicBot{}, bot)
+
+	_, err = New("unknown")
+	assert.ErrorContains(t, err, "unknown bot")
+
+	_, err = New("heuristic:depth=2")
+	assert.ErrorContains(t, err, "unknown option")
+}
+
+func TestOptions(t *testing.T) {
+	opts := Options{"iterations": "500", "c": "1.4", "bad": "x"}
+	n, err := opts.Int("iterations", 100)
+	assert.NoError(t, err)
+	assert.Equal(t, 500, n)
+	n, err = opts.Int("missing", 100)
+	assert.NoError(t, err)
+	assert.Equal(t, 100, n)
+	_, err = opts.Int("bad", 100)
+	assert.Error(t, err)
+	f, err := opts.Float("c", 2)
+	assert.NoError(t, err)
+	assert.InDelta(t, 1.4, f, 1e-9)
+}
+
+func TestSimulate(t *testing.T) {
+	result, err := Simulate(context.Background(), []string{"heuristic", "heuristic"}, 4, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, result.Games)
+	finished := result.Draws
+	for i := range result.Specs {
+		finished += result.Wins[i] + result.Leads[i]
+	}
+	assert.Equal(t, 4, finished, "Every game should have a result")
+
+	_, err = Simulate(context.Background(), []string{"heuristic"}, 1, 1)
+	assert.Error(t, err)
+}
@@ -0,0 +1,153 @@
+package bots
+
+import (
+	"context"
+	"fmt"
+	"monopoly/pkg/monopoly"
+	"runtime"
+	"sync"
+)
+
+// SimulationResult sums up games played between bots. Seats are indexed like the specs passed to Simulate.
+type SimulationResult struct {
+	Specs     []string
+	Games     int
+	Wins      []int // games won by bankrupting every other player
+	Leads     []int // games which reached the round limit with the seat as the richest player
+	Draws     int
+	Cancelled int
+	Rounds    int // rounds played in all finished games
+}
+
+// Simulate plays games between bots created from the specs, one seat per spec, on all CPUs.
+// Games use seeds seed, seed+1, ... unless seed is 0 (random games), the bots are seeded from the seed of their game.
+func Simulate(ctx context.Context, specs []string, games int, seed int64) (SimulationResult, error) {
+	result := SimulationResult{Specs: specs, Wins: make([]int, len(specs)), Leads: make([]int, len(specs))}
+	if len(specs) < 2 || len(specs) > 4 {
+		return result, fmt.Errorf("a game needs 2 to 4 bots, got %d", len(specs))
+	}
+	// fail early on invalid specs instead of in every game
+	for _, spec := range specs {
+		if _, err := New(spec); err != nil {
+			return result, err
+		}
+	}
+
+	var mutex sync.Mutex
+	var wg sync.WaitGroup
+	jobs := make(chan int64)
+	for range runtime.NumCPU() {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for gameSeed := range jobs {
+				table, err := newBotTable(ctx, specs, gameSeed)
+				if err != nil {
+					panic(err) // specs were checked above
+				}
+				monopoly.NewGame(ctx, table, nopLogger{}, gameSeed).Start()
+				mutex.Lock()
+				result.add(table)
+				mutex.Unlock()
+			}
+		}()
+	}
+	for i := range games {
+		if ctx.Err() != nil {
+			break
+		}
+		gameSeed := int64(0)
+		if seed != 0 {
+			gameSeed = seed + int64(i)
+		}
+		jobs <- gameSeed
+	}
+	close(jobs)
+	wg.Wait()
+	return result, ctx.Err()
+}
+
+func (r *SimulationResult) add(table *botTable) {
+	r.Games++
+	switch table.finish {
+	case monopoly.WIN:
+		r.Wins[table.winner]++
+	case monopoly.ROUND_LIMIT:
+		r.Leads[table.winner]++
+	case monopoly.DRAW:
+		r.Draws++
+	case monopoly.CANCELLED:
+		r.Cancelled++
+		return
+	}
+	r.Rounds += table.rounds
+}
+
+// botTable seats one bot per spec, it is the IMonopoly_IO of a simulated game.
+type botTable struct {
+	bots   []Bot
+	names  []string
+	finish monopoly.FinishOption
+	winner int
+	rounds int
+}
+
+func newBotTable(ctx context.Context, specs []string, seed int64) (*botTable, error) {
+	t := &botTable{}
+	for i, spec := range specs {
+		bot, err := New(spec)
+		if err != nil {
+			return nil, err
+		}
+		if seeder, ok := bot.(Seeder); ok && seed != 0 {
+			seeder.Seed(seed*int64(len(specs)) + int64(i))
+		}
+		if setter, ok := bot.(ContextSetter); ok {
+			setter.SetContext(ctx)
+		}
+		t.bots = append(t.bots, bot)
+		t.names = append(t.names, fmt.Sprintf("%s_%d", Name(spec), i))
+	}
+	return t, nil
+}
+
+func (t *botTable) Init() []string {
+	return t.names
+}
+
+func (t *botTable) GetStdAction(player int, state monopoly.GameState, availableActions monopoly.FullActionList) monopoly.ActionDetails {
+	return t.bots[player].GetStdAction(player, state, availableActions)
+}
+
+func (t *botTable) GetJailAction(player int, state monopoly.GameState, available []monopoly.JailAction) monopoly.JailAction {
+	return t.bots[player].GetJailAction(player, state, available)
+}
+
+func (t *botTable) BuyDecision(player int, state monopoly.GameState, propertyId int) bool {
+	return t.bots[player].BuyDecision(player, state, propertyId)
+}
+
+func (t *botTable) BuyFromPlayerDecision(player int, state monopoly.GameState, propertyId int, price int) bool {
+	return t.bots[player].BuyFromPlayerDecision(player, state, propertyId, price)
+}
+
+func (t *botTable) SellToPlayerDecision(player int, state monopoly.GameState, propertyId int, price int) bool {
+	return t.bots[player].SellToPlayerDecision(player, state, propertyId, price)
+}
+
+func (t *botTable) BiddingDecision(player int, state monopoly.GameState, propertyId int, currentPrice int, currentWinner int) int {
+	return t.bots[player].BiddingDecision(player, state, propertyId, currentPrice, currentWinner)
+}
+
+func (t *botTable) Finish(f monopoly.FinishOption, winner int, state monopoly.GameState) {
+	t.finish = f
+	t.winner = winner
+	t.rounds = state.Round
+}
+
+type nopLogger struct{}
+
+func (nopLogger) Log(message string)                                    {}
+func (nopLogger) LogWithState(message string, state monopoly.GameState) {}
+func (nopLogger) LogState(state monopoly.GameState)                     {}
+func (nopLogger) Error(message string, state monopoly.GameState)        {}
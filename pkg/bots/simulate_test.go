@@ -0,0 +1,17 @@
+package bots
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSimulateSeed(t *testing.T) {
+	play := func() SimulationResult {
+		result, err := Simulate(context.Background(), []string{"heuristic", "random", "heuristic"}, 8, 5)
+		assert.NoError(t, err)
+		return result
+	}
+	assert.Equal(t, play(), play(), "A seeded simulation should seed its bots")
+}
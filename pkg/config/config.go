@@ -17,40 +17,20 @@ const (
 	LAST_PROPERTY_ID = 27
 	MAX_MONEY        = 2000
 	MAX_JAIL_CARDS   = 10
+	MAX_JAIL_ROUNDS  = 3
+	MAX_MONOPOLIES   = 8
 	LAST_PLAYER_ID   = MAX_PLAYERS - 1
 )
 
-// Evaluator settings
-const (
-	// PUNISHMENT_FIRST_THRESHOLD  = 0 // if player bankrupts before this round he will receive HIGHEST_PUNISHMENT
-	// PUNISHMENT_SECOND_THRESHOLD = 0 // if player bankrupts before this round he will receive SECOND_HIGHEST_PUNISHMENT
-	// HIGHEST_PUNISHMENT          = 0
-	// SECOND_HIGHEST_PUNISHMENT   = 0
-	ROUND_SCORE         = 1 // points for each round survived for non-winner players
-	FIRST_PLACE_SCORE   = 2000
-	POINTS_PER_PROPERTY = 3  // points for each property owned by the player.
-	POINTS_PER_HOUSE    = 10 // points for each house owned by the player.
-	ALIVE_BONUS         = 50 // bonus points for not going bankrupt
-	// SECOND_PLACE_SCORE          = 0
-	// ROUND_LIMIT_WINNER_SCORE    = 0 // if player wins the game by reaching the round limit he will receive this score
-
-	INCLUDE_HEURISTIC_BOT = false // whether to include a heuristic bot in the games played during evaluation
-
-	GAMES_PER_EPOCH = 1000 // number of games every organism has to play during one epoch
-	GROUP_SIZE      = 4    // number of players in each game
-	MAX_THREADS     = 200  // maximum number of threads used to evaluate organisms
-	PRINT_EVERY     = 50   // saves logs and population to files every N epochs
-)
-
 type GameSettings struct {
-	MaxRounds            int
-	StartPassMoney       int
-	JailPosition         int
-	JailBail             int
-	MaxHouses            int
-	MinPrice             int
-	MaxOfferTries        int
-	MaxStdActionsPerTurn int
+	MaxRounds            int `yaml:"max_rounds"`
+	StartPassMoney       int `yaml:"start_pass_money"`
+	JailPosition         int `yaml:"jail_position"`
+	JailBail             int `yaml:"jail_bail"`
+	MaxHouses            int `yaml:"max_houses"`
+	MinPrice             int `yaml:"min_price"`
+	MaxOfferTries        int `yaml:"max_offer_tries"`
+	MaxStdActionsPerTurn int `yaml:"max_std_actions_per_turn"`
 }
 
 func NewGameSettings() GameSettings {
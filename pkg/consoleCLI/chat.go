@@ -0,0 +1,130 @@
+package consoleCLI
+
+import (
+	"fmt"
+	"monopoly/pkg/server"
+	"strings"
+)
+
+// openChat lets the player write to everyone or to a single player, or propose a trade.
+// It can be opened with Tab at any time, also in the middle of a decision.
+func (c *ConsoleCLI) openChat() {
+	c.chatting = true
+	defer func() { c.chatting = false }()
+
+	recipients := []int{server.CHAT_ALL}
+	items := []menuItem{{label: "Everyone"}}
+	for _, player := range c.ui.opponents() {
+		recipients = append(recipients, player.ID)
+		items = append(items, menuItem{label: player.Name})
+	}
+	idx, ok := c.choose("Chat: send to", nil, items, true)
+	if !ok {
+		return
+	}
+	msg := server.ChatMessage{To: recipients[idx]}
+
+	if msg.To != server.CHAT_ALL {
+		kinds := []menuItem{{label: "Message", key: 'm'}, {label: "Trade proposal", key: 't'}}
+		kind, ok := c.choose("Chat with "+items[idx].label, nil, kinds, true)
+		if !ok {
+			return
+		}
+		if kind == 1 {
+			if msg.Trade, ok = c.proposeTrade(msg.To); !ok {
+				return
+			}
+		}
+	}
+
+	title := "Message to " + items[idx].label
+	var info []string
+	if msg.Trade != nil {
+		title = "Comment on the proposal (optional)"
+		info = []string{c.ui.tradeText(c.ID, msg.To, *msg.Trade)}
+	}
+	if msg.Text, ok = c.enterText(title, info, msg.Trade != nil); !ok {
+		return
+	}
+	if err := c.sendChat(msg); err != nil {
+		c.ui.addLog("Cannot send the message: " + err.Error())
+	}
+}
+
+// proposeTrade asks which properties and how much cash the player wants to swap with the other player.
+func (c *ConsoleCLI) proposeTrade(other int) (*server.TradeProposal, bool) {
+	trade := &server.TradeProposal{}
+	own := c.ui.state.Players[c.ID].Properties
+	chosen, ok := c.chooseMany("Properties you give", c.propertyItems(own, nil))
+	if !ok {
+		return nil, false
+	}
+	for _, idx := range chosen {
+		trade.Give = append(trade.Give, own[idx])
+	}
+	theirs := c.ui.state.Players[other].Properties
+	chosen, ok = c.chooseMany("Properties you want", c.propertyItems(theirs, nil))
+	if !ok {
+		return nil, false
+	}
+	for _, idx := range chosen {
+		trade.Take = append(trade.Take, theirs[idx])
+	}
+	pay, ok := c.enterNumber("Cash you add", nil, 0)
+	if !ok {
+		return nil, false
+	}
+	ask, ok := c.enterNumber("Cash you ask for", nil, 0)
+	if !ok {
+		return nil, false
+	}
+	trade.Money = pay - ask
+	return trade, true
+}
+
+// tradeText describes a proposal, e.g. "Player_1 offers Player_2: Brown1 + 50$ for Orange2".
+func (t *tui) tradeText(from int, to int, trade server.TradeProposal) string {
+	side := func(properties []int, money int) string {
+		var parts []string
+		for _, propertyId := range properties {
+			parts = append(parts, t.propertyName(propertyId))
+		}
+		if money > 0 {
+			parts = append(parts, fmt.Sprintf("%d$", money))
+		}
+		if len(parts) == 0 {
+			return "nothing"
+		}
+		return strings.Join(parts, " + ")
+	}
+	verb := "offers"
+	if from == t.playerID {
+		verb = "offer"
+	}
+	return fmt.Sprintf("%s %s %s: %s for %s", t.chatName(from), verb, t.chatName(to), side(trade.Give, trade.Money), side(trade.Take, -trade.Money))
+}
+
+func (t *tui) chatName(id int) string {
+	if id == t.playerID {
+		return "you"
+	}
+	if id < 0 || id >= len(t.state.Players) {
+		return fmt.Sprintf("Player_%d", id)
+	}
+	return t.state.Players[id].Name
+}
+
+// chatLine formats a chat message for the event log.
+func (t *tui) chatLine(msg server.ChatMessage) string {
+	line := fmt.Sprintf("[chat] %s to %s", t.chatName(msg.From), t.chatName(msg.To))
+	if msg.To == server.CHAT_ALL {
+		line = fmt.Sprintf("[chat] %s to everyone", t.chatName(msg.From))
+	}
+	if msg.Trade != nil {
+		line = "[trade] " + t.tradeText(msg.From, msg.To, *msg.Trade)
+	}
+	if msg.Text != "" {
+		line += ": " + msg.Text
+	}
+	return line
+}
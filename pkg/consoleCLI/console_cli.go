@@ -1,334 +1,257 @@
 package consoleCLI
 
 import (
+	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log"
 	"monopoly/pkg/monopoly"
 	"monopoly/pkg/server"
 	"net"
+	"os"
 
 	"github.com/eiannone/keyboard"
 )
 
 type ConsoleCLI struct {
-	ID int
+	ID       int
+	ui       *tui
+	keys     <-chan keyboard.KeyEvent
+	chats    <-chan server.ChatMessage
+	sendChat func(msg server.ChatMessage) error // nil if the chat is not available
+	chatting bool
+	canUndo  bool // Ctrl+Z takes back the previous decision, raising monopoly.ErrUndo
 }
 
-func (c *ConsoleCLI) GetStdAction(player int, state monopoly.GameState, availableActions monopoly.FullActionList) monopoly.ActionDetails {
-	if err := keyboard.Open(); err != nil {
-		log.Fatal(err)
-	}
-	defer keyboard.Close()
+var stdActionLabels = map[monopoly.StdAction]string{
+	monopoly.NOACTION:  "End turn",
+	monopoly.MORTGAGE:  "Mortgage",
+	monopoly.BUYOUT:    "Buy out",
+	monopoly.SELLOFFER: "Offer to sell",
+	monopoly.BUYOFFER:  "Offer to buy",
+	monopoly.BUYHOUSE:  "Build house",
+	monopoly.SELLHOUSE: "Sell house",
+}
 
-	fmt.Println("Available actions:")
-	for idx, action := range availableActions.Actions {
-		fmt.Printf("%v. %s\n", idx, monopoly.StdActionNames[action])
-	}
+var jailActionLabels = map[monopoly.JailAction]string{
+	monopoly.ROLL_DICE: "Roll dice",
+	monopoly.BAIL:      "Pay bail",
+	monopoly.CARD:      "Use card",
+}
 
-	for {
-		char, key, err := keyboard.GetKey()
-		if err != nil {
-			log.Fatal(err)
-		}
-		if key == keyboard.KeyEsc {
-			panic("User quit the game")
-		}
-		if char == 's' || char == 'S' {
-			fmt.Println(state)
-			continue
-		}
-		if char < '0' || char >= '0'+rune(len(availableActions.Actions)) {
-			fmt.Println("Unknown action")
-			continue
-		}
-		action := availableActions.Actions[char-'0']
-		fmt.Printf("Selected action: %s\n", monopoly.StdActionNames[action])
-		var response monopoly.ActionDetails
-		response.Action = action
-		switch action {
-		case monopoly.MORTGAGE:
-			response.PropertyId = chooseProperty(availableActions.MortgageList, state)
-		case monopoly.BUYOUT:
-			response.PropertyId = chooseProperty(availableActions.BuyOutList, state)
-		case monopoly.SELLOFFER:
-			response.PropertyId = chooseProperty(availableActions.SellPropertyList, state)
-			response.Players = choosePlayers(state.Players, state.CurrentPlayerIdx, state)
-			response.Price = choosePrice()
-		case monopoly.BUYOFFER:
-			response.PropertyId = chooseProperty(availableActions.BuyPropertyList, state)
-			response.Price = choosePrice()
-		case monopoly.BUYHOUSE:
-			response.PropertyId = chooseProperty(availableActions.BuyHouseList, state)
-		case monopoly.SELLHOUSE:
-			response.PropertyId = chooseProperty(availableActions.SellHouseList, state)
-		}
-		return response
-	}
+func (c *ConsoleCLI) money() int {
+	return c.ui.state.Players[c.ID].Money
 }
 
-func chooseProperty(properties []int, state monopoly.GameState) int {
-	page := 0
-	max_page := (len(properties) - 1) / 8
-	for {
-		fmt.Println("Choose property (index):")
-		for idx, property := range properties[page*8 : min(page*8+8, len(properties))] {
-			fmt.Printf("%d. Property index: %d\n", idx+1, property)
-		}
-		if page > 0 {
-			fmt.Println("9. Previous page")
-		}
-		if page < max_page {
-			fmt.Println("0. Next page")
-		}
-		for {
-			char, key, err := keyboard.GetKey()
-			if err != nil {
-				log.Fatal(err)
-			}
-			if key == keyboard.KeyEsc {
-				panic("User quit the game")
-			}
-			if char == 's' || char == 'S' {
-				fmt.Println(state)
-				continue
-			}
-			if page > 0 && char == '9' {
-				page--
-				break
-			}
-			if page < max_page && char == '0' {
-				page++
-				break
-			}
-			chosen_number := int(char - '1')
-			if page*8+chosen_number < len(properties) {
-				return properties[page*8+chosen_number]
-			}
-			fmt.Println("Invalid character. Try again.")
-		}
+// propertyItems lists properties with their price and rent ladder, extra adds action specific details.
+func (c *ConsoleCLI) propertyItems(properties []int, extra func(propertyId int) string) []menuItem {
+	items := make([]menuItem, len(properties))
+	for idx, propertyId := range properties {
+		detail := fmt.Sprintf("%4d$  %s", c.ui.fieldOf[propertyId].Price, c.ui.rentSummary(propertyId))
+		if extra != nil {
+			detail = extra(propertyId) + "  " + detail
+		}
+		items[idx] = menuItem{label: c.ui.propertyName(propertyId), detail: detail}
 	}
+	return items
 }
 
-func choosePlayers(players []*monopoly.Player, currPlayerIdx int, state monopoly.GameState) []int {
-	for {
-		var availablePlayers []int
-		for idx, player := range players {
-			if !player.IsBankrupt && idx != currPlayerIdx {
-				availablePlayers = append(availablePlayers, idx)
-			}
-		}
-		fmt.Println("Select players:")
-		for idx, player_id := range availablePlayers {
-			fmt.Printf("%d. %s\n", idx+1, players[player_id].Name)
-		}
-		fmt.Println("Press Enter to confirm selection")
-		chosenPlayersMap := map[int]bool{}
-		for _, player_id := range availablePlayers {
-			chosenPlayersMap[player_id] = false
-		}
-		fmt.Println("Chosen players: []")
-		for {
-			char, key, err := keyboard.GetKey()
-			if err != nil {
-				log.Fatal(err)
-			}
-			if key == keyboard.KeyEsc {
-				panic("User quit the game")
-			}
-
-			if char == 's' || char == 'S' {
-				fmt.Println(state)
-				continue
-			}
-
-			chosen_number := int(char - '1')
-			if chosen_number >= 0 && chosen_number < len(availablePlayers) {
-				currDecision := chosenPlayersMap[availablePlayers[chosen_number]]
-				chosenPlayersMap[availablePlayers[chosen_number]] = !currDecision
-				if !currDecision {
-					fmt.Printf("Player %s added\n", players[availablePlayers[chosen_number]].Name)
-				} else {
-					fmt.Printf("Player %s removed\n", players[availablePlayers[chosen_number]].Name)
-				}
-			}
-			var chosenPlayers []int
-			for player_id, selected := range chosenPlayersMap {
-				if selected {
-					chosenPlayers = append(chosenPlayers, player_id)
-				}
-			}
-			fmt.Println("Chosen players: ", chosenPlayers)
-			if key == keyboard.KeyEnter {
-				return chosenPlayers
-			}
-		}
+func (c *ConsoleCLI) chooseProperty(title string, properties []int, extra func(propertyId int) string) (int, bool) {
+	idx, ok := c.choose(title, nil, c.propertyItems(properties, extra), true)
+	if !ok {
+		return 0, false
 	}
+	return properties[idx], true
 }
 
-func choosePrice() int {
-	for {
-		fmt.Println("Enter price:")
-		var price int
-		_, err := fmt.Scanf("%d", &price)
-		if err != nil || price < 0 {
-			fmt.Println("Invalid price. Try again.")
-			continue
+func (c *ConsoleCLI) choosePlayers(state monopoly.GameState) ([]int, bool) {
+	var available []int
+	var items []menuItem
+	for idx, player := range state.Players {
+		if !player.IsBankrupt && idx != c.ID {
+			available = append(available, idx)
+			items = append(items, menuItem{label: player.Name, detail: fmt.Sprintf("%d$", player.Money)})
 		}
-		return price
 	}
+	chosen, ok := c.chooseMany("Offer to which players?", items)
+	if !ok {
+		return nil, false
+	}
+	players := make([]int, len(chosen))
+	for idx, item := range chosen {
+		players[idx] = available[item]
+	}
+	return players, true
 }
 
-func (c *ConsoleCLI) GetJailAction(player int, state monopoly.GameState, available []monopoly.JailAction) monopoly.JailAction {
-	if err := keyboard.Open(); err != nil {
-		log.Fatal(err)
+func (c *ConsoleCLI) actionDetail(action monopoly.StdAction, available monopoly.FullActionList) string {
+	lists := map[monopoly.StdAction][]int{
+		monopoly.MORTGAGE:  available.MortgageList,
+		monopoly.BUYOUT:    available.BuyOutList,
+		monopoly.SELLOFFER: available.SellPropertyList,
+		monopoly.BUYOFFER:  available.BuyPropertyList,
+		monopoly.BUYHOUSE:  available.BuyHouseList,
+		monopoly.SELLHOUSE: available.SellHouseList,
+	}
+	list, ok := lists[action]
+	if !ok {
+		return ""
 	}
-	defer keyboard.Close()
+	return fmt.Sprintf("(%d properties)", len(list))
+}
 
-	fmt.Println("Available jail actions:")
-	for idx, action := range available {
-		fmt.Printf("%v. %s\n", idx, monopoly.JailActionNames[action])
+func (c *ConsoleCLI) GetStdAction(player int, state monopoly.GameState, availableActions monopoly.FullActionList) monopoly.ActionDetails {
+	c.ui.setState(state)
+	title := "Choose an action"
+	if state.Charge > 0 {
+		title = fmt.Sprintf("You have to pay %d$, raise money:", state.Charge)
+	}
+	items := make([]menuItem, len(availableActions.Actions))
+	for idx, action := range availableActions.Actions {
+		items[idx] = menuItem{label: stdActionLabels[action], detail: c.actionDetail(action, availableActions)}
 	}
+	info := []string{fmt.Sprintf("Your cash: %d$", c.money())}
 
 	for {
-		char, key, err := keyboard.GetKey()
-		if err != nil {
-			log.Fatal(err)
+		idx, _ := c.choose(title, info, items, false)
+		action := availableActions.Actions[idx]
+		response := monopoly.ActionDetails{Action: action}
+		ok := true
+		switch action {
+		case monopoly.MORTGAGE:
+			response.PropertyId, ok = c.chooseProperty("Mortgage which property?", availableActions.MortgageList, func(id int) string {
+				return fmt.Sprintf("+%d$", c.ui.fieldOf[id].Price/2)
+			})
+		case monopoly.BUYOUT:
+			response.PropertyId, ok = c.chooseProperty("Buy out which property?", availableActions.BuyOutList, func(id int) string {
+				return fmt.Sprintf("-%d$", int(float64(c.ui.fieldOf[id].Price)*1.1))
+			})
+		case monopoly.SELLOFFER:
+			response.PropertyId, ok = c.chooseProperty("Sell which property?", availableActions.SellPropertyList, nil)
+			if ok {
+				response.Players, ok = c.choosePlayers(state)
+			}
+			if ok {
+				response.Price, ok = c.enterNumber("Asking price for "+c.ui.propertyName(response.PropertyId), nil, c.ui.fieldOf[response.PropertyId].Price)
+			}
+		case monopoly.BUYOFFER:
+			response.PropertyId, ok = c.chooseProperty("Buy which property?", availableActions.BuyPropertyList, func(id int) string {
+				return fmt.Sprintf("owner %-10s", c.ui.ownerName(id))
+			})
+			if ok {
+				offerInfo := func(price int) []string { return c.ui.decisionInfo(response.PropertyId, price) }
+				response.Price, ok = c.enterNumber("Your offer for "+c.ui.propertyName(response.PropertyId), offerInfo, c.ui.fieldOf[response.PropertyId].Price)
+			}
+		case monopoly.BUYHOUSE:
+			response.PropertyId, ok = c.chooseProperty("Build a house on which property?", availableActions.BuyHouseList, func(id int) string {
+				return fmt.Sprintf("-%d$ (%d houses)", c.ui.fieldOf[id].HousePrice, state.Properties[id].Houses)
+			})
+		case monopoly.SELLHOUSE:
+			response.PropertyId, ok = c.chooseProperty("Sell a house from which property?", availableActions.SellHouseList, func(id int) string {
+				return fmt.Sprintf("+%d$ (%d houses)", c.ui.fieldOf[id].HousePrice/2, state.Properties[id].Houses)
+			})
 		}
-		if key == keyboard.KeyEsc {
-			panic("User quit the game")
+		if ok {
+			return response
 		}
+	}
+}
 
-		if char == 's' || char == 'S' {
-			fmt.Println(state)
-			continue
-		}
-		if char < '0' || char >= '0'+rune(len(available)) {
-			fmt.Println("Unknown action")
-			continue
+func (c *ConsoleCLI) GetJailAction(player int, state monopoly.GameState, available []monopoly.JailAction) monopoly.JailAction {
+	c.ui.setState(state)
+	items := make([]menuItem, len(available))
+	for idx, action := range available {
+		items[idx] = menuItem{label: jailActionLabels[action]}
+		switch action {
+		case monopoly.BAIL:
+			items[idx].detail = fmt.Sprintf("-%d$", state.Settings.JailBail)
+		case monopoly.CARD:
+			items[idx].detail = fmt.Sprintf("(%d cards)", state.Players[player].JailCards)
 		}
-		action := available[char-'0']
-		fmt.Printf("Selected action: %s\n", monopoly.JailActionNames[action])
-		return action
 	}
+	info := []string{
+		fmt.Sprintf("Rounds in jail: %d", state.Players[player].RoundsInJail),
+		fmt.Sprintf("Your cash: %d$", c.money()),
+	}
+	idx, _ := c.choose("You are in jail", info, items, false)
+	return available[idx]
 }
 
-func (c *ConsoleCLI) BuyDecision(player int, state monopoly.GameState, propertyId int) bool {
-	if err := keyboard.Open(); err != nil {
-		log.Fatal(err)
+func (c *ConsoleCLI) propertyInfo(propertyId int) []string {
+	field := c.ui.fieldOf[propertyId]
+	info := []string{fmt.Sprintf("%s (%s), price %d$", field.Name, field.Set, field.Price)}
+	if field.HousePrice > 0 {
+		info = append(info, fmt.Sprintf("House price %d$", field.HousePrice))
 	}
-	defer keyboard.Close()
+	return append(info, c.ui.rentLadder(propertyId))
+}
 
-	fmt.Printf("Player %d, do you want to buy property %d? (y/n) \n", player, propertyId)
-	for {
-		char, key, err := keyboard.GetKey()
-		if err != nil {
-			log.Fatal(err)
-		}
-		if key == keyboard.KeyEsc {
-			panic("User quit the game")
-		}
-		switch char {
-		case 's', 'S':
-			fmt.Println(state)
-		case 'y', 'Y':
-			return true
-		case 'n', 'N':
-			return false
-		default:
-			fmt.Println("Invalid input. Please enter 'y' or 'n'.")
-		}
-	}
+func (c *ConsoleCLI) BuyDecision(player int, state monopoly.GameState, propertyId int) bool {
+	c.ui.setState(state)
+	price := c.ui.fieldOf[propertyId].Price
+	return c.yesNo(fmt.Sprintf("Buy %s for %d$?", c.ui.propertyName(propertyId), price), c.ui.decisionInfo(propertyId, price))
 }
 
 func (c *ConsoleCLI) BuyFromPlayerDecision(player int, state monopoly.GameState, propertyId int, price int) bool {
-	if err := keyboard.Open(); err != nil {
-		log.Fatal(err)
-	}
-	defer keyboard.Close()
-
-	fmt.Printf("Player %s wants to sell you property %d for %d? (y/n) \n", state.Players[state.CurrentPlayerIdx].Name, propertyId, price)
-	for {
-		char, key, err := keyboard.GetKey()
-		if err != nil {
-			log.Fatal(err)
-		}
-		if key == keyboard.KeyEsc {
-			panic("User quit the game")
-		}
-		switch char {
-		case 's', 'S':
-			fmt.Println(state)
-		case 'y', 'Y':
-			return true
-		case 'n', 'N':
-			return false
-		default:
-			fmt.Println("Invalid input. Please enter 'y' or 'n'.")
-		}
-	}
+	c.ui.setState(state)
+	seller := state.Players[state.CurrentPlayerIdx].Name
+	info := c.ui.decisionInfo(propertyId, price)
+	return c.yesNo(fmt.Sprintf("%s offers you %s for %d$. Buy?", seller, c.ui.propertyName(propertyId), price), info)
 }
 
 func (c *ConsoleCLI) SellToPlayerDecision(player int, state monopoly.GameState, propertyId int, price int) bool {
-	if err := keyboard.Open(); err != nil {
-		log.Fatal(err)
-	}
-	defer keyboard.Close()
-
-	fmt.Printf("Player %s wants to buy property %d from you for %d. Do you want to sell it? (y/n)\n ", state.Players[state.CurrentPlayerIdx].Name, propertyId, price)
-	for {
-		char, key, err := keyboard.GetKey()
-		if err != nil {
-			log.Fatal(err)
-		}
-		if key == keyboard.KeyEsc {
-			panic("User quit the game")
-		}
-		switch char {
-		case 's', 'S':
-			fmt.Println(state)
-		case 'y', 'Y':
-			return true
-		case 'n', 'N':
-			return false
-		default:
-			fmt.Println("Invalid input. Please enter 'y' or 'n'.")
-		}
-	}
+	c.ui.setState(state)
+	buyer := state.Players[state.CurrentPlayerIdx].Name
+	info := append(c.propertyInfo(propertyId), fmt.Sprintf("Your cash: %d$", c.money()))
+	return c.yesNo(fmt.Sprintf("%s wants to buy %s for %d$. Sell?", buyer, c.ui.propertyName(propertyId), price), info)
 }
 
-func (c *ConsoleCLI) BiddingDecision(player int, state monopoly.GameState, propertyId int, currentPrice int) int {
-	if err := keyboard.Open(); err != nil {
-		log.Fatal(err)
+func (c *ConsoleCLI) BiddingDecision(player int, state monopoly.GameState, propertyId int, currentPrice int, currentWinner int) int {
+	c.ui.setState(state)
+	title := fmt.Sprintf("Auction for %s, current price %d$", c.ui.propertyName(propertyId), currentPrice)
+	switch {
+	case currentWinner == player:
+		title += ", you are the highest bidder"
+	case currentWinner >= 0 && currentWinner < len(state.Players):
+		title += fmt.Sprintf(", highest bidder %s", state.Players[currentWinner].Name)
 	}
-	defer keyboard.Close()
-
-	fmt.Printf("Auction bidding for property %d. Current price is %d. Do you want to bid? (y/n)\n", propertyId, currentPrice)
+	items := []menuItem{{label: "Bid", key: 'b'}, {label: "Pass", key: 'p'}}
 	for {
-		char, key, err := keyboard.GetKey()
-		if err != nil {
-			log.Fatal(err)
-		}
-		if key == keyboard.KeyEsc {
-			panic("User quit the game")
+		idx, _ := c.choose(title, c.ui.decisionInfo(propertyId, currentPrice+10), items, false)
+		if idx == 1 {
+			return 0
 		}
-		switch char {
-		case 's', 'S':
-			fmt.Println(state)
-		case 'y', 'Y':
-			bid := choosePrice()
+		bid, ok := c.enterNumber(title, func(bid int) []string { return c.ui.decisionInfo(propertyId, bid) }, currentPrice+10)
+		if ok {
 			return bid
-		case 'n', 'N':
-			return 0
-		default:
-			fmt.Println("Invalid input. Please enter 'y' or 'n'.")
 		}
 	}
 }
 
-func StartClient() {
-	c := &ConsoleCLI{}
-	conn, err := net.Dial("tcp", "localhost:12345")
+type ClientOptions struct {
+	Addr    string
+	TableID string
+	// HumanPlayers creates the table with that many human seats if it does not exist, 0 only joins
+	HumanPlayers int
+	// AllowUndo lets the players of a newly created table take back their last decision
+	AllowUndo bool
+	Password  string
+	Token     string
+	// TLSConfig connects over TLS, nil uses plaintext
+	TLSConfig *tls.Config
+}
+
+// StartClient joins a table on the server and plays until the game is over.
+func StartClient(opts ClientOptions) {
+	var conn net.Conn
+	var err error
+	if opts.TLSConfig != nil {
+		conn, err = tls.Dial("tcp", opts.Addr, opts.TLSConfig)
+	} else {
+		conn, err = net.Dial("tcp", opts.Addr)
+	}
 	if err != nil {
 		panic(err)
 	}
@@ -336,35 +259,166 @@ func StartClient() {
 
 	decoder := json.NewDecoder(conn)
 	encoder := json.NewEncoder(conn)
-	if err := decoder.Decode(&c.ID); err != nil {
+	join_req := server.JoinRequest{
+		TableID:      opts.TableID,
+		HumanPlayers: opts.HumanPlayers,
+		AllowUndo:    opts.AllowUndo,
+		Password:     opts.Password,
+		Token:        opts.Token,
+	}
+	if err := encoder.Encode(join_req); err != nil {
 		panic(err)
 	}
-	fmt.Printf("Connected to server with ID: %d\n", c.ID)
-	fmt.Println("Press 's' to show current game state at any time.")
+	var join server.JoinResponse
+	if err := decoder.Decode(&join); err != nil {
+		panic(err)
+	}
+	if join.Error != "" {
+		fmt.Println("Cannot join table:", join.Error)
+		return
+	}
+
+	keys, err := keyboard.GetKeys(10)
+	if err != nil {
+		log.Fatal(err)
+	}
+	chats := make(chan server.ChatMessage)
+	c := &ConsoleCLI{ID: join.PlayerId, ui: newTUI(os.Stdout), keys: keys, chats: chats}
+	c.sendChat = func(msg server.ChatMessage) error {
+		return encoder.Encode(server.ClientMessage{Type: server.ClientChat, Chat: &msg})
+	}
+	c.ui.chat = true
+	c.ui.playerID = join.PlayerId
+	c.ui.tableID = join.TableID
+	c.ui.open()
+	defer func() {
+		r := recover()
+		c.ui.close()
+		keyboard.Close()
+		if err, ok := r.(error); ok && errors.Is(err, errUserQuit) {
+			fmt.Println("You left the game.")
+			return
+		}
+		if r != nil {
+			panic(r)
+		}
+	}()
+
+	c.ui.addLog(fmt.Sprintf("Joined table %s as player %d.", join.TableID, c.ID))
+	for _, token := range join.Invites {
+		c.ui.addLog(fmt.Sprintf("Invite for another player: --table %s --token %s", join.TableID, token))
+	}
+	c.ui.status = "Waiting for the game to start..."
+	c.ui.draw()
+
+	requests := make(chan server.ActionRequest)
+	readErr := make(chan error, 1)
+	go func() {
+		for {
+			var req server.ActionRequest
+			if err := decoder.Decode(&req); err != nil {
+				readErr <- err
+				return
+			}
+			if req.Type == server.ChatUpdate && req.Chat != nil {
+				// chat is shown even while the player is making a decision
+				chats <- *req.Chat
+				continue
+			}
+			requests <- req
+		}
+	}()
+
 	for {
-		var req server.ActionRequest
-		if err := decoder.Decode(&req); err != nil {
-			fmt.Println("Failed to decode request")
+		select {
+		case ev := <-c.keys:
+			if ev.Key == keyboard.KeyCtrlC {
+				panic(errUserQuit)
+			}
+			if c.ui.scrollLog(ev) {
+				c.ui.draw()
+			}
+			if ev.Key == keyboard.KeyTab && c.ui.state.Players != nil {
+				c.openChat()
+				c.ui.draw()
+			}
+		case msg := <-chats:
+			c.ui.addLog(c.ui.chatLine(msg))
+			c.ui.draw()
+		case err := <-readErr:
+			if errors.Is(err, io.EOF) {
+				c.finish("Server closed the connection.")
+				return
+			}
 			panic(err)
+		case req := <-requests:
+			c.ui.setState(req.State)
+			switch req.Type {
+			case server.StateUpdate:
+				c.ui.addLog(req.Message)
+				c.ui.draw()
+				continue
+			case server.GameFinished:
+				switch req.FinishOption {
+				case monopoly.DRAW:
+					c.finish("Game ended in a draw!")
+				case monopoly.CANCELLED:
+					c.finish("Game was cancelled by the server.")
+				default:
+					c.finish(fmt.Sprintf("Game over. %s wins!", req.State.Players[req.Winner].Name))
+				}
+				return
+			}
+			msg := server.ClientMessage{Type: server.ClientUndo}
+			if resp, undo := c.answer(req); !undo {
+				raw, err := json.Marshal(resp)
+				if err != nil {
+					panic(err)
+				}
+				msg = server.ClientMessage{Type: server.ClientResponse, Response: raw}
+			}
+			encoder.Encode(msg)
+			c.ui.draw()
 		}
-		var resp interface{}
-		switch req.Type {
-		case server.GetStdAction:
-			resp = c.GetStdAction(req.PlayerId, req.State, req.StdActionList)
-		case server.GetJailAction:
-			resp = c.GetJailAction(req.PlayerId, req.State, req.JailActionList)
-		case server.BuyDecision:
-			resp = c.BuyDecision(req.PlayerId, req.State, req.PropertyId)
-		case server.BuyFromPlayerDecision:
-			resp = c.BuyFromPlayerDecision(req.PlayerId, req.State, req.PropertyId, req.Price)
-		case server.SellToPlayerDecision:
-			resp = c.SellToPlayerDecision(req.PlayerId, req.State, req.PropertyId, req.Price)
-		case server.BiddingDecision:
-			resp = c.BiddingDecision(req.PlayerId, req.State, req.PropertyId, req.Price)
+	}
+}
 
-		default:
-			panic(fmt.Sprintf("Unknown request type: %v", req.Type))
-		}
-		encoder.Encode(resp)
+// answer asks the player for the decision requested by the server. It returns undo
+// if the player took back their previous decision instead.
+func (c *ConsoleCLI) answer(req server.ActionRequest) (resp interface{}, undo bool) {
+	c.canUndo = req.CanUndo
+	defer func() {
+		c.canUndo = false
+		if r := recover(); r != nil {
+			if err, ok := r.(error); ok && errors.Is(err, monopoly.ErrUndo) {
+				undo = true
+				return
+			}
+			panic(r)
+		}
+	}()
+	switch req.Type {
+	case server.GetStdAction:
+		return c.GetStdAction(req.PlayerId, req.State, req.StdActionList), false
+	case server.GetJailAction:
+		return c.GetJailAction(req.PlayerId, req.State, req.JailActionList), false
+	case server.BuyDecision:
+		return c.BuyDecision(req.PlayerId, req.State, req.PropertyId), false
+	case server.BuyFromPlayerDecision:
+		return c.BuyFromPlayerDecision(req.PlayerId, req.State, req.PropertyId, req.Price), false
+	case server.SellToPlayerDecision:
+		return c.SellToPlayerDecision(req.PlayerId, req.State, req.PropertyId, req.Price), false
+	case server.BiddingDecision:
+		return c.BiddingDecision(req.PlayerId, req.State, req.PropertyId, req.Price, req.Winner), false
+	default:
+		panic(fmt.Sprintf("Unknown request type: %v", req.Type))
 	}
 }
+
+// finish shows the final message and waits for a key, so the final board stays visible.
+func (c *ConsoleCLI) finish(message string) {
+	c.ui.addLog(message)
+	c.ui.status = message + " Press any key to exit."
+	c.ui.draw()
+	c.nextKey()
+}
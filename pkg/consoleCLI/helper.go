@@ -0,0 +1,104 @@
+package consoleCLI
+
+import (
+	"fmt"
+	"monopoly/pkg/monopoly"
+	"strings"
+)
+
+// expectedIncome is the rent the player expects from a single opponent going once around the board.
+func (t *tui) expectedIncome(playerId int, owners []int) float64 {
+	return monopoly.ExpectedIncome(t.state, playerId, owners) / monopoly.Landings().LapsPerTurn
+}
+
+// opponents returns the players still in the game, other than the client.
+func (t *tui) opponents() []*monopoly.Player {
+	var opponents []*monopoly.Player
+	for _, player := range t.state.Players {
+		if player.ID != t.playerID && !player.IsBankrupt {
+			opponents = append(opponents, player)
+		}
+	}
+	return opponents
+}
+
+// setOwnership tells how many properties of the set the client and each opponent own, e.g. "Orange set: you 1/3, Bob 2/3".
+func (t *tui) setOwnership(set string, owners []int) string {
+	properties := t.board.Sets[set]
+	count := func(playerId int) int {
+		owned := 0
+		for _, propertyId := range properties {
+			if owners[propertyId] == playerId {
+				owned++
+			}
+		}
+		return owned
+	}
+	parts := []string{fmt.Sprintf("you %d/%d", count(t.playerID), len(properties))}
+	for _, opponent := range t.opponents() {
+		parts = append(parts, fmt.Sprintf("%s %d/%d", opponent.Name, count(opponent.ID), len(properties)))
+	}
+	return fmt.Sprintf("%s set: %s", set, strings.Join(parts, ", "))
+}
+
+// monopolyEffect tells if getting the property completes the client's set or keeps an opponent from completing theirs.
+// Railroads and utilities have no monopoly, their rent grows with every property owned.
+func (t *tui) monopolyEffect(propertyId int, owners []int) string {
+	set := t.fieldOf[propertyId].Set
+	if set == monopoly.RAILROAD || set == monopoly.UTILITY {
+		return ""
+	}
+	// the owner of all the other properties of the set, if there is a single one
+	owner := -1
+	for _, other := range t.board.Sets[set] {
+		if other == propertyId {
+			continue
+		}
+		if owners[other] < 0 || (owner >= 0 && owners[other] != owner) {
+			return ""
+		}
+		owner = owners[other]
+	}
+	switch {
+	case owner < 0:
+		return ""
+	case owner == t.playerID:
+		return fmt.Sprintf("Completes your %s set", set)
+	default:
+		return fmt.Sprintf("Blocks %s's %s monopoly", t.state.Players[owner].Name, set)
+	}
+}
+
+// decisionInfo describes what getting a property for the given price means for the client.
+func (t *tui) decisionInfo(propertyId int, price int) []string {
+	field := t.fieldOf[propertyId]
+	info := []string{fmt.Sprintf("%s (%s), price %d$", field.Name, field.Set, field.Price)}
+	if field.HousePrice > 0 {
+		info[0] += fmt.Sprintf(", house %d$", field.HousePrice)
+	}
+	if t.state.Properties[propertyId].IsMortgaged {
+		info[0] += ", mortgaged"
+	}
+	info = append(info, t.rentLadder(propertyId))
+
+	owners := monopoly.Owners(t.state)
+	info = append(info, t.setOwnership(field.Set, owners))
+
+	money := t.state.Players[t.playerID].Money
+	if money >= price {
+		info = append(info, fmt.Sprintf("Cash after paying %d$: %d$", price, money-price))
+	} else {
+		info = append(info, fmt.Sprintf("Cash %d$, %d$ short of %d$", money, price-money, price))
+	}
+
+	before := t.expectedIncome(t.playerID, owners)
+	effect := t.monopolyEffect(propertyId, owners)
+	owners[propertyId] = t.playerID
+	gain := t.expectedIncome(t.playerID, owners) - before
+	chance := monopoly.Landings().PerLap(field.FieldIndex)
+	info = append(info, fmt.Sprintf("Expected rent +%.1f$ per opponent lap, lands %.0f%% of laps", gain, 100*chance))
+	if effect != "" {
+		info = append(info, effect)
+	}
+	return info
+}
@@ -0,0 +1,197 @@
+package consoleCLI
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand"
+	"monopoly/pkg/monopoly"
+	"monopoly/pkg/server"
+	"os"
+	"time"
+
+	"github.com/eiannone/keyboard"
+)
+
+const HOTSEAT_EVENT_DELAY = 300 * time.Millisecond // time to read the events of bot turns
+
+// HotSeatIO plays a game with several humans sharing one terminal, next to bots.
+// Every human decision is asked with the ConsoleCLI prompts. When the decision belongs to
+// another human than the one at the keyboard, the pending decision stays hidden behind
+// a "pass the keyboard" screen until the right player confirms they are ready.
+// HotSeatIO is also the game logger, events are shown in the shared event log.
+type HotSeatIO struct {
+	// AllowUndo lets a player take back their last decision with Ctrl+Z, as long as nothing random
+	// happened and no other human decided since
+	AllowUndo bool
+
+	ui     *tui
+	game   *monopoly.Game
+	keys   <-chan keyboard.KeyEvent
+	humans map[int]*ConsoleCLI
+	bots   map[int]server.PlayerIO
+	names  []string
+	active int // the human at the keyboard, -1 if nobody took it yet
+}
+
+// NewHotSeatIO seats the humans and bots in random order.
+func NewHotSeatIO(humanPlayers int, bots []server.PlayerIO) *HotSeatIO {
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	totalPlayers := humanPlayers + len(bots)
+	perm := r.Perm(totalPlayers)
+	h := &HotSeatIO{
+		ui:     newTUI(os.Stdout),
+		humans: make(map[int]*ConsoleCLI),
+		bots:   make(map[int]server.PlayerIO),
+		names:  make([]string, totalPlayers),
+		active: -1,
+	}
+	for i, bot := range bots {
+		h.bots[perm[i]] = bot
+		h.names[perm[i]] = fmt.Sprintf("Bot_%d", perm[i])
+	}
+	for _, id := range perm[len(bots):] {
+		h.humans[id] = &ConsoleCLI{ID: id, ui: h.ui}
+		h.names[id] = fmt.Sprintf("Player_%d", id)
+	}
+	return h
+}
+
+// Play runs the game in the terminal until it is over, cancelled or the users quit with Ctrl+C.
+func (h *HotSeatIO) Play(ctx context.Context) {
+	keys, err := keyboard.GetKeys(10)
+	if err != nil {
+		log.Fatal(err)
+	}
+	h.keys = keys
+	for _, c := range h.humans {
+		c.keys = keys
+	}
+	h.ui.tableID = "hot seat"
+	h.ui.open()
+	defer func() {
+		r := recover()
+		h.ui.close()
+		keyboard.Close()
+		if err, ok := r.(error); ok && errors.Is(err, errUserQuit) {
+			fmt.Println("You left the game.")
+			return
+		}
+		if r != nil {
+			panic(r)
+		}
+	}()
+
+	h.ui.status = "Game is starting..."
+	h.game = monopoly.NewGame(ctx, h, h, 0)
+	if h.AllowUndo {
+		h.game.EnableUndo()
+	}
+	h.game.Start()
+}
+
+// seat hands the keyboard over to the human, hiding the decision until they are ready.
+func (h *HotSeatIO) seat(player int, state monopoly.GameState) *ConsoleCLI {
+	c := h.humans[player]
+	h.ui.setState(state)
+	if h.active == player {
+		c.canUndo = h.game.CanUndo(player)
+		return c
+	}
+	c.canUndo = false
+	name := h.names[player]
+	h.ui.playerID = -1
+	pass := &menu{
+		title: fmt.Sprintf("Pass the keyboard to %s", name),
+		info:  []string{"Other players, please look away.", fmt.Sprintf("%s, press Enter when you are ready.", name)},
+		hint:  "Enter to continue",
+	}
+	c.run(pass, false)
+	h.active = player
+	h.ui.playerID = player
+	return c
+}
+
+func (h *HotSeatIO) Init() []string {
+	return h.names
+}
+
+func (h *HotSeatIO) GetStdAction(player int, state monopoly.GameState, availableActions monopoly.FullActionList) monopoly.ActionDetails {
+	if bot, ok := h.bots[player]; ok {
+		return bot.GetStdAction(player, state, availableActions)
+	}
+	return h.seat(player, state).GetStdAction(player, state, availableActions)
+}
+
+func (h *HotSeatIO) GetJailAction(player int, state monopoly.GameState, available []monopoly.JailAction) monopoly.JailAction {
+	if bot, ok := h.bots[player]; ok {
+		return bot.GetJailAction(player, state, available)
+	}
+	return h.seat(player, state).GetJailAction(player, state, available)
+}
+
+func (h *HotSeatIO) BuyDecision(player int, state monopoly.GameState, propertyId int) bool {
+	if bot, ok := h.bots[player]; ok {
+		return bot.BuyDecision(player, state, propertyId)
+	}
+	return h.seat(player, state).BuyDecision(player, state, propertyId)
+}
+
+func (h *HotSeatIO) BuyFromPlayerDecision(player int, state monopoly.GameState, propertyId int, price int) bool {
+	if bot, ok := h.bots[player]; ok {
+		return bot.BuyFromPlayerDecision(player, state, propertyId, price)
+	}
+	return h.seat(player, state).BuyFromPlayerDecision(player, state, propertyId, price)
+}
+
+func (h *HotSeatIO) SellToPlayerDecision(player int, state monopoly.GameState, propertyId int, price int) bool {
+	if bot, ok := h.bots[player]; ok {
+		return bot.SellToPlayerDecision(player, state, propertyId, price)
+	}
+	return h.seat(player, state).SellToPlayerDecision(player, state, propertyId, price)
+}
+
+func (h *HotSeatIO) BiddingDecision(player int, state monopoly.GameState, propertyId int, currentPrice int, currentWinner int) int {
+	if bot, ok := h.bots[player]; ok {
+		return bot.BiddingDecision(player, state, propertyId, currentPrice, currentWinner)
+	}
+	return h.seat(player, state).BiddingDecision(player, state, propertyId, currentPrice, currentWinner)
+}
+
+func (h *HotSeatIO) Finish(f monopoly.FinishOption, winner int, state monopoly.GameState) {
+	h.ui.setState(state)
+	h.ui.playerID = -1
+	var message string
+	switch f {
+	case monopoly.DRAW:
+		message = "Game ended in a draw!"
+	case monopoly.CANCELLED:
+		message = "Game was cancelled."
+	default:
+		message = fmt.Sprintf("Game over. %s wins!", h.names[winner])
+	}
+	spectator := &ConsoleCLI{ID: -1, ui: h.ui, keys: h.keys}
+	spectator.finish(message)
+}
+
+func (h *HotSeatIO) Log(message string) {
+	h.ui.addLog(message)
+	h.ui.draw()
+	if _, human := h.humans[h.ui.state.CurrentPlayerIdx]; !human {
+		time.Sleep(HOTSEAT_EVENT_DELAY)
+	}
+}
+
+func (h *HotSeatIO) LogState(state monopoly.GameState) {
+	h.ui.setState(state)
+}
+
+func (h *HotSeatIO) LogWithState(message string, state monopoly.GameState) {
+	h.ui.setState(state)
+	h.Log(message)
+}
+
+func (h *HotSeatIO) Error(message string, state monopoly.GameState) {
+	h.LogWithState("ERROR: "+message, state)
+}
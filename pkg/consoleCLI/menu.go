@@ -0,0 +1,307 @@
+package consoleCLI
+
+import (
+	"errors"
+	"fmt"
+	"monopoly/pkg/monopoly"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/eiannone/keyboard"
+)
+
+var errUserQuit = errors.New("user quit the game")
+
+const MAX_TEXT_LENGTH = 200
+
+type menuItem struct {
+	label  string
+	detail string
+	key    rune // optional shortcut, items without one are selected with digits 1-9
+}
+
+// menu is the decision prompt shown below the event log. It either picks one item,
+// several items (checked != nil) or a number (input != nil).
+type menu struct {
+	title    string
+	info     []string                 // context for the decision, shown above the items
+	live     func(value int) []string // context depending on the entered number, replaces info
+	items    []menuItem
+	selected int
+	checked  []bool
+	input    []rune
+	text     bool // input takes any text instead of a number
+	fresh    bool // the first typed digit replaces the suggested number
+	hint     string
+}
+
+func (m *menu) move(delta int) {
+	if len(m.items) == 0 {
+		return
+	}
+	m.selected = (m.selected + delta + len(m.items)) % len(m.items)
+}
+
+func (m *menu) number() (int, bool) {
+	value, err := strconv.Atoi(string(m.input))
+	return value, err == nil
+}
+
+func (m *menu) draw(scr *screen, x int, y int, width int, height int) {
+	row := y
+	scr.field(x, row, m.title, width, STYLE_BOLD)
+	row++
+	info := m.info
+	if value, ok := m.number(); ok && m.live != nil {
+		info = m.live(value)
+	}
+	for _, line := range info {
+		for _, wrapped := range wrap(line, width-2) {
+			if row >= y+height-2 {
+				break
+			}
+			scr.field(x+2, row, wrapped, width-2, STYLE_NONE)
+			row++
+		}
+	}
+	if m.input != nil {
+		input := m.input
+		if len(input) > width-3 {
+			// keep the end of long messages in view
+			input = input[len(input)-(width-3):]
+		}
+		scr.text(x, row, "> "+string(input)+"_", STYLE_REVERSE)
+		row++
+	}
+
+	// keep the selected item visible when the list does not fit
+	visible := y + height - 1 - row
+	first := 0
+	if m.selected >= visible {
+		first = m.selected - visible + 1
+	}
+	for i := first; i < len(m.items) && i-first < visible; i++ {
+		item := m.items[i]
+		prefix := "   "
+		if item.key != 0 {
+			prefix = fmt.Sprintf("%c. ", item.key)
+		} else if i < 9 {
+			prefix = fmt.Sprintf("%d. ", i+1)
+		}
+		if m.checked != nil {
+			if m.checked[i] {
+				prefix += "[x] "
+			} else {
+				prefix += "[ ] "
+			}
+		}
+		style := STYLE_NONE
+		if i == m.selected && m.input == nil {
+			style = STYLE_REVERSE
+		}
+		line := fmt.Sprintf("%s%-14s %s", prefix, item.label, item.detail)
+		scr.field(x, row, line, width, style)
+		row++
+	}
+	scr.field(x, y+height-1, m.hint, width, STYLE_DIM)
+}
+
+// nextKey waits for a key, handling the keys which work everywhere: log scrolling, chat, undo and quitting.
+// Chat messages received in the meantime are added to the event log.
+func (c *ConsoleCLI) nextKey() keyboard.KeyEvent {
+	for {
+		var ev keyboard.KeyEvent
+		select {
+		case msg := <-c.chats:
+			c.ui.addLog(c.ui.chatLine(msg))
+			c.ui.draw()
+			continue
+		case key, ok := <-c.keys:
+			if !ok {
+				panic(errUserQuit)
+			}
+			ev = key
+		}
+		if ev.Err != nil {
+			panic(ev.Err)
+		}
+		if ev.Key == keyboard.KeyCtrlC {
+			panic(errUserQuit)
+		}
+		if c.ui.scrollLog(ev) {
+			c.ui.draw()
+			continue
+		}
+		if ev.Key == keyboard.KeyCtrlZ && c.canUndo && !c.chatting {
+			panic(monopoly.ErrUndo)
+		}
+		if ev.Key == keyboard.KeyTab && c.sendChat != nil && !c.chatting {
+			c.openChat()
+			c.ui.draw()
+			continue
+		}
+		return ev
+	}
+}
+
+// run shows the menu until the user confirms it. It returns false if the user went back with Esc,
+// which is possible only if the menu is cancelable.
+func (c *ConsoleCLI) run(m *menu, cancelable bool) bool {
+	// menus opened from the chat return to the decision they interrupted
+	previous, status := c.ui.menu, c.ui.status
+	c.ui.menu = m
+	if previous == nil {
+		c.ui.status = fmt.Sprintf("%s, your turn!", c.ui.state.Players[c.ID].Name)
+		if c.canUndo {
+			c.ui.status += " Ctrl+Z takes back your previous decision."
+		}
+	}
+	defer func() {
+		c.ui.menu = previous
+		c.ui.status = status
+		if previous == nil {
+			c.ui.status = "Waiting for other players..."
+		}
+	}()
+	for {
+		c.ui.draw()
+		ev := c.nextKey()
+		switch {
+		case ev.Key == keyboard.KeyEsc:
+			if cancelable {
+				return false
+			}
+		case ev.Key == keyboard.KeyEnter:
+			if m.input == nil || m.text {
+				return true
+			}
+			if _, ok := m.number(); ok {
+				return true
+			}
+		case m.text && ev.Key == keyboard.KeySpace:
+			m.input = append(m.input, ' ')
+		case m.text && ev.Rune != 0:
+			if len(m.input) < MAX_TEXT_LENGTH {
+				m.input = append(m.input, ev.Rune)
+			}
+		case ev.Key == keyboard.KeyArrowUp:
+			if m.input != nil {
+				m.adjust(10)
+			} else {
+				m.move(-1)
+			}
+		case ev.Key == keyboard.KeyArrowDown:
+			if m.input != nil {
+				m.adjust(-10)
+			} else {
+				m.move(1)
+			}
+		case ev.Key == keyboard.KeySpace && m.checked != nil:
+			m.checked[m.selected] = !m.checked[m.selected]
+		case ev.Key == keyboard.KeyBackspace || ev.Key == keyboard.KeyBackspace2:
+			if len(m.input) > 0 {
+				m.input = m.input[:len(m.input)-1]
+			}
+			m.fresh = false
+		case m.input != nil && ev.Rune >= '0' && ev.Rune <= '9':
+			if m.fresh {
+				m.input = m.input[:0]
+				m.fresh = false
+			}
+			if len(m.input) < 6 {
+				m.input = append(m.input, ev.Rune)
+			}
+		default:
+			if idx, ok := m.shortcut(ev.Rune); ok {
+				m.selected = idx
+				if m.checked != nil {
+					m.checked[idx] = !m.checked[idx]
+					continue
+				}
+				return true
+			}
+		}
+	}
+}
+
+func (m *menu) adjust(delta int) {
+	value, _ := m.number()
+	m.input = []rune(strconv.Itoa(max(0, value+delta)))
+	m.fresh = false
+}
+
+func (m *menu) shortcut(r rune) (int, bool) {
+	for idx, item := range m.items {
+		if item.key != 0 && item.key == unicode.ToLower(r) {
+			return idx, true
+		}
+	}
+	idx := int(r - '1')
+	if r >= '1' && r <= '9' && idx < len(m.items) && m.items[idx].key == 0 {
+		return idx, true
+	}
+	return 0, false
+}
+
+func (c *ConsoleCLI) choose(title string, info []string, items []menuItem, cancelable bool) (int, bool) {
+	m := &menu{title: title, info: info, items: items, hint: "Up/Down + Enter or 1-9 to choose"}
+	if cancelable {
+		m.hint += ", Esc to go back"
+	}
+	if !c.run(m, cancelable) {
+		return 0, false
+	}
+	return m.selected, true
+}
+
+func (c *ConsoleCLI) chooseMany(title string, items []menuItem) ([]int, bool) {
+	m := &menu{title: title, items: items, checked: make([]bool, len(items)), hint: "Space or 1-9 to toggle, Enter to confirm, Esc to go back"}
+	if !c.run(m, true) {
+		return nil, false
+	}
+	var chosen []int
+	for idx, checked := range m.checked {
+		if checked {
+			chosen = append(chosen, idx)
+		}
+	}
+	return chosen, true
+}
+
+// enterNumber asks for a number, info describes the number being entered.
+func (c *ConsoleCLI) enterNumber(title string, info func(value int) []string, suggested int) (int, bool) {
+	m := &menu{
+		title: title,
+		live:  info,
+		input: []rune(strconv.Itoa(suggested)),
+		fresh: true,
+		hint:  "type digits, Up/Down +/-10, Enter to confirm, Esc to go back",
+	}
+	if !c.run(m, true) {
+		return 0, false
+	}
+	value, _ := m.number()
+	return value, true
+}
+
+// enterText asks for a line of text, an empty line is accepted only if allowEmpty is set.
+func (c *ConsoleCLI) enterText(title string, info []string, allowEmpty bool) (string, bool) {
+	m := &menu{title: title, info: info, input: []rune{}, text: true, hint: "type the message, Enter to send, Esc to go back"}
+	for {
+		if !c.run(m, true) {
+			return "", false
+		}
+		text := strings.TrimSpace(string(m.input))
+		if text != "" || allowEmpty {
+			return text, true
+		}
+	}
+}
+
+func (c *ConsoleCLI) yesNo(title string, info []string) bool {
+	items := []menuItem{{label: "Yes", key: 'y'}, {label: "No", key: 'n'}}
+	m := &menu{title: title, info: info, items: items, hint: "y/n or Up/Down + Enter"}
+	c.run(m, false)
+	return m.selected == 0
+}
@@ -0,0 +1,158 @@
+package consoleCLI
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// SGR parameters used by the terminal UI
+const (
+	STYLE_NONE    = ""
+	STYLE_BOLD    = "1"
+	STYLE_DIM     = "2"
+	STYLE_REVERSE = "7"
+	STYLE_TITLE   = "1;4"
+)
+
+var playerStyles = []string{"1;31", "1;34", "1;33", "1;35"}
+
+var setStyles = map[string]string{
+	"Brown":      "30;48;5;130",
+	"Light Blue": "30;48;5;117",
+	"Pink":       "30;48;5;205",
+	"Orange":     "30;48;5;208",
+	"Red":        "30;41",
+	"Yellow":     "30;43",
+	"Green":      "30;42",
+	"Dark Blue":  "37;44",
+}
+
+func playerStyle(id int) string {
+	return playerStyles[id%len(playerStyles)]
+}
+
+type cell struct {
+	ch    rune
+	style string
+}
+
+// screen is an off-screen character buffer, flushed to the terminal as a whole frame.
+type screen struct {
+	width  int
+	height int
+	cells  [][]cell
+}
+
+func newScreen(width int, height int) *screen {
+	s := &screen{width: width, height: height}
+	s.cells = make([][]cell, height)
+	for y := range s.cells {
+		s.cells[y] = make([]cell, width)
+	}
+	s.clear()
+	return s
+}
+
+func (s *screen) clear() {
+	for y := range s.cells {
+		for x := range s.cells[y] {
+			s.cells[y][x] = cell{ch: ' '}
+		}
+	}
+}
+
+func (s *screen) set(x int, y int, ch rune, style string) {
+	if x < 0 || y < 0 || x >= s.width || y >= s.height {
+		return
+	}
+	s.cells[y][x] = cell{ch: ch, style: style}
+}
+
+// text writes str at (x, y) and returns the column after it.
+func (s *screen) text(x int, y int, str string, style string) int {
+	for _, ch := range str {
+		s.set(x, y, ch, style)
+		x++
+	}
+	return x
+}
+
+// field writes str cut or padded to exactly width columns.
+func (s *screen) field(x int, y int, str string, width int, style string) {
+	runes := []rune(str)
+	for i := range width {
+		ch := ' '
+		if i < len(runes) {
+			ch = runes[i]
+		}
+		s.set(x+i, y, ch, style)
+	}
+}
+
+func (s *screen) fill(x int, y int, width int, height int) {
+	for row := y; row < y+height; row++ {
+		for col := x; col < x+width; col++ {
+			s.set(col, row, ' ', STYLE_NONE)
+		}
+	}
+}
+
+func (s *screen) box(x int, y int, width int, height int) {
+	for col := x; col < x+width; col++ {
+		s.set(col, y, '-', STYLE_NONE)
+		s.set(col, y+height-1, '-', STYLE_NONE)
+	}
+	for row := y; row < y+height; row++ {
+		s.set(x, row, '|', STYLE_NONE)
+		s.set(x+width-1, row, '|', STYLE_NONE)
+	}
+	s.set(x, y, '+', STYLE_NONE)
+	s.set(x+width-1, y, '+', STYLE_NONE)
+	s.set(x, y+height-1, '+', STYLE_NONE)
+	s.set(x+width-1, y+height-1, '+', STYLE_NONE)
+}
+
+// flush redraws the whole terminal. Every line is positioned explicitly,
+// because the keyboard library keeps the terminal in raw mode.
+func (s *screen) flush(out io.Writer) error {
+	w := bufio.NewWriter(out)
+	for y, row := range s.cells {
+		w.WriteString("\x1b[" + strconv.Itoa(y+1) + ";1H\x1b[0m")
+		style := STYLE_NONE
+		for _, c := range row {
+			if c.style != style {
+				w.WriteString("\x1b[0m")
+				if c.style != STYLE_NONE {
+					w.WriteString("\x1b[" + c.style + "m")
+				}
+				style = c.style
+			}
+			w.WriteRune(c.ch)
+		}
+		w.WriteString("\x1b[0m\x1b[K")
+	}
+	return w.Flush()
+}
+
+// wrap splits text into lines of at most width runes, breaking at spaces where possible.
+func wrap(text string, width int) []string {
+	var lines []string
+	for _, paragraph := range strings.Split(text, "\n") {
+		runes := []rune(paragraph)
+		for len(runes) > width {
+			cut := width
+			for i := width; i > width/2; i-- {
+				if runes[i] == ' ' {
+					cut = i
+					break
+				}
+			}
+			lines = append(lines, string(runes[:cut]))
+			runes = []rune(strings.TrimLeft(string(runes[cut:]), " "))
+		}
+		lines = append(lines, string(runes))
+	}
+	return lines
+}
@@ -0,0 +1,331 @@
+package consoleCLI
+
+import (
+	"fmt"
+	"io"
+	"monopoly/pkg/monopoly"
+	"strings"
+	"unicode"
+
+	"github.com/eiannone/keyboard"
+)
+
+// Layout of the terminal UI. The board is a ring of 11x11 cells, the event log and
+// the decision menu live inside the ring, the side panel is on the right.
+const (
+	CELL_WIDTH    = 7
+	CELL_HEIGHT   = 3
+	BOARD_WIDTH   = 11*CELL_WIDTH + 1
+	BOARD_HEIGHT  = 11*CELL_HEIGHT + 1
+	PANEL_X       = BOARD_WIDTH + 2
+	PANEL_WIDTH   = 44
+	SCREEN_WIDTH  = PANEL_X + PANEL_WIDTH
+	SCREEN_HEIGHT = BOARD_HEIGHT + 2
+
+	CENTER_X      = CELL_WIDTH + 2
+	CENTER_Y      = CELL_HEIGHT + 1
+	CENTER_WIDTH  = 9*CELL_WIDTH - 3
+	CENTER_HEIGHT = 9*CELL_HEIGHT - 1
+	LOG_HEIGHT    = 11
+	MENU_Y        = CENTER_Y + LOG_HEIGHT + 2
+	MENU_HEIGHT   = CENTER_Y + CENTER_HEIGHT - MENU_Y
+
+	MAX_LOG_LINES = 500
+)
+
+var setOrder = []string{"Brown", "Light Blue", "Pink", "Orange", "Red", "Yellow", "Green", "Dark Blue", monopoly.RAILROAD, monopoly.UTILITY}
+
+type tui struct {
+	board     monopoly.Board
+	fieldOf   map[int]monopoly.FieldInfo // property index -> field
+	playerID  int
+	tableID   string
+	state     monopoly.GameState
+	log       []string
+	logScroll int // number of lines scrolled back from the newest one
+	menu      *menu
+	status    string
+	chat      bool // the chat is available, see ConsoleCLI.openChat
+	scr       *screen
+	out       io.Writer
+}
+
+func newTUI(out io.Writer) *tui {
+	t := &tui{
+		board:    monopoly.GetBoard(),
+		fieldOf:  make(map[int]monopoly.FieldInfo),
+		playerID: -1,
+		scr:      newScreen(SCREEN_WIDTH, SCREEN_HEIGHT),
+		out:      out,
+	}
+	for _, field := range t.board.Fields {
+		if field.PropertyIndex >= 0 {
+			t.fieldOf[field.PropertyIndex] = field
+		}
+	}
+	return t
+}
+
+// open switches to the alternate screen buffer, so the shell is restored after the game.
+func (t *tui) open() {
+	fmt.Fprint(t.out, "\x1b[?1049h\x1b[?25l\x1b[2J")
+}
+
+func (t *tui) close() {
+	fmt.Fprint(t.out, "\x1b[0m\x1b[?25h\x1b[?1049l")
+}
+
+func (t *tui) setState(state monopoly.GameState) {
+	if state.Players != nil {
+		t.state = state
+	}
+}
+
+func (t *tui) addLog(message string) {
+	lines := wrap(message, CENTER_WIDTH)
+	t.log = append(t.log, lines...)
+	if t.logScroll > 0 {
+		// keep the same lines in view while the user reads older events
+		t.logScroll += len(lines)
+	}
+	if len(t.log) > MAX_LOG_LINES {
+		t.log = t.log[len(t.log)-MAX_LOG_LINES:]
+	}
+	t.logScroll = min(t.logScroll, max(0, len(t.log)-LOG_HEIGHT))
+}
+
+// scrollLog handles the log scrolling keys, returns false for any other key.
+func (t *tui) scrollLog(ev keyboard.KeyEvent) bool {
+	switch ev.Key {
+	case keyboard.KeyPgup:
+		t.logScroll = min(t.logScroll+LOG_HEIGHT-1, max(0, len(t.log)-LOG_HEIGHT))
+	case keyboard.KeyPgdn:
+		t.logScroll = max(t.logScroll-(LOG_HEIGHT-1), 0)
+	case keyboard.KeyEnd:
+		t.logScroll = 0
+	default:
+		return false
+	}
+	return true
+}
+
+func (t *tui) draw() {
+	t.scr.clear()
+	t.drawBoard()
+	t.drawCenter()
+	t.drawPlayers(PANEL_X, 0)
+	t.drawOwnership(PANEL_X, 11)
+	t.scr.field(0, BOARD_HEIGHT, t.status, SCREEN_WIDTH, STYLE_BOLD)
+	keys := "Up/Down: select  Enter: confirm  Space: toggle  Esc: back  PgUp/PgDn/End: scroll log  Ctrl+C: quit"
+	if t.chat {
+		keys += "  Tab: chat"
+	}
+	t.scr.field(0, BOARD_HEIGHT+1, keys, SCREEN_WIDTH, STYLE_DIM)
+	t.scr.flush(t.out)
+}
+
+// fieldPosition returns the column and row of a field on the 11x11 board, GO is in the bottom right corner.
+func fieldPosition(fieldIndex int) (int, int) {
+	switch {
+	case fieldIndex <= 10:
+		return 10 - fieldIndex, 10
+	case fieldIndex <= 20:
+		return 0, 10 - (fieldIndex - 10)
+	case fieldIndex <= 30:
+		return fieldIndex - 20, 0
+	default:
+		return 10, fieldIndex - 30
+	}
+}
+
+// shortName fits a field name into a board cell, e.g. "LightBlue1" -> "Light1".
+func shortName(field monopoly.FieldInfo) string {
+	width := CELL_WIDTH - 1
+	switch field.Type {
+	case monopoly.CHEST_FIELD:
+		return "Chest"
+	case monopoly.CHANCE_FIELD:
+		return "Chance"
+	case monopoly.TAX_FIELD:
+		return fmt.Sprintf("Tax%d", field.Tax)
+	case monopoly.GO_TO_JAIL_FIELD:
+		return "ToJail"
+	case monopoly.NO_ACTION_FIELD:
+		if field.Name == "Free Parking" {
+			return "Park"
+		}
+		return strings.Fields(field.Name)[0]
+	}
+	name := field.Name
+	if len(name) <= width {
+		return name
+	}
+	digits := strings.TrimLeftFunc(name, func(r rune) bool { return !unicode.IsDigit(r) })
+	letters := strings.TrimSuffix(name, digits)
+	return letters[:width-len(digits)] + digits
+}
+
+func houseMark(property *monopoly.Property, maxHouses int) (rune, string) {
+	switch {
+	case property.IsMortgaged:
+		return 'm', STYLE_DIM
+	case property.Houses == maxHouses:
+		return 'H', STYLE_BOLD
+	case property.Houses > 0:
+		return rune('0' + property.Houses), STYLE_BOLD
+	}
+	return ' ', STYLE_NONE
+}
+
+func (t *tui) drawBoard() {
+	for _, field := range t.board.Fields {
+		col, row := fieldPosition(field.FieldIndex)
+		x, y := col*CELL_WIDTH, row*CELL_HEIGHT
+		t.scr.box(x, y, CELL_WIDTH+1, CELL_HEIGHT+1)
+		t.scr.field(x+1, y+1, shortName(field), CELL_WIDTH-1, setStyles[field.Set])
+
+		// second line: player tokens, houses, owner
+		for id, player := range t.state.Players {
+			if !player.IsBankrupt && player.CurrentPosition == field.FieldIndex {
+				t.scr.set(x+1+id, y+2, rune('0'+id), playerStyle(id))
+			}
+		}
+		if field.PropertyIndex < 0 || t.state.Properties == nil {
+			continue
+		}
+		property := t.state.Properties[field.PropertyIndex]
+		mark, style := houseMark(property, t.state.Settings.MaxHouses)
+		t.scr.set(x+CELL_WIDTH-2, y+2, mark, style)
+		if property.Owner != nil {
+			t.scr.set(x+CELL_WIDTH-1, y+2, rune('0'+property.Owner.ID), playerStyle(property.Owner.ID)+";7")
+		}
+	}
+}
+
+func (t *tui) drawCenter() {
+	title := "MONOPOLY"
+	if t.tableID != "" {
+		title += "  table " + t.tableID
+	}
+	if t.state.Players != nil {
+		title += fmt.Sprintf("  round %d", t.state.Round)
+	}
+	if t.logScroll > 0 {
+		title += fmt.Sprintf("  (log -%d)", t.logScroll)
+	}
+	t.scr.field(CENTER_X, CENTER_Y, title, CENTER_WIDTH, STYLE_BOLD)
+
+	end := len(t.log) - t.logScroll
+	start := max(0, end-LOG_HEIGHT)
+	for i, line := range t.log[start:end] {
+		t.scr.field(CENTER_X, CENTER_Y+1+i, line, CENTER_WIDTH, STYLE_NONE)
+	}
+	t.scr.field(CENTER_X, MENU_Y-1, strings.Repeat("-", CENTER_WIDTH), CENTER_WIDTH, STYLE_DIM)
+	if t.menu != nil {
+		t.menu.draw(t.scr, CENTER_X, MENU_Y, CENTER_WIDTH, MENU_HEIGHT)
+	}
+}
+
+func (t *tui) drawPlayers(x int, y int) {
+	t.scr.text(x, y, "PLAYERS", STYLE_TITLE)
+	if t.state.Players == nil {
+		t.scr.text(x, y+1, "waiting for the game to start...", STYLE_DIM)
+		return
+	}
+	for id, player := range t.state.Players {
+		row := y + 1 + 2*id
+		if id == t.state.CurrentPlayerIdx {
+			t.scr.text(x, row, ">", STYLE_BOLD)
+		}
+		name := player.Name
+		if id == t.playerID {
+			name += " (you)"
+		}
+		t.scr.text(x+2, row, fmt.Sprintf("[%d]", id), playerStyle(id))
+		t.scr.field(x+6, row, name, 26, STYLE_BOLD)
+		t.scr.text(x+33, row, fmt.Sprintf("%6d$", player.Money), STYLE_NONE)
+
+		if player.IsBankrupt {
+			t.scr.text(x+6, row+1, "BANKRUPT", STYLE_DIM)
+			continue
+		}
+		houses := 0
+		for _, propertyId := range player.Properties {
+			houses += t.state.Properties[propertyId].Houses
+		}
+		info := fmt.Sprintf("at %-6s props %d houses %d", shortName(t.board.Fields[player.CurrentPosition]), len(player.Properties), houses)
+		if player.IsJailed {
+			info += " JAIL"
+		}
+		if player.JailCards > 0 {
+			info += fmt.Sprintf(" cards %d", player.JailCards)
+		}
+		t.scr.field(x+6, row+1, info, PANEL_WIDTH-6, STYLE_NONE)
+	}
+}
+
+// drawOwnership shows one row per color set: for each property the owner and houses,
+// '.' for properties owned by the bank.
+func (t *tui) drawOwnership(x int, y int) {
+	t.scr.text(x, y, "OWNERSHIP", STYLE_TITLE)
+	for i, set := range setOrder {
+		row := y + 1 + i
+		t.scr.field(x, row, set, 11, setStyles[set])
+		for j, propertyId := range t.board.Sets[set] {
+			col := x + 12 + 4*j
+			if t.state.Properties == nil {
+				t.scr.set(col, row, '.', STYLE_NONE)
+				continue
+			}
+			property := t.state.Properties[propertyId]
+			if property.Owner == nil {
+				t.scr.set(col, row, '.', STYLE_NONE)
+				continue
+			}
+			t.scr.set(col, row, rune('0'+property.Owner.ID), playerStyle(property.Owner.ID)+";7")
+			mark, style := houseMark(property, t.state.Settings.MaxHouses)
+			t.scr.set(col+1, row, mark, style)
+		}
+	}
+	legend := y + 2 + len(setOrder)
+	t.scr.text(x, legend, "owner id, 1-4 houses, H hotel, m mortgaged", STYLE_DIM)
+	t.scr.text(x, legend+1, "board cell: tokens | houses | owner", STYLE_DIM)
+}
+
+func (t *tui) propertyName(propertyId int) string {
+	return t.fieldOf[propertyId].Name
+}
+
+// rentLadder describes the rents of a property, e.g. "rent 2, set 4, houses 10/30/90/160, hotel 250".
+func (t *tui) rentLadder(propertyId int) string {
+	field := t.fieldOf[propertyId]
+	rents := field.Rents
+	switch field.Set {
+	case monopoly.RAILROAD:
+		return fmt.Sprintf("rent %d/%d/%d/%d by railroads owned", rents[0], rents[1], rents[2], rents[3])
+	case monopoly.UTILITY:
+		return fmt.Sprintf("rent %dx/%dx dice", rents[0], rents[1])
+	}
+	return fmt.Sprintf("rent %d, set %d, houses %d/%d/%d/%d, hotel %d", rents[0], rents[1], rents[2], rents[3], rents[4], rents[5], rents[6])
+}
+
+// rentSummary is a compact rentLadder for menu lines, e.g. "rent 2/4/10/30/90/160/250".
+func (t *tui) rentSummary(propertyId int) string {
+	field := t.fieldOf[propertyId]
+	if field.Set == monopoly.UTILITY {
+		return fmt.Sprintf("rent %dx/%dx dice", field.Rents[0], field.Rents[1])
+	}
+	rents := make([]string, len(field.Rents))
+	for idx, rent := range field.Rents {
+		rents[idx] = fmt.Sprint(rent)
+	}
+	return "rent " + strings.Join(rents, "/")
+}
+
+func (t *tui) ownerName(propertyId int) string {
+	owner := t.state.Properties[propertyId].Owner
+	if owner == nil {
+		return "bank"
+	}
+	return t.state.Players[owner.ID].Name
+}
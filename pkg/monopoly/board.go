@@ -0,0 +1,214 @@
+package monopoly
+
+// Field types reported in FieldInfo.Type
+const (
+	PROPERTY_FIELD   = "PROPERTY"
+	CHEST_FIELD      = "CHEST"
+	CHANCE_FIELD     = "CHANCE"
+	TAX_FIELD        = "TAX"
+	GO_TO_JAIL_FIELD = "GO_TO_JAIL"
+	NO_ACTION_FIELD  = "NO_ACTION"
+)
+
+// FieldInfo is a static, serializable description of a single board field.
+type FieldInfo struct {
+	FieldIndex    int
+	Name          string
+	Type          string
+	PropertyIndex int // -1 if the field is not a property
+	Set           string
+	Price         int
+	HousePrice    int
+	Tax           int
+	Rents         []int
+}
+
+// Board describes the static layout of the board used by every game.
+type Board struct {
+	Fields []FieldInfo
+	Sets   map[string][]int
+}
+
+func GetBoard() Board {
+	properties := newProperties()
+	fields := newFields(properties)
+	charges := newChargeMap()
+	board := Board{
+		Fields: make([]FieldInfo, len(fields)),
+		Sets:   newSets(),
+	}
+	for idx, field := range fields {
+		info := FieldInfo{
+			FieldIndex:    idx,
+			Name:          field.GetName(),
+			PropertyIndex: -1,
+		}
+		switch f := field.(type) {
+		case *Property:
+			info.Type = PROPERTY_FIELD
+			info.PropertyIndex = f.PropertyIndex
+			info.Set = f.Set
+			info.Price = f.Price
+			info.HousePrice = f.HousePrice
+			info.Rents = charges[f.PropertyIndex]
+		case *Chest:
+			info.Type = CHEST_FIELD
+		case *Chance:
+			info.Type = CHANCE_FIELD
+		case *TaxField:
+			info.Type = TAX_FIELD
+			info.Tax = f.Tax
+		case *GoToJailField:
+			info.Type = GO_TO_JAIL_FIELD
+		default:
+			info.Type = NO_ACTION_FIELD
+		}
+		board.Fields[idx] = info
+	}
+	return board
+}
+
+func newProperties() []*Property {
+	return []*Property{
+		NewProperty(1, 0, "Brown1", 60, 50, true, "Brown"),
+		NewProperty(3, 1, "Brown2", 60, 50, true, "Brown"),
+		NewProperty(5, 2, "Railroad1", 200, 0, false, RAILROAD),
+		NewProperty(6, 3, "LightBlue1", 100, 50, true, "Light Blue"),
+		NewProperty(8, 4, "LightBlue2", 100, 50, true, "Light Blue"),
+		NewProperty(9, 5, "LightBlue3", 120, 50, true, "Light Blue"),
+		NewProperty(11, 6, "Pink1", 140, 100, true, "Pink"),
+		NewProperty(12, 7, "Utility1", 150, 0, false, UTILITY),
+		NewProperty(13, 8, "Pink2", 140, 100, true, "Pink"),
+		NewProperty(14, 9, "Pink3", 160, 100, true, "Pink"),
+		NewProperty(15, 10, "Railroad2", 200, 0, false, RAILROAD),
+		NewProperty(16, 11, "Orange1", 180, 100, true, "Orange"),
+		NewProperty(18, 12, "Orange2", 180, 100, true, "Orange"),
+		NewProperty(19, 13, "Orange3", 200, 100, true, "Orange"),
+		NewProperty(21, 14, "Red1", 220, 150, true, "Red"),
+		NewProperty(23, 15, "Red2", 220, 150, true, "Red"),
+		NewProperty(24, 16, "Red3", 240, 150, true, "Red"),
+		NewProperty(25, 17, "Railroad3", 200, 0, false, RAILROAD),
+		NewProperty(26, 18, "Yellow1", 260, 150, true, "Yellow"),
+		NewProperty(27, 19, "Yellow2", 260, 150, true, "Yellow"),
+		NewProperty(28, 20, "Utility2", 150, 0, false, UTILITY),
+		NewProperty(29, 21, "Yellow3", 280, 150, true, "Yellow"),
+		NewProperty(31, 22, "Green1", 300, 200, true, "Green"),
+		NewProperty(32, 23, "Green2", 300, 200, true, "Green"),
+		NewProperty(34, 24, "Green3", 320, 200, true, "Green"),
+		NewProperty(35, 25, "Railroad4", 200, 0, false, RAILROAD),
+		NewProperty(37, 26, "DarkBlue1", 350, 200, true, "Dark Blue"),
+		NewProperty(39, 27, "DarkBlue2", 400, 200, true, "Dark Blue"),
+	}
+}
+
+func newFields(properties []*Property) []Field {
+	return []Field{
+		&NoActionField{FieldIndex: 0, Name: "GO"},
+		properties[0],
+		&Chest{FieldIndex: 2},
+		properties[1],
+		&TaxField{FieldIndex: 4, Name: "Income Tax", Tax: 200},
+		properties[2],
+		properties[3],
+		&Chance{FieldIndex: 7},
+		properties[4],
+		properties[5],
+		&NoActionField{FieldIndex: 10, Name: "Jail / Just Visiting"},
+		properties[6],
+		properties[7],
+		properties[8],
+		properties[9],
+		properties[10],
+		properties[11],
+		&Chest{FieldIndex: 17},
+		properties[12],
+		properties[13],
+		&NoActionField{FieldIndex: 20, Name: "Free Parking"},
+		properties[14],
+		&Chance{FieldIndex: 22},
+		properties[15],
+		properties[16],
+		properties[17],
+		properties[18],
+		properties[19],
+		properties[20],
+		properties[21],
+		&GoToJailField{FieldIndex: 30},
+		properties[22],
+		properties[23],
+		&Chest{FieldIndex: 33},
+		properties[24],
+		properties[25],
+		&Chance{FieldIndex: 36},
+		properties[26],
+		&TaxField{FieldIndex: 38, Name: "Luxury Tax", Tax: 100},
+		properties[27],
+	}
+}
+
+func newSets() map[string][]int {
+	return map[string][]int{
+		"Brown":      {0, 1},
+		"Light Blue": {3, 4, 5},
+		"Pink":       {6, 8, 9},
+		"Orange":     {11, 12, 13},
+		"Red":        {14, 15, 16},
+		"Yellow":     {18, 19, 20, 21},
+		"Green":      {22, 23, 24},
+		"Dark Blue":  {26, 27},
+		RAILROAD:     {2, 10, 17, 25},
+		UTILITY:      {7, 20},
+	}
+}
+
+func newChargeMap() map[int][]int {
+	return map[int][]int{
+		// Brown
+		0: {2, 4, 10, 30, 90, 160, 250},  // Mediterranean Avenue
+		1: {4, 8, 20, 60, 180, 320, 450}, // Baltic Avenue
+
+		// Light Blue
+		3: {6, 12, 30, 90, 270, 400, 550},  // Oriental Avenue
+		4: {6, 12, 30, 90, 270, 400, 550},  // Vermont Avenue
+		5: {8, 16, 40, 100, 300, 450, 600}, // Connecticut Avenue
+
+		// Pink
+		6: {10, 20, 50, 150, 450, 625, 750}, // St. Charles Place
+		8: {10, 20, 50, 150, 450, 625, 750}, // States Avenue
+		9: {12, 24, 60, 180, 500, 700, 900}, // Virginia Avenue
+
+		// Orange
+		11: {14, 28, 70, 200, 550, 750, 950},  // St. James Place
+		12: {14, 28, 70, 200, 550, 750, 950},  // Tennessee Avenue
+		13: {16, 32, 80, 220, 600, 800, 1000}, // New York Avenue
+
+		// Red
+		14: {18, 36, 90, 250, 700, 875, 1050},  // Kentucky Avenue
+		15: {18, 36, 90, 250, 700, 875, 1050},  // Indiana Avenue
+		16: {20, 40, 100, 300, 750, 925, 1100}, // Illinois Avenue
+
+		// Yellow
+		18: {22, 44, 110, 330, 800, 975, 1150},  // Atlantic Avenue
+		19: {22, 44, 110, 330, 800, 975, 1150},  // Ventnor Avenue
+		21: {24, 48, 120, 360, 850, 1025, 1200}, // Marvin Gardens
+
+		// Green
+		22: {26, 52, 130, 390, 900, 1100, 1275},  // Pacific Avenue
+		23: {26, 52, 130, 390, 900, 1100, 1275},  // North Carolina Avenue
+		24: {28, 56, 150, 450, 1000, 1200, 1400}, // Pennsylvania Avenue
+
+		// Dark Blue
+		26: {35, 70, 175, 500, 1100, 1300, 1500},  // Park Place
+		27: {50, 100, 200, 600, 1400, 1700, 2000}, // Boardwalk
+
+		// Railroads (only 4 values: 1 to 4 railroads)
+		2:  {25, 50, 100, 200}, // Reading Railroad
+		10: {25, 50, 100, 200}, // Pennsylvania Railroad
+		17: {25, 50, 100, 200}, // B&O Railroad
+		25: {25, 50, 100, 200}, // Short Line
+
+		// Utilities (special: rent = dice × multiplier)
+		7:  {4, 10}, // Electric Company: 4× or 10× dice roll
+		20: {4, 10}, // Water Works
+	}
+}
@@ -0,0 +1,27 @@
+package monopoly
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetBoard(t *testing.T) {
+	board := GetBoard()
+	assert.Equal(t, 40, len(board.Fields), "Board should have 40 fields")
+
+	properties := 0
+	for idx, field := range board.Fields {
+		assert.Equal(t, idx, field.FieldIndex, "Field index should match its position on the board")
+		if field.Type != PROPERTY_FIELD {
+			assert.Equal(t, -1, field.PropertyIndex, "Non-property field should not have a property index")
+			continue
+		}
+		assert.Equal(t, properties, field.PropertyIndex, "Properties should be ordered by field index")
+		assert.NotEmpty(t, field.Rents, "Property should have rents")
+		properties++
+	}
+	assert.Equal(t, 28, properties, "Board should have 28 properties")
+	assert.Equal(t, GO_TO_JAIL_FIELD, board.Fields[30].Type)
+	assert.Equal(t, 200, board.Fields[4].Tax)
+}
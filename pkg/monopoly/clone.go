@@ -0,0 +1,168 @@
+package monopoly
+
+import (
+	"context"
+	"math/rand"
+	"time"
+
+	cfg "monopoly/pkg/config"
+)
+
+// Clone returns a deep copy of the game, for bots which look ahead by playing the game further.
+// The copy has its own players, properties and random source, which continues with the same
+// random numbers as the game. It plays with the same io and logger. Undo is not copied, the copy asks
+// the io of the game directly and its decisions are not recorded in the undo log of the game.
+func (g *Game) Clone() *Game {
+	c := *g
+	c.undo = nil
+	if g.undo != nil {
+		c.io = g.undo.IMonopoly_IO
+	}
+	c.playerNames = append([]string{}, g.playerNames...)
+	c.source = g.source.clone()
+	c.randomSource = rand.New(c.source)
+	c.players = make([]*Player, len(g.players))
+	for i, player := range g.players {
+		c.players[i] = clonePlayer(player)
+	}
+	c.properties = cloneProperties(g.properties, c.players)
+	c.fields = newFields(c.properties)
+	return &c
+}
+
+// NewGameFromState creates a game in the given state, e.g. a copy of a game seen by a bot in a decision.
+// The game goes on with the turn of the player after state.CurrentPlayerIdx, what is left of the current
+// turn is not played, unless it is resolved with one of the Answer methods first. The game keeps the settings
+// of the state, a state without settings gets the default ones.
+func NewGameFromState(ctx context.Context, io IMonopoly_IO, logger Logger, state GameState, seed int64) *Game {
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	g := &Game{
+		ctx:              ctx,
+		io:               io,
+		logger:           logger,
+		seed:             seed,
+		source:           newCountingSource(seed),
+		currentPlayerIdx: state.CurrentPlayerIdx,
+		round:            state.Round,
+		buy_offer_tries:  state.BuyOfferTries,
+		sell_offer_tries: state.SellOfferTries,
+		std_actions_used: state.StdActionsUsed,
+		resumeIdx:        state.CurrentPlayerIdx + 1,
+		sets:             newSets(),
+		charge_map:       newChargeMap(),
+		settings:         state.Settings,
+	}
+	if g.settings == (cfg.GameSettings{}) {
+		g.settings = cfg.NewGameSettings()
+	}
+	g.randomSource = rand.New(g.source)
+	for _, player := range state.Players {
+		g.players = append(g.players, clonePlayer(player))
+		g.playerNames = append(g.playerNames, player.Name)
+	}
+	g.properties = cloneProperties(state.Properties, g.players)
+	g.fields = newFields(g.properties)
+	if g.resumeIdx >= len(g.players) {
+		g.resumeIdx = 0
+	} else {
+		// play starts every round by counting it
+		g.round--
+	}
+	return g
+}
+
+// Reseed replaces the random source, so that the game continues with another future.
+func (g *Game) Reseed(seed int64) {
+	g.seed = seed
+	g.source = newCountingSource(seed)
+	g.randomSource = rand.New(g.source)
+}
+
+// LimitRounds ends the game with ROUND_LIMIT after at most rounds more rounds.
+func (g *Game) LimitRounds(rounds int) {
+	g.settings.MaxRounds = min(g.settings.MaxRounds, g.round+rounds)
+}
+
+// AnswerStdAction resolves a standard action of the current player as if it was the answer of GetStdAction.
+// Further standard actions are asked from io.
+func (g *Game) AnswerStdAction(action_details ActionDetails) {
+	if g.std_actions_used >= g.settings.MaxStdActionsPerTurn {
+		return
+	}
+	g.applyStdAction(action_details, g.getStdActionList())
+}
+
+// AnswerJailAction resolves the jail action of the current player as if it was the answer of GetJailAction.
+func (g *Game) AnswerJailAction(action JailAction) {
+	g.resolveJailAction(action, g.getJailActionList(g.getCurrPlayer()))
+}
+
+// AnswerBuyDecision resolves the buy decision of the current player, an auction is asked from io.
+func (g *Game) AnswerBuyDecision(propertyId int, buy bool) {
+	g.resolveBuyDecision(g.properties[propertyId], buy)
+}
+
+// AnswerBiddingDecision resolves the bid of the player, other bids of the auction are asked from io.
+// The players who already passed are not known, so every player still in the game may bid again.
+func (g *Game) AnswerBiddingDecision(player int, propertyId int, bid int, currentPrice int, currentWinner int) {
+	answered := false
+	g.runAuction(g.properties[propertyId], g.auctionQueue(player), currentPrice, currentWinner, func(bidderID int, curr_price int, auction_winner int) int {
+		if !answered {
+			answered = true
+			return bid
+		}
+		return g.io.BiddingDecision(bidderID, g.getState(), propertyId, curr_price, auction_winner)
+	})
+}
+
+// AnswerTradeOffer resolves an answer to BuyFromPlayerDecision or SellToPlayerDecision.
+func (g *Game) AnswerTradeOffer(buyer int, propertyId int, price int, accepted bool) {
+	property := g.properties[propertyId]
+	if !accepted || property.Owner == nil || property.Owner.ID == buyer {
+		return
+	}
+	seller := property.Owner
+	g.charge(g.players[buyer], price, seller)
+	if g.players[buyer].IsBankrupt {
+		return
+	}
+	g.transferProperty(seller, g.players[buyer], propertyId)
+}
+
+// Clone returns a deep copy of the state. The state passed to a decision points to the players and
+// properties of the running game, a bot which keeps it after the decision has to copy it.
+func (s GameState) Clone() GameState {
+	c := s
+	c.Players = make([]*Player, len(s.Players))
+	for i, player := range s.Players {
+		c.Players[i] = clonePlayer(player)
+	}
+	c.Properties = cloneProperties(s.Properties, c.Players)
+	return c
+}
+
+// clone copies the state of the generator, so the copy continues with the same random numbers.
+func (s *countingSource) clone() *countingSource {
+	pcg := *s.pcg
+	return &countingSource{pcg: &pcg, draws: s.draws}
+}
+
+func clonePlayer(player *Player) *Player {
+	p := *player
+	p.Properties = append([]int{}, player.Properties...)
+	return &p
+}
+
+func cloneProperties(properties []*Property, owners []*Player) []*Property {
+	cloned := make([]*Property, len(properties))
+	for i, property := range properties {
+		p := *property
+		if property.Owner != nil {
+			p.Owner = owners[property.Owner.ID]
+		}
+		cloned[i] = &p
+	}
+	return cloned
+}
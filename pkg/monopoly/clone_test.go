@@ -0,0 +1,146 @@
+package monopoly
+
+import (
+	"context"
+	"testing"
+
+	cfg "monopoly/pkg/config"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// buyAllIO buys every property it lands on and never makes other decisions.
+type buyAllIO struct {
+	finish FinishOption
+	winner int
+	rounds int
+	money  []int
+}
+
+func (io *buyAllIO) Init() []string {
+	return playerNames
+}
+
+func (io *buyAllIO) GetStdAction(player int, state GameState, availableActions FullActionList) ActionDetails {
+	if state.Charge > 0 {
+		return ActionDetails{Action: availableActions.Actions[0], PropertyId: append(availableActions.MortgageList, availableActions.SellHouseList...)[0]}
+	}
+	return ActionDetails{Action: NOACTION}
+}
+
+func (io *buyAllIO) GetJailAction(player int, state GameState, available []JailAction) JailAction {
+	return BAIL
+}
+
+func (io *buyAllIO) BuyDecision(player int, state GameState, propertyId int) bool {
+	return true
+}
+
+func (io *buyAllIO) BuyFromPlayerDecision(player int, state GameState, propertyId int, price int) bool {
+	return false
+}
+
+func (io *buyAllIO) SellToPlayerDecision(player int, state GameState, propertyId int, price int) bool {
+	return false
+}
+
+func (io *buyAllIO) BiddingDecision(player int, state GameState, propertyId int, currentPrice int, currentWinner int) int {
+	return 0
+}
+
+func (io *buyAllIO) Finish(f FinishOption, winner int, state GameState) {
+	io.finish = f
+	io.winner = winner
+	io.rounds = state.Round
+	io.money = nil
+	for _, player := range state.Players {
+		io.money = append(io.money, player.Money)
+	}
+}
+
+func TestCloneIsIndependent(t *testing.T) {
+	io := &buyAllIO{}
+	game := NewGame(context.Background(), io, silentLogger{}, 5)
+	game.rollDice()
+	game.addProperty(game.players[1], 3)
+
+	clone := game.Clone()
+	assert.Equal(t, game.randomSource.Int63(), clone.randomSource.Int63(), "Clone should draw the same random numbers")
+	clone.players[1].AddMoney(100)
+	clone.transferProperty(clone.players[1], clone.players[2], 3)
+
+	assert.Equal(t, 1500, game.players[1].Money)
+	assert.Equal(t, []int{3}, game.players[1].Properties)
+	assert.Same(t, game.players[1], game.properties[3].Owner)
+	assert.Same(t, clone.players[2], clone.properties[3].Owner)
+	assert.Same(t, clone.properties[3], clone.fields[clone.properties[3].FieldIndex])
+}
+
+func TestClonePlaysTheSameGame(t *testing.T) {
+	io := &buyAllIO{}
+	game := NewGame(context.Background(), io, silentLogger{}, 11)
+	clone := game.Clone()
+
+	game.Start()
+	original := *io
+	clone.Start()
+	assert.Equal(t, original, *io, "Clone should play the same game as the original")
+}
+
+func TestCloneWithUndo(t *testing.T) {
+	io := &buyAllIO{}
+	game := NewGame(context.Background(), io, silentLogger{}, 7)
+	game.EnableUndo()
+	clone := game.Clone()
+	assert.Nil(t, clone.undo)
+	assert.Same(t, io, clone.io, "Clone should ask the io under the undo of the game")
+
+	clone.Start()
+	assert.Empty(t, game.undo.decisions, "The decisions of the clone should not be recorded by the game")
+}
+
+func TestNewGameFromState(t *testing.T) {
+	io := &buyAllIO{}
+	game := NewGame(context.Background(), io, silentLogger{}, 3)
+	game.round = 7
+	game.currentPlayerIdx = 2
+	game.addProperty(game.players[0], 5)
+	settings := cfg.NewGameSettings()
+	settings.MaxHouses = 3
+	game.SetSettings(settings)
+	state := game.getState()
+
+	restored := NewGameFromState(context.Background(), io, silentLogger{}, state, 3)
+	assert.Equal(t, settings, restored.settings, "The game should keep the settings of the state")
+	assert.Equal(t, 6, restored.round, "The current round should be finished first")
+	assert.Equal(t, 3, restored.resumeIdx)
+	assert.Same(t, restored.players[0], restored.properties[5].Owner)
+	assert.NotSame(t, game.players[0], restored.players[0])
+
+	restored.LimitRounds(2)
+	restored.Start()
+	assert.Contains(t, []FinishOption{WIN, ROUND_LIMIT}, io.finish)
+	assert.LessOrEqual(t, io.rounds, 9)
+
+	game.currentPlayerIdx = 3
+	restored = NewGameFromState(context.Background(), io, silentLogger{}, game.getState(), 3)
+	assert.Equal(t, 7, restored.round)
+	assert.Equal(t, 0, restored.resumeIdx)
+
+	state.Settings = cfg.GameSettings{}
+	restored = NewGameFromState(context.Background(), io, silentLogger{}, state, 3)
+	assert.Equal(t, cfg.NewGameSettings(), restored.settings, "A state without settings should get the default ones")
+}
+
+func TestAnswerBuyDecision(t *testing.T) {
+	io := &buyAllIO{}
+	game := NewGame(context.Background(), io, silentLogger{}, 3)
+	game.currentPlayerIdx = 1
+	game.players[1].CurrentPosition = game.properties[4].FieldIndex
+	restored := NewGameFromState(context.Background(), io, silentLogger{}, game.getState(), 3)
+
+	restored.AnswerBuyDecision(4, true)
+	assert.Same(t, restored.players[1], restored.properties[4].Owner)
+	assert.Equal(t, 1500-restored.properties[4].Price, restored.players[1].Money)
+	assert.Nil(t, game.properties[4].Owner, "The original game should not change")
+}
@@ -3,6 +3,7 @@ package monopoly
 import (
 	"container/list"
 	"context"
+	"errors"
 	"fmt"
 	"math/rand"
 	"slices"
@@ -11,6 +12,10 @@ import (
 	cfg "monopoly/pkg/config"
 )
 
+// ErrGameCancelled is raised (as a panic value) when the game context is done. IO implementations
+// may raise it from a blocked decision too, Start recovers it and finishes the game with CANCELLED.
+var ErrGameCancelled = errors.New("game cancelled")
+
 const RAILROAD = "Railroad"
 const UTILITY = "Utility"
 
@@ -30,6 +35,11 @@ type Game struct {
 	sell_offer_tries int
 	std_actions_used int
 	randomSource     *rand.Rand
+	source           *countingSource
+	seed             int64
+	playerNames      []string
+	undo             *undoIO // nil unless EnableUndo was called
+	resumeIdx        int     // first player of the next round, see NewGameFromState
 	finished         bool
 }
 
@@ -42,162 +52,45 @@ func NewGame(ctx context.Context, io IMonopoly_IO, logger Logger, seed int64) *G
 	if seed == 0 {
 		seed = time.Now().UnixNano()
 	}
-	g.randomSource = rand.New(rand.NewSource(seed))
-
-	g.round = 1
-	g.currentPlayerIdx = 0
+	g.seed = seed
+	g.settings = cfg.NewGameSettings()
 
-	player_names := g.io.Init()
-	if len(player_names) < 2 || len(player_names) > 4 {
+	g.playerNames = g.io.Init()
+	if len(g.playerNames) < 2 || len(g.playerNames) > 4 {
 		panic("Players count must be between 2 and 4")
 	}
+	g.reset()
 
-	g.players = make([]*Player, len(player_names))
-	for i, name := range player_names {
-		g.players[i] = NewPlayer(i, name, 1500)
-	}
+	g.logger.Log(fmt.Sprintf("Game initialized successfully. Seed: %d", seed))
+	return g
+}
 
-	g.properties = []*Property{
-		NewProperty(1, 0, "Brown1", 60, 50, true, "Brown"),
-		NewProperty(3, 1, "Brown2", 60, 50, true, "Brown"),
-		NewProperty(5, 2, "Railroad1", 200, 0, false, RAILROAD),
-		NewProperty(6, 3, "LightBlue1", 100, 50, true, "Light Blue"),
-		NewProperty(8, 4, "LightBlue2", 100, 50, true, "Light Blue"),
-		NewProperty(9, 5, "LightBlue3", 120, 50, true, "Light Blue"),
-		NewProperty(11, 6, "Pink1", 140, 100, true, "Pink"),
-		NewProperty(12, 7, "Utility1", 150, 0, false, UTILITY),
-		NewProperty(13, 8, "Pink2", 140, 100, true, "Pink"),
-		NewProperty(14, 9, "Pink3", 160, 100, true, "Pink"),
-		NewProperty(15, 10, "Railroad2", 200, 0, false, RAILROAD),
-		NewProperty(16, 11, "Orange1", 180, 100, true, "Orange"),
-		NewProperty(18, 12, "Orange2", 180, 100, true, "Orange"),
-		NewProperty(19, 13, "Orange3", 200, 100, true, "Orange"),
-		NewProperty(21, 14, "Red1", 220, 150, true, "Red"),
-		NewProperty(23, 15, "Red2", 220, 150, true, "Red"),
-		NewProperty(24, 16, "Red3", 240, 150, true, "Red"),
-		NewProperty(25, 17, "Railroad3", 200, 0, false, RAILROAD),
-		NewProperty(26, 18, "Yellow1", 260, 150, true, "Yellow"),
-		NewProperty(27, 19, "Yellow2", 260, 150, true, "Yellow"),
-		NewProperty(28, 20, "Utility2", 150, 0, false, UTILITY),
-		NewProperty(29, 21, "Yellow3", 280, 150, true, "Yellow"),
-		NewProperty(31, 22, "Green1", 300, 200, true, "Green"),
-		NewProperty(32, 23, "Green2", 300, 200, true, "Green"),
-		NewProperty(34, 24, "Green3", 320, 200, true, "Green"),
-		NewProperty(35, 25, "Railroad4", 200, 0, false, RAILROAD),
-		NewProperty(37, 26, "DarkBlue1", 350, 200, true, "Dark Blue"),
-		NewProperty(39, 27, "DarkBlue2", 400, 200, true, "Dark Blue"),
-	}
-
-	g.fields = []Field{
-		&NoActionField{FieldIndex: 0, Name: "GO"},
-		g.properties[0],
-		&Chest{FieldIndex: 2},
-		g.properties[1],
-		&TaxField{FieldIndex: 4, Name: "Income Tax", Tax: 200},
-		g.properties[2],
-		g.properties[3],
-		&Chance{FieldIndex: 7},
-		g.properties[4],
-		g.properties[5],
-		&NoActionField{FieldIndex: 10, Name: "Jail / Just Visiting"},
-		g.properties[6],
-		g.properties[7],
-		g.properties[8],
-		g.properties[9],
-		g.properties[10],
-		g.properties[11],
-		&Chest{FieldIndex: 17},
-		g.properties[12],
-		g.properties[13],
-		&NoActionField{FieldIndex: 20, Name: "Free Parking"},
-		g.properties[14],
-		&Chance{FieldIndex: 22},
-		g.properties[15],
-		g.properties[16],
-		g.properties[17],
-		g.properties[18],
-		g.properties[19],
-		g.properties[20],
-		g.properties[21],
-		&GoToJailField{FieldIndex: 30},
-		g.properties[22],
-		g.properties[23],
-		&Chest{FieldIndex: 33},
-		g.properties[24],
-		g.properties[25],
-		&Chance{FieldIndex: 36},
-		g.properties[26],
-		&TaxField{FieldIndex: 38, Name: "Luxury Tax", Tax: 100},
-		g.properties[27],
-	}
-
-	g.sets = map[string][]int{
-		"Brown":      {0, 1},
-		"Light Blue": {3, 4, 5},
-		"Pink":       {6, 8, 9},
-		"Orange":     {11, 12, 13},
-		"Red":        {14, 15, 16},
-		"Yellow":     {18, 19, 20, 21},
-		"Green":      {22, 23, 24},
-		"Dark Blue":  {26, 27},
-		RAILROAD:     {2, 10, 17, 25},
-		UTILITY:      {7, 20},
-	}
-
-	g.charge_map = map[int][]int{
-		// Brown
-		0: {2, 4, 10, 30, 90, 160, 250},  // Mediterranean Avenue
-		1: {4, 8, 20, 60, 180, 320, 450}, // Baltic Avenue
-
-		// Light Blue
-		3: {6, 12, 30, 90, 270, 400, 550},  // Oriental Avenue
-		4: {6, 12, 30, 90, 270, 400, 550},  // Vermont Avenue
-		5: {8, 16, 40, 100, 300, 450, 600}, // Connecticut Avenue
-
-		// Pink
-		6: {10, 20, 50, 150, 450, 625, 750}, // St. Charles Place
-		8: {10, 20, 50, 150, 450, 625, 750}, // States Avenue
-		9: {12, 24, 60, 180, 500, 700, 900}, // Virginia Avenue
-
-		// Orange
-		11: {14, 28, 70, 200, 550, 750, 950},  // St. James Place
-		12: {14, 28, 70, 200, 550, 750, 950},  // Tennessee Avenue
-		13: {16, 32, 80, 220, 600, 800, 1000}, // New York Avenue
-
-		// Red
-		14: {18, 36, 90, 250, 700, 875, 1050},  // Kentucky Avenue
-		15: {18, 36, 90, 250, 700, 875, 1050},  // Indiana Avenue
-		16: {20, 40, 100, 300, 750, 925, 1100}, // Illinois Avenue
-
-		// Yellow
-		18: {22, 44, 110, 330, 800, 975, 1150},  // Atlantic Avenue
-		19: {22, 44, 110, 330, 800, 975, 1150},  // Ventnor Avenue
-		21: {24, 48, 120, 360, 850, 1025, 1200}, // Marvin Gardens
-
-		// Green
-		22: {26, 52, 130, 390, 900, 1100, 1275},  // Pacific Avenue
-		23: {26, 52, 130, 390, 900, 1100, 1275},  // North Carolina Avenue
-		24: {28, 56, 150, 450, 1000, 1200, 1400}, // Pennsylvania Avenue
-
-		// Dark Blue
-		26: {35, 70, 175, 500, 1100, 1300, 1500},  // Park Place
-		27: {50, 100, 200, 600, 1400, 1700, 2000}, // Boardwalk
-
-		// Railroads (only 4 values: 1 to 4 railroads)
-		2:  {25, 50, 100, 200}, // Reading Railroad
-		10: {25, 50, 100, 200}, // Pennsylvania Railroad
-		17: {25, 50, 100, 200}, // B&O Railroad
-		25: {25, 50, 100, 200}, // Short Line
-
-		// Utilities (special: rent = dice × multiplier)
-		7:  {4, 10}, // Electric Company: 4× or 10× dice roll
-		20: {4, 10}, // Water Works
-	}
+// SetSettings replaces the default settings, it has to be called before the game starts.
+func (g *Game) SetSettings(settings cfg.GameSettings) {
+	g.settings = settings
+}
 
-	g.settings = cfg.NewGameSettings()
+// reset puts the game in its initial state, with the random source at the start of the seed's sequence.
+func (g *Game) reset() {
+	g.source = newCountingSource(g.seed)
+	g.randomSource = rand.New(g.source)
 
-	g.logger.Log(fmt.Sprintf("Game initialized successfully. Seed: %d", seed))
-	return g
+	g.round = 1
+	g.currentPlayerIdx = 0
+	g.buy_offer_tries = 0
+	g.sell_offer_tries = 0
+	g.std_actions_used = 0
+	g.finished = false
+
+	g.players = make([]*Player, len(g.playerNames))
+	for i, name := range g.playerNames {
+		g.players[i] = NewPlayer(i, name, 1500)
+	}
+
+	g.properties = newProperties()
+	g.fields = newFields(g.properties)
+	g.sets = newSets()
+	g.charge_map = newChargeMap()
 }
 
 func (g *Game) getState() GameState {
@@ -209,6 +102,7 @@ func (g *Game) getState() GameState {
 		SellOfferTries:   g.sell_offer_tries,
 		BuyOfferTries:    g.buy_offer_tries,
 		StdActionsUsed:   g.std_actions_used,
+		Settings:         g.settings,
 	}
 }
 
@@ -227,8 +121,30 @@ func (g *Game) getCurrPlayer() *Player {
 }
 
 func (g *Game) Start() {
+	for !g.play() {
+		// a player took back a decision, the game was rewound to it
+	}
+}
+
+// play runs the game until it is over. It returns false if the game was rewound by an undo and has to be played again.
+func (g *Game) play() (over bool) {
 	defer func() {
 		if r := recover(); r != nil {
+			if err, ok := r.(error); ok && errors.Is(err, ErrUndo) && g.undo != nil {
+				g.rewind(g.undo.asking)
+				over = false
+				return
+			}
+			if g.undo != nil && g.undo.replaying {
+				g.logger = g.undo.logger
+			}
+			if err, ok := r.(error); ok && errors.Is(err, ErrGameCancelled) {
+				g.finished = true
+				g.logger.LogWithState("Game cancelled", g.getState())
+				g.io.Finish(CANCELLED, -1, g.getState())
+				over = true
+				return
+			}
 			g.logger.Error(fmt.Sprintf("Game ended with an error: %v", r), g.getState())
 			panic(r)
 		}
@@ -238,6 +154,9 @@ func (g *Game) Start() {
 		g.round++
 		g.logger.Log(fmt.Sprintf("Starting round %d", g.round))
 		for idx, player := range g.players {
+			if idx < g.resumeIdx {
+				continue
+			}
 			g.currentPlayerIdx = idx
 			if !g.continueRound(idx) {
 				continue
@@ -253,12 +172,14 @@ func (g *Game) Start() {
 			}
 			g.makeMove(1, 0, 0)
 		}
+		g.resumeIdx = 0
 		if g.round > g.settings.MaxRounds {
 			g.finished = true
 			break
 		}
 	}
 	g.endGame()
+	return true
 }
 
 func (g *Game) resetRoundState(idx int, player *Player) {
@@ -304,12 +225,14 @@ func (g *Game) endDraw() {
 	g.io.Finish(DRAW, -1, g.getState())
 }
 
-func (g *Game) continueRound(currentPlayer int) bool {
-	select {
-	case <-g.ctx.Done():
-		panic("Game cancelled")
-	default:
+func (g *Game) checkCancelled() {
+	if g.ctx.Err() != nil {
+		panic(ErrGameCancelled)
 	}
+}
+
+func (g *Game) continueRound(currentPlayer int) bool {
+	g.checkCancelled()
 	if g.finished {
 		return false
 	}
@@ -383,6 +306,12 @@ func (g *Game) handleJail() {
 	if !g.continueRound(g.currentPlayerIdx) {
 		return
 	}
+	action_list := g.getJailActionList(player)
+	action := g.io.GetJailAction(g.currentPlayerIdx, g.getState(), action_list)
+	g.resolveJailAction(action, action_list)
+}
+
+func (g *Game) getJailActionList(player *Player) []JailAction {
 	var action_list []JailAction
 	action_list = append(action_list, BAIL)
 	if player.JailCards > 0 {
@@ -391,9 +320,14 @@ func (g *Game) handleJail() {
 	if player.RoundsInJail < 3 {
 		action_list = append(action_list, ROLL_DICE)
 	}
-	action := g.io.GetJailAction(g.currentPlayerIdx, g.getState(), action_list)
+	return action_list
+}
+
+func (g *Game) resolveJailAction(action JailAction, action_list []JailAction) {
+	player := g.getCurrPlayer()
 	if !slices.Contains(action_list, action) {
 		g.logger.Log(fmt.Sprintf("%s attempted an invalid jail action: %v", player.Name, action))
+		player.InvalidMoves++
 		g.bankrupt(player, nil)
 		return
 	}
@@ -459,6 +393,12 @@ func (g *Game) standardActions() {
 		return
 	}
 
+	action_list := g.getStdActionList()
+	action_details := g.io.GetStdAction(g.currentPlayerIdx, g.getState(), action_list)
+	g.applyStdAction(action_details, action_list)
+}
+
+func (g *Game) getStdActionList() FullActionList {
 	action_list := FullActionList{}
 
 	action_list.MortgageList = g.getMortgageList(g.currentPlayerIdx)
@@ -487,7 +427,11 @@ func (g *Game) standardActions() {
 	if len(action_list.SellHouseList) > 0 {
 		action_list.Actions = append(action_list.Actions, SELLHOUSE)
 	}
-	action_details := g.io.GetStdAction(g.currentPlayerIdx, g.getState(), action_list)
+	return action_list
+}
+
+// applyStdAction resolves the chosen standard action and asks for the next one, until the player chooses NOACTION.
+func (g *Game) applyStdAction(action_details ActionDetails, action_list FullActionList) {
 	if action_details.Action == NOACTION {
 		g.std_actions_used = 0
 		return
@@ -506,6 +450,7 @@ func (g *Game) resolveStandardAction(player_id int, action_details ActionDetails
 
 	if !slices.Contains(available.Actions, action_details.Action) {
 		g.logger.Log(fmt.Sprintf("%s attempted an invalid action: %v", player.Name, action_details.Action))
+		player.InvalidMoves++
 		g.bankrupt(player, nil)
 		return
 	}
@@ -677,6 +622,8 @@ func (g *Game) getBuyHouseList(player_id int) []int {
 			buy_list = append(buy_list, temp_list...)
 		}
 	}
+	// the sets come in the random order of the map, a seeded game has to offer the same list
+	slices.Sort(buy_list)
 	return buy_list
 }
 
@@ -706,19 +653,11 @@ func (g *Game) checkHouses(property *Property) bool {
 }
 
 func (g *Game) calculateNetWorth(player *Player) int {
-	net_worth := player.Money
-	for _, id := range player.Properties {
-		property := g.properties[id]
-		if property.IsMortgaged {
-			continue
-		}
-		net_worth += property.Price / 2
-		net_worth += property.Houses * (property.HousePrice / 2)
-	}
-	return net_worth
+	return LiquidationValue(g.getState(), player.ID)
 }
 
-func (g *Game) chargePlayer(player_id int, amount int, target *Player) {
+// chargePlayer lets the player raise the money if needed and returns how much of it was paid, see charge.
+func (g *Game) chargePlayer(player_id int, amount int, target *Player) (paid int) {
 	player := g.players[player_id]
 	target_name := "Bank"
 	if target != nil {
@@ -726,15 +665,13 @@ func (g *Game) chargePlayer(player_id int, amount int, target *Player) {
 	}
 	g.logger.Log(fmt.Sprintf("%s has to pay %d$ to %s", player.Name, amount, target_name))
 	if player.Money >= amount {
-		g.charge(player, amount, target)
-		return
+		return g.charge(player, amount, target)
 	}
 
 	net_worth := g.calculateNetWorth(player)
 	if net_worth < amount {
 		g.logger.Log(fmt.Sprintf("%s cannot afford to pay %d$ (net worth: %d$)", player.Name, amount, net_worth))
-		g.charge(player, amount, target)
-		return
+		return g.charge(player, amount, target)
 	}
 	for player.Money < amount {
 		g.logger.Log(fmt.Sprintf("%s cannot afford to pay %d$, (cash: %d$)", player.Name, amount, player.Money))
@@ -755,10 +692,10 @@ func (g *Game) chargePlayer(player_id int, amount int, target *Player) {
 			if target != nil {
 				g.addMoney(target, amount) // situation where player has properties to sell but goes bankrupt because of wrong decision. Target should still receive the money
 			}
-			return
+			return amount
 		}
 	}
-	g.charge(player, amount, target)
+	return g.charge(player, amount, target)
 }
 
 func (g *Game) mortgage(player_id int, propertyId int) {
@@ -932,7 +869,7 @@ func (g *Game) doForProperty(p *Property) {
 	if p.Owner != nil {
 		g.logger.Log(fmt.Sprintf("Property owned by %s", p.Owner.Name))
 		amount := g.checkCharge(p)
-		g.chargePlayer(g.currentPlayerIdx, amount, p.Owner)
+		p.Owner.RentEarned += g.chargePlayer(g.currentPlayerIdx, amount, p.Owner)
 		return
 	}
 
@@ -942,6 +879,11 @@ func (g *Game) doForProperty(p *Property) {
 		return
 	}
 	wantToBuy := g.io.BuyDecision(g.currentPlayerIdx, g.getState(), p.PropertyIndex)
+	g.resolveBuyDecision(p, wantToBuy)
+}
+
+func (g *Game) resolveBuyDecision(p *Property, wantToBuy bool) {
+	player := g.getCurrPlayer()
 	if !wantToBuy {
 		g.logger.Log(fmt.Sprintf("%s does not want to buy the property", player.Name))
 		g.auction(p, g.currentPlayerIdx)
@@ -994,6 +936,13 @@ func (g *Game) checkCharge(p *Property) int {
 
 func (g *Game) auction(property *Property, first_player_id int) {
 	g.logger.Log(fmt.Sprintf("Auctioning property %d", property.PropertyIndex))
+	g.runAuction(property, g.auctionQueue(first_player_id), g.settings.MinPrice, -1, func(bidderID int, curr_price int, auction_winner int) int {
+		return g.io.BiddingDecision(bidderID, g.getState(), property.PropertyIndex, curr_price, auction_winner)
+	})
+}
+
+// auctionQueue returns the players still in the game, starting with the first bidder.
+func (g *Game) auctionQueue(first_player_id int) *list.List {
 	queue := list.New()
 	for _, player := range g.players[first_player_id:] {
 		if !player.IsBankrupt {
@@ -1005,16 +954,20 @@ func (g *Game) auction(property *Property, first_player_id int) {
 			queue.PushBack(player.ID)
 		}
 	}
-	curr_price := g.settings.MinPrice
-	auction_winner := -1
+	return queue
+}
+
+// runAuction asks the players in the queue for bids, until nobody outbids the auction winner.
+func (g *Game) runAuction(property *Property, queue *list.List, curr_price int, auction_winner int, bid func(bidderID int, curr_price int, auction_winner int) int) {
 	for queue.Len() > 0 {
 		bidderID := queue.Front().Value.(int)
 		queue.Remove(queue.Front())
 		if auction_winner == bidderID {
 			break
 		}
+		g.checkCancelled()
 		bidder := g.players[bidderID]
-		bid_offer := g.io.BiddingDecision(bidderID, g.getState(), property.PropertyIndex, curr_price, auction_winner)
+		bid_offer := bid(bidderID, curr_price, auction_winner)
 		if bid_offer <= curr_price {
 			g.logger.Log(fmt.Sprintf("%s passes", bidder.Name))
 		} else if bid_offer > bidder.Money {
@@ -1050,17 +1003,19 @@ func (g *Game) addProperty(player *Player, property_id int) {
 	g.logger.LogWithState(fmt.Sprintf("Property %d is now owned by %s", property_id, player.Name), g.getState())
 }
 
-func (g *Game) charge(player *Player, amount int, target *Player) {
+// charge returns how much was paid, a bankrupt player pays only the cash left.
+func (g *Game) charge(player *Player, amount int, target *Player) (paid int) {
 	if player.Money < amount {
+		paid = max(0, player.Money)
 		g.bankrupt(player, target)
-		return
+		return paid
 	}
 	player.RemoveMoney(amount)
 	g.logger.LogWithState(fmt.Sprintf("%s lost %d$", player.Name, amount), g.getState())
 	if target != nil {
 		g.addMoney(target, amount)
 	}
-
+	return amount
 }
 
 func (g *Game) bankrupt(player *Player, creditor *Player) {
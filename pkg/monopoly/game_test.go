@@ -322,11 +322,11 @@ func TestSeededRollDice(t *testing.T) {
 		d1   int
 		d2   int
 	}{
-		{1, 6, 4},
-		{2, 5, 1},
-		{22, 4, 4},
-		{42, 6, 6},
-		{56, 1, 1},
+		{1, 1, 5},
+		{2, 6, 2},
+		{12, 1, 1},
+		{14, 4, 6},
+		{39, 6, 6},
 	}
 	for _, test := range tests {
 		for i := 0; i < 100; i++ {
@@ -450,7 +450,7 @@ func TestJailRollDice(t *testing.T) {
 		{1, 0, 1, 10, 1, true},
 		{1, 1, 1, 10, 2, true},
 		{1, 2, 1, 10, 3, true},
-		{0, 0, 56, 20, 0, false},
+		{0, 0, 12, 19, 0, false},
 	}
 	for _, test := range tests {
 		io := &MockMonopolyIO{}
@@ -510,7 +510,7 @@ func TestJailBail(t *testing.T) {
 		io.On("GetStdAction", test.playerId, mock.Anything, mock.Anything).Return(ActionDetails{
 			Action: NOACTION,
 		})
-		game := NewGame(context.Background(), io, logger, 1)
+		game := NewGame(context.Background(), io, logger, 14)
 		game.currentPlayerIdx = test.playerId
 		player := game.players[test.playerId]
 		player.IsJailed = true
@@ -994,6 +994,26 @@ func TestBuyPropertyList(t *testing.T) {
 	}
 }
 
+func TestGetBuyHouseList(t *testing.T) {
+	io := &MockMonopolyIO{}
+	io.On("Init").Return(playerNames[:4])
+	logger := &MockLogger{}
+	logger.On("Init").Return()
+	logger.On("Log", mock.Anything).Return()
+	game := NewGame(context.Background(), io, logger, 1)
+	player := game.players[0]
+	for _, propertyId := range []int{26, 27, 11, 12, 13, 0, 1} {
+		property := game.properties[propertyId]
+		property.Owner = player
+		property.CanBuildHouse = true
+		player.Properties = append(player.Properties, propertyId)
+	}
+	game.properties[12].Houses = game.settings.MaxHouses
+	for range 10 {
+		assert.Equal(t, []int{0, 1, 11, 13, 26, 27}, game.getBuyHouseList(0), "Houses should be listed in the order of the properties")
+	}
+}
+
 func TestBankruptTransferingProperties(t *testing.T) {
 	tests := []struct {
 		players                  []string
@@ -1314,6 +1334,7 @@ func TestDoForProperty(t *testing.T) {
 		{2, 200, 5, 2, 3, 100, false, 192, 108},
 		{2, 200, 5, 1, 2, 200, false, 200, 200},
 		{3, 600, 8, 0, -1, 0, true, 600, 0},
+		{1, 4, 3, 0, 2, 400, false, -1, 404},
 	}
 	for _, test := range tests {
 		io := &MockMonopolyIO{}
@@ -1344,6 +1365,7 @@ func TestDoForProperty(t *testing.T) {
 		} else {
 			assert.Equal(t, test.expectedPlayerCash, player.Money, "Player's cash should match expected after doForProperty")
 			assert.Equal(t, test.expectedOwnerCash, property.Owner.Money, "Owner's cash should match expected after doForProperty")
+			assert.Equal(t, test.expectedOwnerCash-test.ownerCash, property.Owner.RentEarned, "Rent should be counted for the owner")
 
 		}
 	}
@@ -1566,3 +1588,44 @@ func TestSetPosition(t *testing.T) {
 		assert.Equal(t, test.expectedPosition, player.CurrentPosition, "Player's position should match expected after setting position")
 	}
 }
+
+func TestStartCancelled(t *testing.T) {
+	io := &MockMonopolyIO{}
+	io.On("Init").Return(playerNames[:2])
+	io.On("Finish", CANCELLED, -1, mock.Anything).Return()
+	logger := &MockLogger{}
+	logger.On("Log", mock.Anything).Return()
+	logger.On("LogWithState", mock.Anything, mock.Anything).Return()
+	ctx, cancel := context.WithCancel(context.Background())
+	game := NewGame(ctx, io, logger, 0)
+	cancel()
+
+	assert.NotPanics(t, game.Start, "Cancelled game should not panic")
+	io.AssertCalled(t, "Finish", CANCELLED, -1, mock.Anything)
+	logger.AssertCalled(t, "LogWithState", "Game cancelled", mock.Anything)
+}
+
+func TestStartCancelledByIO(t *testing.T) {
+	io := &MockMonopolyIO{}
+	io.On("Init").Return(playerNames[:2])
+	io.On("GetStdAction", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		panic(ErrGameCancelled)
+	})
+	io.On("BuyDecision", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		panic(ErrGameCancelled)
+	})
+	io.On("GetJailAction", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		panic(ErrGameCancelled)
+	})
+	io.On("BiddingDecision", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		panic(ErrGameCancelled)
+	})
+	io.On("Finish", CANCELLED, -1, mock.Anything).Return()
+	logger := &MockLogger{}
+	logger.On("Log", mock.Anything).Return()
+	logger.On("LogWithState", mock.Anything, mock.Anything).Return()
+	game := NewGame(context.Background(), io, logger, 0)
+
+	assert.NotPanics(t, game.Start, "Game cancelled by IO should not panic")
+	io.AssertCalled(t, "Finish", CANCELLED, -1, mock.Anything)
+}
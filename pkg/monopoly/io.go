@@ -1,6 +1,10 @@
 package monopoly
 
-import "fmt"
+import (
+	"fmt"
+
+	cfg "monopoly/pkg/config"
+)
 
 type StdAction int
 
@@ -47,6 +51,7 @@ type GameState struct {
 	SellOfferTries   int
 	BuyOfferTries    int
 	StdActionsUsed   int
+	Settings         cfg.GameSettings
 }
 
 func formatStr(str string, length int) string {
@@ -118,6 +123,7 @@ const (
 	WIN FinishOption = iota
 	DRAW
 	ROUND_LIMIT
+	CANCELLED // game context was cancelled before the game was over, there is no winner
 )
 
 type IMonopoly_IO interface {
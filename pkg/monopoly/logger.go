@@ -14,6 +14,7 @@ type Logger interface {
 
 type ConsoleLogger struct {
 	StateID int64
+	Prefix  string // printed before every message, e.g. table ID when many games share the console
 }
 
 func (c *ConsoleLogger) Init() {
@@ -28,7 +29,7 @@ func (c *ConsoleLogger) Init() {
 
 func (c *ConsoleLogger) Log(message string) {
 	time.Sleep(500 * time.Millisecond)
-	println(message)
+	println(c.Prefix + message)
 }
 
 func (c *ConsoleLogger) LogState(state GameState) {}
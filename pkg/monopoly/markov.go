@@ -0,0 +1,223 @@
+package monopoly
+
+import (
+	"math"
+	cfg "monopoly/pkg/config"
+	"sync"
+)
+
+// Number of cards drawn by doForChest and doForChance, see resolveChanceOrChest
+const (
+	chestCards  = 7
+	chanceCards = 8
+)
+
+// LandingStats are long-run movement statistics of a single player, computed from a Markov
+// chain over the board. The chain follows the engine rules: three doubles in a row, the
+// Go To Jail field and the jail cards send the player to jail, the chance cards can move
+// the player to GO or to a random field. A jailed player is assumed to roll for doubles
+// for as long as the rules allow and to pay the bail afterwards.
+type LandingStats struct {
+	// PerTurn is the expected number of times a player lands on each field during one turn
+	PerTurn []float64
+	// LapsPerTurn is the expected number of times a player passes GO during one turn
+	LapsPerTurn float64
+}
+
+// PerLap is the expected number of times a player lands on the field while going once around the board.
+func (s LandingStats) PerLap(fieldIndex int) float64 {
+	return s.PerTurn[fieldIndex] / s.LapsPerTurn
+}
+
+var landings = sync.OnceValue(computeLandings)
+
+// Landings returns the landing statistics of the standard board.
+// They are computed once and shared, callers must not modify them.
+func Landings() LandingStats {
+	return landings()
+}
+
+const (
+	doublesStates = 3 // doubles rolled in a row before the current roll
+	jailStates    = 3 // failed attempts to roll doubles in jail, see handleJail
+)
+
+// landingChain describes the state reached before every roll: a free player on a field with
+// some doubles rolled in a row, or a jailed player after some failed attempts to roll doubles.
+type landingChain struct {
+	fields     []FieldInfo
+	transition [][]float64
+	landings   [][]float64 // expected landings on each field during the roll from a state
+	passes     []float64   // probability of passing GO during the roll from a state
+}
+
+func (c *landingChain) freeState(position int, doubles int) int {
+	return position*doublesStates + doubles
+}
+
+func (c *landingChain) jailState(rounds int) int {
+	return len(c.fields)*doublesStates + rounds
+}
+
+func (c *landingChain) size() int {
+	return len(c.fields)*doublesStates + jailStates
+}
+
+// land adds a landing on the field to the state and follows the field action,
+// calling next with where the move ends and whether the player was jailed.
+func (c *landingChain) land(state int, position int, probability float64, depth int, next func(position int, jailed bool, probability float64)) {
+	c.landings[state][position] += probability
+	switch c.fields[position].Type {
+	case GO_TO_JAIL_FIELD:
+		next(cfg.JAIL_POSITION, true, probability)
+	case CHEST_FIELD:
+		card := probability / chestCards
+		next(cfg.JAIL_POSITION, true, card)
+		c.passes[state] += card
+		c.landings[state][0] += card
+		next(0, false, card)
+		next(position, false, probability-2*card)
+	case CHANCE_FIELD:
+		card := probability / chanceCards
+		next(cfg.JAIL_POSITION, true, card)
+		c.passes[state] += card
+		c.landings[state][0] += card
+		next(0, false, card)
+		// moving to a random field may hit another chance field, the tail is negligible
+		if depth < 2 {
+			for target := range c.fields {
+				c.land(state, target, card/float64(len(c.fields)), depth+1, next)
+			}
+		} else {
+			next(position, false, card)
+		}
+		next(position, false, probability-3*card)
+	default:
+		next(position, false, probability)
+	}
+}
+
+// roll adds the transitions of a single dice roll of a free player.
+func (c *landingChain) roll(state int, position int, doubles int, probability float64) {
+	jail := c.jailState(0)
+	for d1 := 1; d1 <= 6; d1++ {
+		for d2 := 1; d2 <= 6; d2++ {
+			if d1 == d2 && doubles == doublesStates-1 {
+				// the third doubles in a row go to jail instead of moving, see makeMove
+				c.transition[state][jail] += probability
+				continue
+			}
+			target := position + d1 + d2
+			if target >= len(c.fields) {
+				c.passes[state] += probability
+				target -= len(c.fields)
+			}
+			c.land(state, target, probability, 0, func(end int, jailed bool, p float64) {
+				switch {
+				case jailed:
+					c.transition[state][jail] += p
+				case d1 == d2:
+					c.transition[state][c.freeState(end, doubles+1)] += p
+				default:
+					c.transition[state][c.freeState(end, 0)] += p
+				}
+			})
+		}
+	}
+}
+
+func newLandingChain(fields []FieldInfo) *landingChain {
+	c := &landingChain{fields: fields}
+	size := c.size()
+	c.transition = make([][]float64, size)
+	c.landings = make([][]float64, size)
+	c.passes = make([]float64, size)
+	for state := range size {
+		c.transition[state] = make([]float64, size)
+		c.landings[state] = make([]float64, len(fields))
+	}
+
+	const dice = 1.0 / 36
+	for position := range fields {
+		for doubles := range doublesStates {
+			c.roll(c.freeState(position, doubles), position, doubles, dice)
+		}
+	}
+	for rounds := range jailStates {
+		state := c.jailState(rounds)
+		// doubles release the player and count as the first doubles of the turn, see jailRollDice
+		for d := 1; d <= 6; d++ {
+			c.land(state, cfg.JAIL_POSITION+2*d, dice, 0, func(end int, jailed bool, p float64) {
+				if jailed {
+					c.transition[state][c.jailState(0)] += p
+				} else {
+					c.transition[state][c.freeState(end, 1)] += p
+				}
+			})
+		}
+		if rounds < jailStates-1 {
+			c.transition[state][c.jailState(rounds+1)] += 30 * dice
+		} else {
+			// after the third failed roll the bail is paid at the start of the next turn
+			c.transition[state][c.freeState(cfg.JAIL_POSITION, 0)] += 30 * dice
+		}
+	}
+	return c
+}
+
+// stationary returns the long-run share of rolls made from each state.
+func (c *landingChain) stationary() []float64 {
+	size := c.size()
+	current := make([]float64, size)
+	current[c.freeState(0, 0)] = 1
+	next := make([]float64, size)
+	for range 10000 {
+		// half of the mass stays in place, which keeps the iteration from oscillating
+		for idx := range next {
+			next[idx] = current[idx] / 2
+		}
+		for from, share := range current {
+			if share == 0 {
+				continue
+			}
+			for to, p := range c.transition[from] {
+				next[to] += share * p / 2
+			}
+		}
+		diff := 0.0
+		for idx := range next {
+			diff = max(diff, math.Abs(next[idx]-current[idx]))
+		}
+		current, next = next, current
+		if diff < 1e-13 {
+			break
+		}
+	}
+	return current
+}
+
+func computeLandings() LandingStats {
+	board := GetBoard()
+	chain := newLandingChain(board.Fields)
+	shares := chain.stationary()
+
+	perRoll := make([]float64, len(board.Fields))
+	passes := 0.0
+	turns := 0.0
+	for state, share := range shares {
+		for field, landings := range chain.landings[state] {
+			perRoll[field] += share * landings
+		}
+		passes += share * chain.passes[state]
+		// a turn starts with the first roll of a free player or with a jailed player
+		if state >= chain.jailState(0) || state%doublesStates == 0 {
+			turns += share
+		}
+	}
+
+	stats := LandingStats{PerTurn: make([]float64, len(board.Fields)), LapsPerTurn: passes / turns}
+	for field := range perRoll {
+		stats.PerTurn[field] = perRoll[field] / turns
+	}
+	return stats
+}
@@ -0,0 +1,27 @@
+package monopoly
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLandings(t *testing.T) {
+	stats := Landings()
+	assert.Equal(t, 40, len(stats.PerTurn))
+
+	total := 0.0
+	for _, landings := range stats.PerTurn {
+		assert.GreaterOrEqual(t, landings, 0.0)
+		total += landings
+	}
+	// doubles and cards give more than one landing per turn, jail turns give none
+	assert.Greater(t, total, 1.0)
+	assert.Less(t, total, 1.5)
+	assert.Greater(t, stats.LapsPerTurn, 0.1)
+	assert.Less(t, stats.LapsPerTurn, 0.25)
+
+	// players leaving jail land on the orange set much more often than on the first street
+	assert.Greater(t, stats.PerTurn[19], stats.PerTurn[1])
+	assert.Greater(t, stats.PerLap(24), stats.PerLap(1))
+}
@@ -15,6 +15,8 @@ type Player struct {
 	RoundsPlayed    int
 	MaxProperties   int
 	MaxHouses       int
+	RentEarned      int // rent paid by other players, a bankrupt player pays only the cash left
+	InvalidMoves    int // actions the player chose from outside of the available ones
 }
 
 func NewPlayer(id int, name string, money int) *Player {
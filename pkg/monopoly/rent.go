@@ -0,0 +1,177 @@
+package monopoly
+
+import (
+	"math"
+	"sync"
+)
+
+// The functions below estimate what properties are worth in a game state, using the landing
+// statistics of the standard board. They are shared by the bots and the console client.
+// Rents are expected values per turn of a single opponent, multiply them by the number of
+// opponents for the income of a whole round.
+
+var board = sync.OnceValue(GetBoard)
+
+// propertyFields maps the property index to its field
+var propertyFields = sync.OnceValue(func() []FieldInfo {
+	fields := make([]FieldInfo, len(newProperties()))
+	for _, field := range board().Fields {
+		if field.PropertyIndex >= 0 {
+			fields[field.PropertyIndex] = field
+		}
+	}
+	return fields
+})
+
+// Owners returns the owner id of every property, -1 for the bank.
+// Change the result to ask how rents would look after a trade.
+func Owners(state GameState) []int {
+	owners := make([]int, len(state.Properties))
+	for idx, property := range state.Properties {
+		owners[idx] = -1
+		if property.Owner != nil {
+			owners[idx] = property.Owner.ID
+		}
+	}
+	return owners
+}
+
+// Monopolies returns the number of sets the player owns completely, railroads and utilities are not counted.
+func Monopolies(playerId int, owners []int) int {
+	count := 0
+	for set, properties := range board().Sets {
+		if set == RAILROAD || set == UTILITY {
+			continue
+		}
+		complete := true
+		for _, propertyId := range properties {
+			complete = complete && owners[propertyId] == playerId
+		}
+		if complete {
+			count++
+		}
+	}
+	return count
+}
+
+// Rent returns the rent of the property if the properties were owned as given, following checkCharge.
+func Rent(state GameState, propertyId int, owners []int) int {
+	return rentWithHouses(state, propertyId, owners, state.Properties[propertyId].Houses)
+}
+
+func rentWithHouses(state GameState, propertyId int, owners []int, houses int) int {
+	property := state.Properties[propertyId]
+	field := propertyFields()[propertyId]
+	if property.IsMortgaged || owners[propertyId] < 0 {
+		return 0
+	}
+	owned := 0
+	for _, other := range board().Sets[field.Set] {
+		if owners[other] == owners[propertyId] {
+			owned++
+		}
+	}
+	switch field.Set {
+	case RAILROAD, UTILITY:
+		return field.Rents[owned-1]
+	}
+	if owned < len(board().Sets[field.Set]) {
+		return field.Rents[0]
+	}
+	return field.Rents[1+houses]
+}
+
+// ExpectedRent is the rent an opponent pays for the property during one turn.
+func ExpectedRent(state GameState, propertyId int, owners []int) float64 {
+	return Landings().PerTurn[propertyFields()[propertyId].FieldIndex] * float64(Rent(state, propertyId, owners))
+}
+
+// ExpectedSetRent is the rent an opponent pays for the properties of the set during one turn.
+func ExpectedSetRent(state GameState, set string, owners []int) float64 {
+	rent := 0.0
+	for _, propertyId := range board().Sets[set] {
+		rent += ExpectedRent(state, propertyId, owners)
+	}
+	return rent
+}
+
+// ExpectedIncome is the rent an opponent pays to the player during one turn.
+func ExpectedIncome(state GameState, playerId int, owners []int) float64 {
+	income := 0.0
+	for propertyId, owner := range owners {
+		if owner == playerId {
+			income += ExpectedRent(state, propertyId, owners)
+		}
+	}
+	return income
+}
+
+// ExpectedCharges is what the player pays in rents and taxes during one turn.
+func ExpectedCharges(state GameState, playerId int, owners []int) float64 {
+	charges := 0.0
+	forEachCharge(state, playerId, owners, func(chance float64, amount int) {
+		charges += chance * float64(amount)
+	})
+	return charges
+}
+
+// HousePayback is the number of turns of a single opponent after which the given number of new
+// houses on the property pay for themselves, +Inf if they cannot be built or would not raise the rent.
+func HousePayback(state GameState, propertyId int, houses int) float64 {
+	property := state.Properties[propertyId]
+	field := propertyFields()[propertyId]
+	if property.HousePrice == 0 || houses <= 0 || 1+property.Houses+houses >= len(field.Rents) {
+		return math.Inf(1)
+	}
+	owners := Owners(state)
+	gain := rentWithHouses(state, propertyId, owners, property.Houses+houses) - Rent(state, propertyId, owners)
+	if gain <= 0 {
+		return math.Inf(1)
+	}
+	return float64(houses*property.HousePrice) / (Landings().PerTurn[field.FieldIndex] * float64(gain))
+}
+
+// LiquidationValue is the money the player can raise by selling houses and mortgaging
+// properties, the net worth which decides the winner at the round limit.
+func LiquidationValue(state GameState, playerId int) int {
+	player := state.Players[playerId]
+	value := player.Money
+	for _, propertyId := range player.Properties {
+		property := state.Properties[propertyId]
+		if !property.IsMortgaged {
+			value += property.Price/2 + property.Houses*(property.HousePrice/2)
+		}
+	}
+	return value
+}
+
+// ChargeRisk is the chance that the player lands on a rent or a tax above budget during one turn.
+// It ignores the current position of the player, the chance is the long-run average.
+func ChargeRisk(state GameState, playerId int, budget int) float64 {
+	risk := 0.0
+	forEachCharge(state, playerId, Owners(state), func(chance float64, amount int) {
+		if amount > budget {
+			risk += chance
+		}
+	})
+	return min(risk, 1)
+}
+
+// BankruptcyRisk is the chance that the player goes bankrupt during one turn.
+func BankruptcyRisk(state GameState, playerId int) float64 {
+	return ChargeRisk(state, playerId, LiquidationValue(state, playerId))
+}
+
+// forEachCharge calls charge with the expected landings on every field charging the player.
+func forEachCharge(state GameState, playerId int, owners []int, charge func(chance float64, amount int)) {
+	landings := Landings()
+	for _, field := range board().Fields {
+		amount := field.Tax
+		if field.PropertyIndex >= 0 && owners[field.PropertyIndex] >= 0 && owners[field.PropertyIndex] != playerId {
+			amount = Rent(state, field.PropertyIndex, owners)
+		}
+		if amount > 0 {
+			charge(landings.PerTurn[field.FieldIndex], amount)
+		}
+	}
+}
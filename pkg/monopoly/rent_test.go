@@ -0,0 +1,63 @@
+package monopoly
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRent(t *testing.T) {
+	game := NewGame(context.Background(), &buyAllIO{}, silentLogger{}, 1)
+	game.addProperty(game.players[0], 2)
+	game.addProperty(game.players[0], 0)
+	state := game.getState()
+	owners := Owners(state)
+	assert.Equal(t, 25, Rent(state, 2, owners))
+	assert.Equal(t, 2, Rent(state, 0, owners))
+	assert.Equal(t, 0, Rent(state, 1, owners), "The bank does not charge rent")
+
+	owners[10] = 0
+	owners[1] = 0
+	assert.Equal(t, 50, Rent(state, 2, owners), "The rent should grow with the railroads owned")
+	assert.Equal(t, 4, Rent(state, 0, owners), "The rent should double for a full set")
+	assert.Greater(t, ExpectedSetRent(state, "Brown", owners), ExpectedRent(state, 0, owners))
+
+	game.properties[0].IsMortgaged = true
+	assert.Equal(t, 0, Rent(state, 0, owners))
+	assert.Equal(t, ExpectedRent(state, 1, owners)+ExpectedRent(state, 2, owners)+ExpectedRent(state, 10, owners), ExpectedIncome(state, 0, owners))
+	assert.Equal(t, 1, Monopolies(0, owners), "Mortgaged sets are still complete, railroads are not counted")
+	assert.Equal(t, 0, Monopolies(1, owners))
+}
+
+func TestHousePayback(t *testing.T) {
+	game := NewGame(context.Background(), &buyAllIO{}, silentLogger{}, 1)
+	game.addProperty(game.players[0], 26)
+	state := game.getState()
+	assert.True(t, math.IsInf(HousePayback(state, 26, 1), 1), "Houses need a full set")
+
+	game.addProperty(game.players[0], 27)
+	one, three := HousePayback(state, 27, 1), HousePayback(state, 27, 3)
+	assert.Greater(t, one, three, "The third house raises the rent the most")
+	assert.InDelta(t, 200/(Landings().PerTurn[39]*100), one, 1e-9)
+	assert.True(t, math.IsInf(HousePayback(state, 27, 6), 1))
+}
+
+func TestChargeRisk(t *testing.T) {
+	game := NewGame(context.Background(), &buyAllIO{}, silentLogger{}, 1)
+	state := game.getState()
+	landings := Landings()
+	assert.Equal(t, 0.0, ChargeRisk(state, 0, 1000))
+	assert.InDelta(t, landings.PerTurn[4], ChargeRisk(state, 0, 150), 1e-9, "Only the income tax is above 150$")
+	assert.InDelta(t, landings.PerTurn[4]+landings.PerTurn[38], ChargeRisk(state, 0, 50), 1e-9)
+
+	game.addProperty(game.players[1], 27)
+	assert.InDelta(t, landings.PerTurn[4]+landings.PerTurn[38]+landings.PerTurn[39], ChargeRisk(state, 0, 40), 1e-9)
+	assert.InDelta(t, landings.PerTurn[4]+landings.PerTurn[38], ChargeRisk(state, 1, 40), 1e-9, "Own properties do not charge")
+	assert.InDelta(t, 200*landings.PerTurn[4]+100*landings.PerTurn[38]+50*landings.PerTurn[39], ExpectedCharges(state, 0, Owners(state)), 1e-9)
+
+	game.players[0].Money = 0
+	assert.Equal(t, ChargeRisk(state, 0, 0), BankruptcyRisk(state, 0), "A player without cash and properties goes bankrupt on any charge")
+	assert.Equal(t, 1500+200, LiquidationValue(state, 1))
+}
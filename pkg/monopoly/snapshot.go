@@ -0,0 +1,58 @@
+package monopoly
+
+import (
+	"encoding/json"
+	"os"
+)
+
+// PropertySnapshot stores the owner by ID, so that a snapshot has no pointers and can be saved as JSON.
+type PropertySnapshot struct {
+	PropertyIndex int
+	Name          string
+	OwnerID       int // -1 if not owned
+	IsMortgaged   bool
+	Houses        int
+}
+
+// Snapshot is a serializable copy of the game state, saved e.g. when a game is cancelled.
+type Snapshot struct {
+	Round            int
+	CurrentPlayerIdx int
+	Players          []Player
+	Properties       []PropertySnapshot
+}
+
+func NewSnapshot(state GameState) Snapshot {
+	snapshot := Snapshot{
+		Round:            state.Round,
+		CurrentPlayerIdx: state.CurrentPlayerIdx,
+	}
+	for _, player := range state.Players {
+		p := *player
+		p.Properties = append([]int{}, player.Properties...)
+		snapshot.Players = append(snapshot.Players, p)
+	}
+	for _, property := range state.Properties {
+		owner := -1
+		if property.Owner != nil {
+			owner = property.Owner.ID
+		}
+		snapshot.Properties = append(snapshot.Properties, PropertySnapshot{
+			PropertyIndex: property.PropertyIndex,
+			Name:          property.Name,
+			OwnerID:       owner,
+			IsMortgaged:   property.IsMortgaged,
+			Houses:        property.Houses,
+		})
+	}
+	return snapshot
+}
+
+// SaveSnapshot writes the snapshot of state to path as JSON.
+func SaveSnapshot(path string, state GameState) error {
+	data, err := json.MarshalIndent(NewSnapshot(state), "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
@@ -0,0 +1,28 @@
+package monopoly
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewSnapshot(t *testing.T) {
+	players := []*Player{NewPlayer(0, "player1", 1500), NewPlayer(1, "player2", 1500)}
+	properties := newProperties()
+	properties[3].Owner = players[1]
+	properties[3].Houses = 2
+	players[1].Properties = []int{3}
+
+	snapshot := NewSnapshot(GameState{Players: players, Properties: properties, Round: 7, CurrentPlayerIdx: 1})
+
+	assert.Equal(t, 7, snapshot.Round)
+	assert.Equal(t, 1, snapshot.CurrentPlayerIdx)
+	assert.Len(t, snapshot.Players, 2)
+	assert.Len(t, snapshot.Properties, 28)
+	assert.Equal(t, -1, snapshot.Properties[0].OwnerID)
+	assert.Equal(t, 1, snapshot.Properties[3].OwnerID)
+	assert.Equal(t, 2, snapshot.Properties[3].Houses)
+
+	players[1].Properties = append(players[1].Properties, 4)
+	assert.Equal(t, []int{3}, snapshot.Players[1].Properties, "Snapshot should not share properties slice with the game")
+}
@@ -0,0 +1,172 @@
+package monopoly
+
+import (
+	"errors"
+	"fmt"
+	"math/rand/v2"
+)
+
+// ErrUndo is raised (as a panic value) by an IMonopoly_IO decision when the player takes back
+// their previous decision instead of answering. It is allowed only if Game.CanUndo returns true.
+var ErrUndo = errors.New("undo requested")
+
+// countingSource counts the random numbers drawn, so that undo can tell if anything random happened.
+// It draws from a PCG generator, whose state is small enough to be copied when the game is cloned.
+type countingSource struct {
+	pcg   *rand.PCG
+	draws int
+}
+
+func newCountingSource(seed int64) *countingSource {
+	return &countingSource{pcg: rand.NewPCG(uint64(seed), 0)}
+}
+
+func (s *countingSource) Int63() int64 {
+	s.draws++
+	return int64(s.pcg.Uint64() >> 1)
+}
+
+func (s *countingSource) Uint64() uint64 {
+	s.draws++
+	return s.pcg.Uint64()
+}
+
+func (s *countingSource) Seed(seed int64) {
+	s.pcg.Seed(uint64(seed), 0)
+}
+
+type decision struct {
+	method string // IMonopoly_IO method which asked for the decision
+	player int
+	value  any
+	draws  int // random numbers drawn before the decision
+}
+
+// undoIO records every decision of the game. Since the game is deterministic for a seed and
+// the decisions, the state before any decision is restored by playing the game again from the
+// start with the recorded decisions, up to that decision.
+type undoIO struct {
+	IMonopoly_IO
+	game      *Game
+	decisions []decision
+	next      int // index of the next decision, decisions before len(decisions) are replayed
+	asking    int // player of the decision in progress
+	replaying bool
+	undoer    int // player who took back the decision being replayed to
+	logger    Logger
+}
+
+func (u *undoIO) decide(method string, player int, ask func() any) any {
+	if u.next < len(u.decisions) {
+		d := u.decisions[u.next]
+		if d.method != method || d.player != player {
+			panic(fmt.Sprintf("undo replay diverged at decision %d: %s for player %d, recorded %s for player %d", u.next, method, player, d.method, d.player))
+		}
+		u.next++
+		return d.value
+	}
+	if u.replaying {
+		u.endReplay()
+	}
+	u.asking = player
+	value := ask()
+	u.decisions = append(u.decisions, decision{method: method, player: player, value: value, draws: u.game.source.draws})
+	u.next++
+	return value
+}
+
+func (u *undoIO) endReplay() {
+	u.replaying = false
+	u.game.logger = u.logger
+	player := u.game.players[u.undoer]
+	u.game.logger.LogWithState(fmt.Sprintf("%s takes back their last decision", player.Name), u.game.getState())
+}
+
+// lastUndoable returns the index of the player's last decision if nothing random happened since, -1 otherwise.
+func (u *undoIO) lastUndoable(player int, draws int) int {
+	for idx := u.next - 1; idx >= 0; idx-- {
+		d := u.decisions[idx]
+		if d.player != player {
+			continue
+		}
+		if d.draws != draws {
+			return -1
+		}
+		return idx
+	}
+	return -1
+}
+
+func (u *undoIO) GetStdAction(player int, state GameState, availableActions FullActionList) ActionDetails {
+	return u.decide("GetStdAction", player, func() any {
+		return u.IMonopoly_IO.GetStdAction(player, state, availableActions)
+	}).(ActionDetails)
+}
+
+func (u *undoIO) GetJailAction(player int, state GameState, available []JailAction) JailAction {
+	return u.decide("GetJailAction", player, func() any {
+		return u.IMonopoly_IO.GetJailAction(player, state, available)
+	}).(JailAction)
+}
+
+func (u *undoIO) BuyDecision(player int, state GameState, propertyId int) bool {
+	return u.decide("BuyDecision", player, func() any {
+		return u.IMonopoly_IO.BuyDecision(player, state, propertyId)
+	}).(bool)
+}
+
+func (u *undoIO) BuyFromPlayerDecision(player int, state GameState, propertyId int, price int) bool {
+	return u.decide("BuyFromPlayerDecision", player, func() any {
+		return u.IMonopoly_IO.BuyFromPlayerDecision(player, state, propertyId, price)
+	}).(bool)
+}
+
+func (u *undoIO) SellToPlayerDecision(player int, state GameState, propertyId int, price int) bool {
+	return u.decide("SellToPlayerDecision", player, func() any {
+		return u.IMonopoly_IO.SellToPlayerDecision(player, state, propertyId, price)
+	}).(bool)
+}
+
+func (u *undoIO) BiddingDecision(player int, state GameState, propertyId int, currentPrice int, currentWinner int) int {
+	return u.decide("BiddingDecision", player, func() any {
+		return u.IMonopoly_IO.BiddingDecision(player, state, propertyId, currentPrice, currentWinner)
+	}).(int)
+}
+
+type silentLogger struct{}
+
+func (silentLogger) Log(message string)                           {}
+func (silentLogger) LogWithState(message string, state GameState) {}
+func (silentLogger) LogState(state GameState)                     {}
+func (silentLogger) Error(message string, state GameState)        {}
+
+// EnableUndo lets players take back their last decision as long as nothing random happened since,
+// see ErrUndo. It must be called before Start.
+func (g *Game) EnableUndo() {
+	if g.undo != nil {
+		return
+	}
+	g.undo = &undoIO{IMonopoly_IO: g.io, game: g}
+	g.io = g.undo
+}
+
+// CanUndo tells if the player can take back their last decision.
+func (g *Game) CanUndo(player int) bool {
+	return g.undo != nil && !g.undo.replaying && g.undo.lastUndoable(player, g.source.draws) >= 0
+}
+
+// rewind restarts the game from its initial state, replaying the decisions made before
+// the last decision of the player. The events of the replay are not logged.
+func (g *Game) rewind(player int) {
+	if !g.CanUndo(player) {
+		panic(fmt.Sprintf("player %d cannot undo their last decision", player))
+	}
+	u := g.undo
+	u.decisions = u.decisions[:u.lastUndoable(player, g.source.draws)]
+	u.next = 0
+	u.replaying = true
+	u.undoer = player
+	u.logger = g.logger
+	g.logger = silentLogger{}
+	g.reset()
+}
@@ -0,0 +1,98 @@
+package monopoly
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// undoTestIO declines the first property it is offered, then takes the decision back
+// at its next chance and buys the property instead.
+type undoTestIO struct {
+	game          *Game
+	declined      int
+	declinedBy    int
+	undone        bool
+	reoffered     int
+	finished      bool
+	undoAfterDice bool
+}
+
+func (io *undoTestIO) Init() []string {
+	return playerNames[:2]
+}
+
+func (io *undoTestIO) tryUndo(player int) {
+	if io.declined >= 0 && !io.undone && player == io.declinedBy && io.game.CanUndo(player) {
+		io.undone = true
+		panic(ErrUndo)
+	}
+}
+
+func (io *undoTestIO) GetStdAction(player int, state GameState, availableActions FullActionList) ActionDetails {
+	io.tryUndo(player)
+	return ActionDetails{Action: NOACTION}
+}
+
+func (io *undoTestIO) GetJailAction(player int, state GameState, available []JailAction) JailAction {
+	io.tryUndo(player)
+	return BAIL
+}
+
+func (io *undoTestIO) BuyDecision(player int, state GameState, propertyId int) bool {
+	// the dice were rolled since any earlier decision of the player
+	if io.game.CanUndo(player) {
+		io.undoAfterDice = true
+	}
+	if io.declined < 0 {
+		io.declined = propertyId
+		io.declinedBy = player
+		return false
+	}
+	if io.undone && io.reoffered < 0 {
+		io.reoffered = propertyId
+	}
+	return true
+}
+
+func (io *undoTestIO) BuyFromPlayerDecision(player int, state GameState, propertyId int, price int) bool {
+	return false
+}
+
+func (io *undoTestIO) SellToPlayerDecision(player int, state GameState, propertyId int, price int) bool {
+	return false
+}
+
+func (io *undoTestIO) BiddingDecision(player int, state GameState, propertyId int, currentPrice int, currentWinner int) int {
+	io.tryUndo(player)
+	return 0
+}
+
+func (io *undoTestIO) Finish(f FinishOption, winner int, state GameState) {
+	io.finished = true
+}
+
+func TestUndo(t *testing.T) {
+	io := &undoTestIO{declined: -1, reoffered: -1}
+	game := NewGame(context.Background(), io, silentLogger{}, 42)
+	io.game = game
+	game.EnableUndo()
+
+	assert.NotPanics(t, game.Start)
+	assert.True(t, io.finished, "Game should finish after an undo")
+	assert.True(t, io.undone, "Player should be able to undo declining the property during the auction")
+	assert.Equal(t, io.declined, io.reoffered, "The undone decision should be asked again")
+	assert.Equal(t, io.declinedBy, game.properties[io.declined].Owner.ID, "Player should own the property bought after the undo")
+	assert.False(t, io.undoAfterDice, "Decisions made before a dice roll cannot be undone")
+}
+
+func TestUndoDisabled(t *testing.T) {
+	io := &undoTestIO{declined: -1, reoffered: -1}
+	game := NewGame(context.Background(), io, silentLogger{}, 42)
+	io.game = game
+
+	assert.False(t, game.CanUndo(0))
+	assert.NotPanics(t, game.Start)
+	assert.False(t, io.undone, "Undo should not be possible unless enabled")
+}
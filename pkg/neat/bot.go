@@ -1,313 +1,87 @@
 package neatnetwork
 
 import (
-	"fmt"
-	"math/rand/v2"
-	"monopoly/pkg/config"
-	"monopoly/pkg/monopoly"
-	"slices"
+	"context"
+	"monopoly/pkg/bots"
 	"sync"
 
 	"github.com/yaricom/goNEAT/v4/neat/genetics"
 )
 
-type SimplePlayerBot struct {
-	score int
+// BotPlayer lets a registered bot play in the evaluator groups next to the organisms.
+// It keeps score like the organisms do, but has no fitness of its own.
+type BotPlayer struct {
+	bots.Bot
+	name  string
+	score float64
 	mutex sync.Mutex
-	wins  int
-	draws int
+	wins  float64
+	draws float64
 }
 
-func (bot *SimplePlayerBot) GetName() string {
-	return "SimpleBot"
+// NewBotPlayer creates the bot from its spec, see bots.New.
+func NewBotPlayer(spec string) (*BotPlayer, error) {
+	bot, err := bots.New(spec)
+	if err != nil {
+		return nil, err
+	}
+	return &BotPlayer{Bot: bot, name: bots.Name(spec)}, nil
+}
+
+// Seed seeds the bot if it makes random decisions, see bots.Seeder.
+func (bot *BotPlayer) Seed(seed int64) {
+	if seeder, ok := bot.Bot.(bots.Seeder); ok {
+		seeder.Seed(seed)
+	}
+}
+
+// SetContext passes the context of the game to the bot if it plays games of its own, see bots.ContextSetter.
+func (bot *BotPlayer) SetContext(ctx context.Context) {
+	if setter, ok := bot.Bot.(bots.ContextSetter); ok {
+		setter.SetContext(ctx)
+	}
+}
+
+func (bot *BotPlayer) GetName() string {
+	return bot.name
 }
-func (bot *SimplePlayerBot) GetId() int {
+func (bot *BotPlayer) GetId() int {
 	return -1
 }
-func (bot *SimplePlayerBot) GetScore() int {
+func (bot *BotPlayer) GetScore() float64 {
 	bot.mutex.Lock()
 	defer bot.mutex.Unlock()
 	return bot.score
 }
-func (bot *SimplePlayerBot) GetOrganism() *genetics.Organism {
+func (bot *BotPlayer) GetOrganism() *genetics.Organism {
 	return nil
 }
-func (bot *SimplePlayerBot) AddScore(points int) {
+func (bot *BotPlayer) AddScore(points float64) {
 	bot.mutex.Lock()
 	bot.score += points
 	bot.mutex.Unlock()
 }
 
-func (bot *SimplePlayerBot) AddWin() {
+func (bot *BotPlayer) AddWin(share float64) {
 	bot.mutex.Lock()
-	bot.wins++
+	bot.wins += share
 	bot.mutex.Unlock()
 }
 
-func (bot *SimplePlayerBot) AddDraw() {
+func (bot *BotPlayer) AddDraw(share float64) {
 	bot.mutex.Lock()
-	bot.draws++
+	bot.draws += share
 	bot.mutex.Unlock()
 }
 
-func (bot *SimplePlayerBot) GetWins() int {
+func (bot *BotPlayer) GetWins() float64 {
 	bot.mutex.Lock()
 	defer bot.mutex.Unlock()
 	return bot.wins
 }
 
-func (bot *SimplePlayerBot) GetDraws() int {
+func (bot *BotPlayer) GetDraws() float64 {
 	bot.mutex.Lock()
 	defer bot.mutex.Unlock()
 	return bot.draws
 }
-
-func (bot *SimplePlayerBot) GetStdAction(player int, state monopoly.GameState, availableActions monopoly.FullActionList) monopoly.ActionDetails {
-	retValue := monopoly.ActionDetails{}
-	if state.StdActionsUsed >= config.MAX_STD_ACTIONS {
-		retValue.Action = monopoly.NOACTION
-		return retValue
-	}
-	playerCash := state.Players[player].Money
-	need_money := playerCash < 300
-
-	// Buying houses
-	if len(availableActions.BuyHouseList) > 0 {
-		randIdx := rand.IntN(len(availableActions.BuyHouseList))
-		propertyId := availableActions.BuyHouseList[randIdx]
-		property := state.Properties[propertyId]
-		if playerCash-property.HousePrice >= 200 {
-			retValue.Action = monopoly.BUYHOUSE
-			retValue.PropertyId = propertyId
-			return retValue
-		} else {
-			need_money = true
-		}
-	}
-
-	// Unmortgaging properties in full sets
-	fullSetProperties := findPropertiesInFullSets(state, player)
-	for _, propertyId := range fullSetProperties {
-		if slices.Contains(availableActions.BuyOutList, propertyId) {
-			propertyBuyOut := int(float64(state.Properties[propertyId].Price) * 1.1)
-			if playerCash-propertyBuyOut >= 200 {
-				retValue.Action = monopoly.BUYOUT
-				retValue.PropertyId = propertyId
-				return retValue
-			} else {
-				need_money = true
-			}
-		}
-	}
-
-	// Buying key properties
-	if state.BuyOfferTries < config.MAX_OFFER_TRIES {
-		keyProperties := findKeyProperties(state, player)
-		for _, propertyId := range keyProperties {
-			if slices.Contains(availableActions.BuyPropertyList, propertyId) {
-				price := state.Properties[propertyId].Price / 2
-				if playerCash-price >= 200 {
-					retValue.Action = monopoly.BUYOFFER
-					retValue.PropertyId = propertyId
-					retValue.Price = price
-					if retValue.Price > 1000 {
-						fmt.Println("PRICE HIGH !!!!!!!!!!!!!!!!")
-						fmt.Printf("PropertyID: %d, Name: %s\n", propertyId, state.Properties[propertyId].Name)
-						fmt.Printf("Offer: %d\n", retValue.Price)
-						fmt.Printf("Property price: %d\n", state.Properties[propertyId].Price)
-						fmt.Println("!!!!!!!!!!!!!!!!!!!!!!!!!!!!")
-						panic("High price offer")
-					}
-					return retValue
-				} else {
-					need_money = true
-				}
-			}
-		}
-	}
-
-	unwantedProperties := findUnwantedProperties(state, player)
-	if need_money && len(unwantedProperties) > 0 {
-		randIdx := rand.IntN(len(unwantedProperties))
-		propertyId := unwantedProperties[randIdx]
-
-		// Selling properties
-		if state.SellOfferTries < config.MAX_OFFER_TRIES && slices.Contains(availableActions.SellPropertyList, propertyId) {
-			retValue.Action = monopoly.SELLOFFER
-			retValue.PropertyId = propertyId
-			property := state.Properties[propertyId]
-			retValue.Price = int(float64(property.Price) * 1.5)
-			retValue.Players = []int{0, 1, 2, 3}
-			if retValue.Price > 1000 {
-				fmt.Println("PRICE HIGH !!!!!!!!!!!!!!!!")
-				fmt.Printf("PropertyID: %d, Name: %s\n", propertyId, state.Properties[propertyId].Name)
-				fmt.Printf("Offer: %d\n", retValue.Price)
-				fmt.Printf("Property price: %d\n", state.Properties[propertyId].Price)
-				fmt.Println("!!!!!!!!!!!!!!!!!!!!!!!!!!!!")
-				panic("High price offer")
-			}
-			return retValue
-		}
-
-		// Mortgaging properties
-		if slices.Contains(availableActions.MortgageList, propertyId) {
-			retValue.Action = monopoly.MORTGAGE
-			retValue.PropertyId = propertyId
-			return retValue
-		}
-	}
-
-	// Unmortgaging rest of properties
-	for _, propertyId := range availableActions.BuyOutList {
-		buyOut := int(float64(state.Properties[propertyId].Price) * 1.1)
-		if playerCash-buyOut >= 200 {
-			retValue.Action = monopoly.BUYOUT
-			retValue.PropertyId = propertyId
-			return retValue
-		}
-	}
-
-	// Trying to buy properties for free
-	if state.BuyOfferTries < config.MAX_OFFER_TRIES && len(availableActions.BuyPropertyList) > 0 {
-		randIdx := rand.IntN(len(availableActions.BuyPropertyList))
-		propertyId := availableActions.BuyPropertyList[randIdx]
-		retValue.Action = monopoly.BUYOFFER
-		retValue.PropertyId = propertyId
-		retValue.Price = 0
-		return retValue
-	}
-
-	retValue.Action = monopoly.NOACTION
-	return retValue
-}
-
-func (bot *SimplePlayerBot) GetJailAction(player int, state monopoly.GameState, available []monopoly.JailAction) monopoly.JailAction {
-	if !slices.Contains(available, monopoly.ROLL_DICE) {
-		if slices.Contains(available, monopoly.CARD) {
-			return monopoly.CARD
-		}
-		return monopoly.BAIL
-	}
-	if state.Round > 20 {
-		return monopoly.ROLL_DICE
-	}
-	if slices.Contains(available, monopoly.CARD) {
-		return monopoly.CARD
-	}
-	return monopoly.BAIL
-}
-func (bot *SimplePlayerBot) BuyDecision(player int, state monopoly.GameState, propertyId int) bool {
-	if state.Players[player].Money-state.Properties[propertyId].Price >= 200 {
-		return true
-	}
-	return false
-}
-func (bot *SimplePlayerBot) BuyFromPlayerDecision(player int, state monopoly.GameState, propertyId int, price int) bool {
-	if state.Players[player].Money-price < 200 {
-		return false
-	}
-	if price < state.Properties[propertyId].Price {
-		return true
-	}
-	isKeyProperty := slices.Contains(findKeyProperties(state, player), propertyId)
-	if isKeyProperty && price <= 2*state.Properties[propertyId].Price {
-		return true
-	}
-	return false
-}
-
-func (bot *SimplePlayerBot) SellToPlayerDecision(player int, state monopoly.GameState, propertyId int, price int) bool {
-	fullSetProperties := findPropertiesInFullSets(state, player)
-	if slices.Contains(fullSetProperties, propertyId) {
-		return false
-	}
-	unwantedProperties := findUnwantedProperties(state, player)
-	if slices.Contains(unwantedProperties, propertyId) && price > state.Properties[propertyId].Price {
-		return true
-	}
-	if price > 2*state.Properties[propertyId].Price {
-		return true
-	}
-	return false
-}
-
-func (bot *SimplePlayerBot) BiddingDecision(player int, state monopoly.GameState, propertyId int, currentPrice int, currentWinner int) int {
-	if state.Players[player].Money-currentPrice < 200 {
-		return 0
-	}
-	isKeyProperty := slices.Contains(findKeyProperties(state, player), propertyId)
-	if isKeyProperty {
-		return currentPrice + 20
-	}
-	if currentPrice < state.Properties[propertyId].Price {
-		return currentPrice + 10
-	}
-	return 0
-}
-
-func findKeyProperties(state monopoly.GameState, playerId int) []int {
-	_, missing := getSetMaps(state, playerId)
-	keyProperties := []int{}
-	for _, properties := range missing {
-		if len(properties) == 1 {
-			keyProperties = append(keyProperties, properties[0])
-		}
-	}
-	return keyProperties
-}
-
-func findUnwantedProperties(state monopoly.GameState, playerId int) []int {
-	have, _ := getSetMaps(state, playerId)
-	unwanted := []int{}
-	for set, properties := range have {
-		if len(properties) == 1 && set != "DarkBlue" && set != "Brown" {
-			unwanted = append(unwanted, properties[0])
-		}
-	}
-	return unwanted
-}
-
-func findPropertiesInFullSets(state monopoly.GameState, playerId int) []int {
-	have, missing := getSetMaps(state, playerId)
-	fullSetProperties := []int{}
-	for set, properties := range missing {
-		if len(properties) == 0 {
-			fullSetProperties = append(fullSetProperties, have[set]...)
-		}
-	}
-	return fullSetProperties
-}
-
-func getSetMaps(state monopoly.GameState, playerId int) (have map[string][]int, missing map[string][]int) {
-	have = map[string][]int{
-		"Brown":     {},
-		"LightBlue": {},
-		"Pink":      {},
-		"Orange":    {},
-		"Red":       {},
-		"Yellow":    {},
-		"Green":     {},
-		"DarkBlue":  {},
-	}
-	missing = map[string][]int{
-		"Brown":     {},
-		"LightBlue": {},
-		"Pink":      {},
-		"Orange":    {},
-		"Red":       {},
-		"Yellow":    {},
-		"Green":     {},
-		"DarkBlue":  {},
-	}
-	for idx, property := range state.Properties {
-		if property.Set == monopoly.RAILROAD || property.Set == monopoly.UTILITY {
-			continue
-		}
-		if property.Owner == nil || property.Owner.ID != playerId {
-			missing[property.Set] = append(missing[property.Set], idx)
-		} else {
-			have[property.Set] = append(have[property.Set], idx)
-		}
-	}
-	return
-}
@@ -0,0 +1,250 @@
+package neatnetwork
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/yaricom/goNEAT/v4/neat"
+	"github.com/yaricom/goNEAT/v4/neat/genetics"
+)
+
+// A checkpoint holds the state of the training between two epochs, so that it can be resumed after a crash
+// or Ctrl+C. It is saved to the checkpoint directory of the output directory:
+//   - population: the genomes of the population about to be evaluated,
+//   - champion: the last champion of the evaluator,
+//   - state.json: the epoch counter, the species and the evaluator state.
+//
+// The random numbers of an epoch are derived from the seed of the experiment and the epoch, see deriveSeed,
+// so the epoch counter is the whole state of the random sources, including the global one of the reproduction
+// when it can be seeded, see seedReproduction.
+const (
+	CHECKPOINT_DIR       = "checkpoint"
+	checkpointPopulation = "population"
+	checkpointChampion   = "champion"
+	checkpointStateFile  = "state.json"
+	checkpointTmpSuffix  = ".tmp"
+)
+
+type checkpointState struct {
+	Layout     string `json:"layout"` // layout version of the population genomes
+	Trial      int    `json:"trial"`
+	Generation int    `json:"generation"` // the next generation to evaluate
+
+	LastSpecies              int     `json:"last_species"`
+	WinnerGen                int     `json:"winner_gen"`
+	HighestFitness           float64 `json:"highest_fitness"`
+	EpochsHighestLastChanged int     `json:"epochs_highest_last_changed"`
+
+	Species []checkpointSpecies `json:"species"`
+
+	LastChampFitness float64 `json:"last_champion_fitness"`
+}
+
+type checkpointSpecies struct {
+	Id                   int     `json:"id"`
+	Age                  int     `json:"age"`
+	MaxFitnessEver       float64 `json:"max_fitness_ever"`
+	AgeOfLastImprovement int     `json:"age_of_last_improvement"`
+	IsNovel              bool    `json:"is_novel"`
+	Organisms            []int   `json:"organisms"` // genome ids of the organisms
+}
+
+// checkpoint is a loaded checkpoint to resume the training from.
+type checkpoint struct {
+	state        checkpointState
+	population   *genetics.Population
+	lastChampion *genetics.Organism
+}
+
+// saveCheckpoint saves the population before the evaluation of the generation. The previous checkpoint is
+// replaced only when the new one is complete.
+func (e *MonopolyEvaluator) saveCheckpoint(pop *genetics.Population, trial int, generation int) error {
+	dir := filepath.Join(e.config.OutputDir, CHECKPOINT_DIR)
+	tmpDir := dir + checkpointTmpSuffix
+	if len(pop.Organisms) == 0 {
+		return errors.New("empty population")
+	}
+	version, err := layout.GenomeVersion(pop.Organisms[0].Genotype)
+	if err != nil {
+		return err
+	}
+	if err := os.RemoveAll(tmpDir); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(tmpDir, os.ModePerm); err != nil {
+		return err
+	}
+	if err := writePopulation(filepath.Join(tmpDir, checkpointPopulation), pop, version); err != nil {
+		return err
+	}
+	if e.lastChampion != nil {
+		if err := WriteGenome(filepath.Join(tmpDir, checkpointChampion), e.lastChampion.Genotype); err != nil {
+			return err
+		}
+	}
+
+	state := checkpointState{
+		Layout:                   version,
+		Trial:                    trial,
+		Generation:               generation,
+		LastSpecies:              pop.LastSpecies,
+		WinnerGen:                pop.WinnerGen,
+		HighestFitness:           pop.HighestFitness,
+		EpochsHighestLastChanged: pop.EpochsHighestLastChanged,
+		LastChampFitness:         e.lastChampFitness,
+	}
+	for _, species := range pop.Species {
+		s := checkpointSpecies{
+			Id:                   species.Id,
+			Age:                  species.Age,
+			MaxFitnessEver:       species.MaxFitnessEver,
+			AgeOfLastImprovement: species.AgeOfLastImprovement,
+			IsNovel:              species.IsNovel,
+		}
+		for _, org := range species.Organisms {
+			s.Organisms = append(s.Organisms, org.Genotype.Id)
+		}
+		state.Species = append(state.Species, s)
+	}
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, checkpointStateFile), data, 0644); err != nil {
+		return err
+	}
+
+	if err := os.RemoveAll(dir); err != nil {
+		return err
+	}
+	return os.Rename(tmpDir, dir)
+}
+
+// writePopulation writes the genomes in the plain format of genetics.ReadPopulation. The reader glues the line
+// following "genomestart" to it, where it is ignored, so every genome starts with its layout stamp.
+func writePopulation(path string, pop *genetics.Population, version string) error {
+	var buf bytes.Buffer
+	for _, org := range pop.Organisms {
+		var genome bytes.Buffer
+		if err := org.Genotype.Write(&genome); err != nil {
+			return err
+		}
+		start, rest, _ := bytes.Cut(genome.Bytes(), []byte("\n"))
+		fmt.Fprintf(&buf, "%s\n%s %s\n%s", start, LAYOUT_STAMP, version, rest)
+	}
+	return os.WriteFile(path, buf.Bytes(), 0644)
+}
+
+// loadCheckpoint reads the checkpoint of the output directory and restores the species of the population.
+func loadCheckpoint(outputDir string, options *neat.Options) (*checkpoint, error) {
+	dir := filepath.Join(outputDir, CHECKPOINT_DIR)
+	data, err := os.ReadFile(filepath.Join(dir, checkpointStateFile))
+	if err != nil {
+		return nil, fmt.Errorf("no checkpoint to resume from: %w", err)
+	}
+	c := &checkpoint{}
+	if err := json.Unmarshal(data, &c.state); err != nil {
+		return nil, fmt.Errorf("failed to read checkpoint state: %w", err)
+	}
+
+	file, err := os.Open(filepath.Join(dir, checkpointPopulation))
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	c.population, err = genetics.ReadPopulation(file, options)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read checkpoint population: %w", err)
+	}
+	if len(c.population.Organisms) == 0 {
+		return nil, errors.New("empty checkpoint population")
+	}
+	version, err := layout.GenomeVersion(c.population.Organisms[0].Genotype)
+	if err != nil {
+		return nil, err
+	}
+	if version != c.state.Layout {
+		return nil, fmt.Errorf("checkpoint population has layout %s, the network expects %s", c.state.Layout, version)
+	}
+	if err := c.restoreSpecies(); err != nil {
+		return nil, err
+	}
+
+	championPath := filepath.Join(dir, checkpointChampion)
+	if _, err := os.Stat(championPath); err == nil {
+		genome, err := ReadGenome(championPath)
+		if err != nil {
+			return nil, err
+		}
+		c.lastChampion, err = genetics.NewOrganism(c.state.LastChampFitness, genome, c.state.Generation)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// restoreSpecies replaces the species the population was sorted into when it was read with the saved ones,
+// which carry their age and the fitness history used by the reproduction.
+func (c *checkpoint) restoreSpecies() error {
+	organisms := map[int]*genetics.Organism{}
+	for _, org := range c.population.Organisms {
+		organisms[org.Genotype.Id] = org
+	}
+	var allSpecies []*genetics.Species
+	assigned := 0
+	for _, s := range c.state.Species {
+		species := genetics.NewSpeciesNovel(s.Id, s.IsNovel)
+		species.Age = s.Age
+		species.MaxFitnessEver = s.MaxFitnessEver
+		species.AgeOfLastImprovement = s.AgeOfLastImprovement
+		for _, id := range s.Organisms {
+			org, ok := organisms[id]
+			if !ok {
+				return fmt.Errorf("organism %d of species %d is missing in the checkpoint population", id, s.Id)
+			}
+			org.Species = species
+			species.Organisms = append(species.Organisms, org)
+			assigned++
+		}
+		allSpecies = append(allSpecies, species)
+	}
+	if assigned != len(c.population.Organisms) {
+		return fmt.Errorf("%d of %d checkpoint organisms belong to a species", assigned, len(c.population.Organisms))
+	}
+	c.population.Species = allSpecies
+	c.population.LastSpecies = c.state.LastSpecies
+	c.population.WinnerGen = c.state.WinnerGen
+	c.population.HighestFitness = c.state.HighestFitness
+	c.population.EpochsHighestLastChanged = c.state.EpochsHighestLastChanged
+	restoreCounters(c.population)
+	return nil
+}
+
+// restoreCounters makes the population number new nodes and genes after the largest ones of its genomes.
+// The read population counts from the last node and gene of every genome, which are not always the largest.
+// goNEAT keeps the counters unexported and only lets them grow by one, so they are advanced one number
+// at a time, which only covers the gap between the last and the largest numbers.
+func restoreCounters(pop *genetics.Population) {
+	maxNodeId, maxInnovation := 0, int64(0)
+	for _, org := range pop.Organisms {
+		for _, node := range org.Genotype.Nodes {
+			maxNodeId = max(maxNodeId, node.Id)
+		}
+		for _, gene := range org.Genotype.Genes {
+			maxInnovation = max(maxInnovation, gene.InnovationNum)
+		}
+	}
+	nodeId := pop.NextNodeId()
+	for nodeId < maxNodeId {
+		nodeId = pop.NextNodeId()
+	}
+	innovation := pop.NextInnovationNumber()
+	for innovation < maxInnovation {
+		innovation = pop.NextInnovationNumber()
+	}
+}
@@ -0,0 +1,100 @@
+package neatnetwork
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yaricom/goNEAT/v4/neat"
+	"github.com/yaricom/goNEAT/v4/neat/genetics"
+)
+
+func TestCheckpoint(t *testing.T) {
+	options, err := neat.ReadNeatOptionsFromFile("../../neat_options.yaml")
+	if !assert.NoError(t, err) {
+		return
+	}
+	options.PopSize = 10
+	genome, err := ReadGenome("../../genomes/base_genome.yaml")
+	if !assert.NoError(t, err) {
+		return
+	}
+	pop, err := genetics.NewPopulation(genome, options)
+	if !assert.NoError(t, err) {
+		return
+	}
+	// split the population into two species with a history
+	second := genetics.NewSpecies(2)
+	second.Age = 3
+	second.MaxFitnessEver = 40
+	for _, org := range pop.Species[0].Organisms[6:] {
+		org.Species = second
+		second.Organisms = append(second.Organisms, org)
+	}
+	pop.Species[0].Organisms = pop.Species[0].Organisms[:6]
+	pop.Species[0].Age = 7
+	pop.Species = append(pop.Species, second)
+	pop.LastSpecies = 2
+	pop.HighestFitness = 55
+
+	config := DefaultTrainingConfig()
+	config.OutputDir = t.TempDir()
+	evaluator := NewMonopolyEvaluator(config, nil)
+	evaluator.lastChampion = pop.Organisms[3]
+	evaluator.lastChampFitness = 55
+	assert.NoError(t, evaluator.saveCheckpoint(pop, 0, 5))
+	assert.NoError(t, evaluator.saveCheckpoint(pop, 0, 6), "A checkpoint should replace the previous one")
+
+	c, err := loadCheckpoint(config.OutputDir, options)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, 6, c.state.Generation)
+	assert.Equal(t, 55.0, c.state.LastChampFitness)
+	assert.Equal(t, 2, c.population.LastSpecies)
+	assert.Equal(t, 55.0, c.population.HighestFitness)
+	if assert.Len(t, c.population.Species, 2) {
+		assert.Equal(t, 7, c.population.Species[0].Age)
+		assert.Len(t, c.population.Species[0].Organisms, 6)
+		assert.Equal(t, 40.0, c.population.Species[1].MaxFitnessEver)
+		assert.Same(t, c.population.Species[1], c.population.Species[1].Organisms[0].Species)
+	}
+	if assert.Len(t, c.population.Organisms, len(pop.Organisms)) {
+		for i, org := range pop.Organisms {
+			assert.Equal(t, genomeText(t, org.Genotype), genomeText(t, c.population.Organisms[i].Genotype))
+		}
+	}
+	if assert.NotNil(t, c.lastChampion) {
+		assert.Equal(t, genomeText(t, pop.Organisms[3].Genotype), genomeText(t, c.lastChampion.Genotype))
+	}
+	_, err = c.population.Verify()
+	assert.NoError(t, err)
+	maxNodeId, maxInnovation := 0, int64(0)
+	for _, org := range c.population.Organisms {
+		for _, node := range org.Genotype.Nodes {
+			maxNodeId = max(maxNodeId, node.Id)
+		}
+		for _, gene := range org.Genotype.Genes {
+			maxInnovation = max(maxInnovation, gene.InnovationNum)
+		}
+	}
+	assert.Greater(t, c.population.NextNodeId(), maxNodeId, "New nodes should be numbered after the ones of the population")
+	assert.Greater(t, c.population.NextInnovationNumber(), maxInnovation, "New genes should be numbered after the ones of the population")
+
+	_, err = loadCheckpoint(t.TempDir(), options)
+	assert.Error(t, err)
+	state := filepath.Join(config.OutputDir, CHECKPOINT_DIR, checkpointStateFile)
+	data, err := os.ReadFile(state)
+	assert.NoError(t, err)
+	assert.NoError(t, os.WriteFile(state, bytes.Replace(data, []byte(c.state.Layout), []byte("000000000000"), 1), 0644))
+	_, err = loadCheckpoint(config.OutputDir, options)
+	assert.ErrorContains(t, err, "layout")
+}
+
+func genomeText(t *testing.T, genome *genetics.Genome) string {
+	var buf bytes.Buffer
+	assert.NoError(t, genome.Write(&buf))
+	return buf.String()
+}
@@ -2,14 +2,17 @@ package neatnetwork
 
 import (
 	"context"
+	"encoding/binary"
 	"fmt"
+	"hash/fnv"
+	"math"
 	"math/rand"
 	"os"
 	"path/filepath"
 	"sync"
 	"time"
 
-	cfg "monopoly/pkg/config"
+	"monopoly/pkg/bots"
 	"monopoly/pkg/monopoly"
 
 	"github.com/yaricom/goNEAT/v4/experiment"
@@ -26,25 +29,39 @@ type RoundDetails struct {
 }
 
 type MonopolyEvaluator struct {
-	outputDir        string
-	groupSize        int
+	config           TrainingConfig
+	fitness          *Fitness
 	lastChampion     *genetics.Organism
 	lastChampFitness float64
+	hallOfFame       *HallOfFame // nil when no seats are taken by past champions
 	rng              *rand.Rand
+	stats            *gameStats // games of the evaluated epoch
+	metrics          *metricsLog
 }
 
-func NewMonopolyEvaluator(outputDir string, groupSize int, rng *rand.Rand) *MonopolyEvaluator {
-	if rng == nil {
-		rng = rand.New(rand.NewSource(time.Now().UnixNano()))
-	}
+func NewMonopolyEvaluator(config TrainingConfig, fitness *Fitness) *MonopolyEvaluator {
 	return &MonopolyEvaluator{
-		outputDir: outputDir,
-		groupSize: groupSize,
-		rng:       rng,
+		config:  config,
+		fitness: fitness,
+		rng:     rand.New(rand.NewSource(config.Seed)),
+		stats:   &gameStats{},
+		metrics: newMetricsLog(config.OutputDir),
+	}
+}
+
+// deriveSeed mixes the ids into the seed, so that every part of the experiment gets its own random numbers
+// which do not depend on the parts played before, e.g. a resumed epoch is played like the interrupted one.
+func deriveSeed(seed int64, ids ...int) int64 {
+	hash := fnv.New64a()
+	binary.Write(hash, binary.LittleEndian, seed)
+	for _, id := range ids {
+		binary.Write(hash, binary.LittleEndian, int64(id))
 	}
+	return int64(hash.Sum64())
 }
 
 type GroupDetails struct {
+	Trial   int
 	Epoch   int
 	Round   int
 	GroupID int
@@ -58,38 +75,40 @@ func (e *MonopolyEvaluator) GenerationEvaluate(ctx context.Context, pop *genetic
 		return fmt.Errorf("failed to get options from context")
 	}
 
+	startTime := time.Now()
+	e.rng = rand.New(rand.NewSource(deriveSeed(e.config.Seed, epoch.TrialId, epoch.Id)))
+	e.stats = &gameStats{}
+
 	// create players from population
 	players, err := e.createPlayersFromPopulation(pop)
 	if err != nil {
 		return fmt.Errorf("failed to create players from population: %v", err)
 	}
 
-	// start workers
-	jobsCh := make(chan GroupDetails, 100)
-	var wg sync.WaitGroup
-	for i := range cfg.MAX_THREADS {
-		wg.Add(1)
-		go startWorker(ctx, i, jobsCh, &wg, e.outputDir)
-	}
-
-	// starting rounds
-	for roundID := range cfg.GAMES_PER_EPOCH {
+	// the rounds are played one after another, so that every organism plays one game at a time
+	// and adds its scores in the same order in every run of the experiment
+	for roundID := range e.config.Evaluation.GamesPerEpoch {
 		// prepare groups
-		var groups [][]MonopolyPlayer
-		if cfg.INCLUDE_HEURISTIC_BOT {
-			groups = e.prepareGroupsWithHeuristicBot(players, e.groupSize)
-		} else {
-			groups = e.prepareGroups(players, e.groupSize)
+		groups, err := e.prepareGroups(players)
+		if err != nil {
+			return fmt.Errorf("failed to create opponents: %v", err)
 		}
 
-		if roundID == 0 && (epoch.Id == options.NumGenerations-1 || (epoch.Id+1)%cfg.PRINT_EVERY == 0) {
-			dumpGroupAssignments(e.outputDir, epoch.Id, roundID, groups)
+		if roundID == 0 && (epoch.Id == options.NumGenerations-1 || (epoch.Id+1)%e.config.Logging.PrintEvery == 0) {
+			dumpGroupAssignments(e.config.OutputDir, epoch.Id, roundID, groups)
 		}
 
-		// create job for every group
+		// start workers and create job for every group
+		jobsCh := make(chan GroupDetails, len(groups))
+		var wg sync.WaitGroup
+		for i := range min(e.config.Evaluation.Threads, len(groups)) {
+			wg.Add(1)
+			go e.startWorker(ctx, i, jobsCh, &wg)
+		}
 		for groupID, group := range groups {
 
 			gd := GroupDetails{
+				Trial:   epoch.TrialId,
 				Epoch:   epoch.Id,
 				Round:   roundID,
 				GroupID: groupID,
@@ -97,116 +116,172 @@ func (e *MonopolyEvaluator) GenerationEvaluate(ctx context.Context, pop *genetic
 			}
 			jobsCh <- gd
 		}
+		close(jobsCh)
+		wg.Wait()
+		// a cancelled epoch is not recorded, its metrics and champion would come from the games played so far
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 	}
-	close(jobsCh)
-	wg.Wait()
 
 	bestPlayer := e.calculateFitness(players)
 	bestOrg := bestPlayer.GetOrganism()
 	epoch.FillPopulationStatistics(pop)
 	numberOfSpecies := len(pop.Species)
-	// e.lastChampion = best.GetOrganism()
-	// e.lastChampFitness = e.lastChampion.Fitness
+	if err := e.metrics.add(epochMetrics(epoch.TrialId, epoch.Id, pop, bestOrg, e.stats, time.Since(startTime))); err != nil {
+		neat.ErrorLog(fmt.Sprintf("Failed to save metrics, reason: %s\n", err))
+		return err
+	}
+	e.lastChampion = bestOrg
+	e.lastChampFitness = bestOrg.Fitness
+	if e.hallOfFame != nil {
+		if err := e.hallOfFame.Add(epoch.Id, bestOrg.Genotype); err != nil {
+			neat.ErrorLog(fmt.Sprintf("Failed to add champion to hall of fame, reason: %s\n", err))
+			return err
+		}
+	}
 
 	// log info
 	neat.InfoLog(fmt.Sprintf("Species count: %d\n", numberOfSpecies))
-	neat.InfoLog(fmt.Sprintf("Champion of epoch %d is organism %d\n with fitness: %f (wins: %d, draws: %d)", epoch.Id, bestOrg.Genotype.Id, bestOrg.Fitness, bestPlayer.GetWins(), bestPlayer.GetDraws()))
+	neat.InfoLog(fmt.Sprintf("Champion of epoch %d is organism %d\n with fitness: %f (wins: %g, draws: %g)", epoch.Id, bestOrg.Genotype.Id, bestOrg.Fitness, bestPlayer.GetWins(), bestPlayer.GetDraws()))
 	neat.InfoLog(fmt.Sprintf("Number of nodes: %d, number of connections: %d\n", len(bestOrg.Genotype.Nodes), len(bestOrg.Genotype.Genes)))
 
 	// dump population
-	if (epoch.Id+1)%cfg.PRINT_EVERY == 0 || epoch.Id == options.NumGenerations-1 {
-		if _, err := utils.WritePopulationPlain(e.outputDir, pop, epoch); err != nil {
+	if (epoch.Id+1)%e.config.Logging.PrintEvery == 0 || epoch.Id == options.NumGenerations-1 {
+		popPath, err := utils.WritePopulationPlain(e.config.OutputDir, pop, epoch)
+		if err == nil {
+			err = StampGenomeFile(popPath, bestOrg.Genotype)
+		}
+		if err != nil {
 			neat.ErrorLog(fmt.Sprintf("Failed to dump population, reason: %s\n", err))
 			return err
 		}
 	} else {
 		// dump only champion
 		genomeFile := fmt.Sprintf("gen_%d_champion", epoch.Id)
-		if _, err := utils.WriteGenomePlain(genomeFile, e.outputDir, bestOrg, epoch); err != nil {
+		orgPath, err := utils.WriteGenomePlain(genomeFile, e.config.OutputDir, bestOrg, epoch)
+		if err == nil {
+			err = StampGenomeFile(orgPath, bestOrg.Genotype)
+		}
+		if err != nil {
 			neat.ErrorLog(fmt.Sprintf("Failed to dump champion organism, reason: %s\n", err))
 			return err
 		}
 	}
 
 	// add line to champions.txt with champion info
-	if err := appendChampionInfo(e.outputDir, bestPlayer, epoch.Id); err != nil {
+	if err := appendChampionInfo(e.config.OutputDir, bestPlayer, epoch.Id); err != nil {
 		neat.ErrorLog(fmt.Sprintf("Failed to append champion info, reason: %s\n", err))
 		return err
 	}
 	return nil
 }
 
-func startWorker(ctx context.Context, id int, jobsCh <-chan GroupDetails, wg *sync.WaitGroup, outputDir string) {
+func (e *MonopolyEvaluator) startWorker(ctx context.Context, id int, jobsCh <-chan GroupDetails, wg *sync.WaitGroup) {
 	defer wg.Done()
 	// neat.InfoLog(fmt.Sprintf("Worker %d started\n", id))
 	for gd := range jobsCh {
 		// neat.InfoLog(fmt.Sprintf("Worker %d processing group %d (round %d)\n", id, gd.GroupID, gd.Round))
-		if err := startGroup(ctx, gd, outputDir); err != nil {
+		if err := e.startGroup(ctx, gd); err != nil {
 			neat.ErrorLog(err.Error())
 			continue
 		}
 	}
 }
 
-func (e *MonopolyEvaluator) prepareGroups(players []MonopolyPlayer, groupSize int) [][]MonopolyPlayer {
+// prepareGroups shuffles the players into groups, the opponent seats of every group are taken by the bots
+// and, once there are any, by the champions of the hall of fame.
+func (e *MonopolyEvaluator) prepareGroups(players []MonopolyPlayer) ([][]MonopolyPlayer, error) {
 	e.rng.Shuffle(len(players), func(i, j int) {
 		players[i], players[j] = players[j], players[i]
 	})
-	var groups [][]MonopolyPlayer
-	for i := 0; i < len(players); i += (e.groupSize) {
-		end := min(i+e.groupSize, len(players))
-		group := make([]MonopolyPlayer, 0, e.groupSize)
-		group = append(group, players[i:end]...)
-		groups = append(groups, group)
+	groupSize := e.config.Evaluation.GroupSize
+	seats := e.config.Opponents.Seats
+	champions := 0
+	if e.hallOfFame != nil && e.hallOfFame.Len() > 0 {
+		champions = e.config.Opponents.HallOfFame.Seats(groupSize)
 	}
-	return groups
-}
-
-func (e *MonopolyEvaluator) prepareGroupsWithHeuristicBot(players []MonopolyPlayer, groupSize int) [][]MonopolyPlayer {
-	e.rng.Shuffle(len(players), func(i, j int) {
-		players[i], players[j] = players[j], players[i]
-	})
+	organisms := groupSize - seats - champions
 	var groups [][]MonopolyPlayer
-	for i := 0; i < len(players); i += (e.groupSize - 1) {
-		end := min(i+e.groupSize-1, len(players))
-		group := make([]MonopolyPlayer, 0, e.groupSize)
+	for i := 0; i < len(players); i += organisms {
+		end := min(i+organisms, len(players))
+		group := make([]MonopolyPlayer, 0, groupSize)
 		group = append(group, players[i:end]...)
-		group = append(group, new(SimplePlayerBot))
+		if seats+champions == 0 {
+			groups = append(groups, group)
+			continue
+		}
+		for range seats {
+			bot, err := NewBotPlayer(e.config.Opponents.Bots[e.rng.Intn(len(e.config.Opponents.Bots))])
+			if err != nil {
+				return nil, err
+			}
+			group = append(group, bot)
+		}
+		for range champions {
+			champion, err := e.hallOfFame.newPlayer(e.rng)
+			if err != nil {
+				return nil, err
+			}
+			group = append(group, champion)
+		}
 		e.rng.Shuffle(len(group), func(i, j int) {
 			group[i], group[j] = group[j], group[i]
 		})
 		groups = append(groups, group)
 	}
-	return groups
+	return groups, nil
 }
 
-func startGroup(ctx context.Context, gd GroupDetails, outputDir string) error {
+// startGroup plays the games of the group, one for every order of its seats, see seatOrders. All of them use
+// the dice of the same seed.
+func (e *MonopolyEvaluator) startGroup(ctx context.Context, gd GroupDetails) error {
 	neat.DebugLog(fmt.Sprintf("Starting group %d (round %d)\n", gd.GroupID, gd.Round))
 	options, ok := neat.FromContext(ctx)
 	if !ok {
 		return fmt.Errorf("Error in group %d (round %d): %s", gd.GroupID, gd.Round, "failed to get options from context")
 	}
-	playerGroup, err := NewNEATPlayerGroup(gd.GroupID, gd.Players)
-	if err != nil {
-		return fmt.Errorf("Error in group %d (round %d): %v", gd.GroupID, gd.Round, err)
-	}
-	playerGroup, err = NewNEATPlayerGroup(gd.GroupID, gd.Players)
-	if err != nil {
-		return fmt.Errorf("Error in group %d (round %d): %v", gd.GroupID, gd.Round, err)
-	}
 	enable_log := false
 	if gd.Epoch == options.NumGenerations-1 {
-		enable_log = gd.Round < 10
-	} else if (gd.Epoch+1)%cfg.PRINT_EVERY == 0 {
+		enable_log = gd.Round < e.config.Logging.LoggedGames
+	} else if (gd.Epoch+1)%e.config.Logging.PrintEvery == 0 {
 		enable_log = gd.Round == 0
 	}
-	logger, err := NewTrainerLogger(fmt.Sprintf("%s/games/epoch%d/round%d/group%d",
-		outputDir, gd.Epoch, gd.Round, gd.GroupID), !enable_log)
-	if err != nil {
-		return fmt.Errorf("Error in group %d (round %d): %v", gd.GroupID, gd.Round, err)
+	// the seeds depend on the ids of the game only, not on the games played before on the thread
+	seed := deriveSeed(e.config.Seed, gd.Trial, gd.Epoch, gd.Round, gd.GroupID)
+	orders := seatOrders(e.config.Evaluation.Seating, len(gd.Players))
+	for i, order := range orders {
+		players := make([]MonopolyPlayer, len(order))
+		for seat, player := range order {
+			players[seat] = gd.Players[player]
+		}
+		playerGroup, err := NewNEATPlayerGroup(gd.GroupID, players, e.fitness)
+		if err != nil {
+			return fmt.Errorf("Error in group %d (round %d): %v", gd.GroupID, gd.Round, err)
+		}
+		playerGroup.weight = 1 / float64(len(orders))
+		logPath := fmt.Sprintf("%s/games/epoch%d/round%d/group%d", e.config.OutputDir, gd.Epoch, gd.Round, gd.GroupID)
+		if len(orders) > 1 {
+			logPath = fmt.Sprintf("%s_seating%d", logPath, i)
+		}
+		logger, err := NewTrainerLogger(logPath, !enable_log)
+		if err != nil {
+			return fmt.Errorf("Error in group %d (round %d): %v", gd.GroupID, gd.Round, err)
+		}
+		// a bot keeps its seed in every seating, like the dice
+		for player, p := range gd.Players {
+			if seeder, ok := p.(bots.Seeder); ok {
+				seeder.Seed(deriveSeed(seed, player))
+			}
+			if setter, ok := p.(bots.ContextSetter); ok {
+				setter.SetContext(ctx)
+			}
+		}
+		game := monopoly.NewGame(ctx, playerGroup, logger, seed)
+		game.SetSettings(e.config.Game)
+		game.Start()
+		e.stats.add(playerGroup)
 	}
-	game := monopoly.NewGame(ctx, playerGroup, logger, 0)
-	game.Start()
 	return nil
 }
 
@@ -253,13 +328,13 @@ func (e *MonopolyEvaluator) createPlayersFromPopulation(pop *genetics.Population
 }
 
 func (e *MonopolyEvaluator) calculateFitness(players []MonopolyPlayer) (best MonopolyPlayer) {
-	highestFitness := 0.0
+	highestFitness := math.Inf(-1)
 	for _, player := range players {
 		org := player.GetOrganism()
 		if org == nil {
 			continue
 		}
-		org.Fitness += float64(player.GetScore()) / cfg.GAMES_PER_EPOCH
+		org.Fitness += player.GetScore() / float64(e.config.Evaluation.GamesPerEpoch)
 		if org.Fitness > highestFitness {
 			highestFitness = org.Fitness
 			best = player
@@ -277,7 +352,7 @@ func appendChampionInfo(outputDir string, champion MonopolyPlayer, epoch int) er
 	defer file.Close()
 	timestamp := time.Now().Format("2006-01-02 15:04:05")
 	org := champion.GetOrganism()
-	line := fmt.Sprintf("%s EPOCH %d, Organism ID: %d, Fitness: %f\n (wins: %d, draws: %d)", timestamp, epoch, org.Genotype.Id, org.Fitness, champion.GetWins(), champion.GetDraws())
+	line := fmt.Sprintf("%s EPOCH %d, Organism ID: %d, Fitness: %f\n (wins: %g, draws: %g)", timestamp, epoch, org.Genotype.Id, org.Fitness, champion.GetWins(), champion.GetDraws())
 	if _, err := file.WriteString(line); err != nil {
 		return fmt.Errorf("failed to write to champions file: %v", err)
 	}
@@ -0,0 +1,81 @@
+package neatnetwork
+
+import (
+	"math"
+	cfg "monopoly/pkg/config"
+	"monopoly/pkg/monopoly"
+)
+
+// Derived features spare the network rediscovering concepts like "I own 2 of 3 oranges" from the raw
+// property inputs. They are only loaded for networks which have their inputs, see TrainingConfig.DerivedFeatures.
+
+var board = monopoly.GetBoard()
+
+// fieldCount is the number of fields on the board
+const fieldCount = cfg.LAST_FIELD_ID + 1
+
+func (s MonopolySensors) LoadFeatures(state monopoly.GameState, playerID int) {
+	owners := monopoly.Owners(state)
+	position := state.Players[playerID].CurrentPosition
+	for set, inputs := range layout.setInputs {
+		properties := board.Sets[set]
+		for _, player := range state.Players {
+			owned := 0
+			for _, propertyId := range properties {
+				if owners[propertyId] == player.ID {
+					owned++
+				}
+			}
+			s[inputs[getNewPlayerId(player.ID, playerID)]] = float64(owned) / float64(len(properties))
+		}
+	}
+	for _, field := range board.Fields {
+		if field.PropertyIndex < 0 {
+			continue
+		}
+		inputs := propertyInputs[field.PropertyIndex]
+		s[inputs["RENT"]] = normalize(monopoly.Rent(state, field.PropertyIndex, owners), 0, cfg.MAX_MONEY, false)
+		s[inputs["DISTANCE"]] = normalize((field.FieldIndex-position+fieldCount)%fieldCount, 0, cfg.LAST_FIELD_ID, false)
+	}
+	s[layout.featureInputs["NEXT_HOUSE_COST"]] = normalize(nextHouseCost(state, playerID), 0, cfg.MAX_MONEY, false)
+	s[layout.featureInputs["RENT_EXPOSURE"]] = normalize(int(math.Round(rentExposure(state, playerID, owners))), 0, cfg.MAX_MONEY, false)
+}
+
+// nextHouseCost returns the price of the cheapest house the player can build, 0 if there is none.
+// It follows the engine: houses are built on complete sets without mortgages.
+func nextHouseCost(state monopoly.GameState, playerID int) int {
+	cost := 0
+	for _, properties := range board.Sets {
+		complete := true
+		for _, propertyId := range properties {
+			property := state.Properties[propertyId]
+			complete = complete && property.CanBuildHouse && !property.IsMortgaged && property.Owner != nil && property.Owner.ID == playerID
+		}
+		if !complete {
+			continue
+		}
+		for _, propertyId := range properties {
+			property := state.Properties[propertyId]
+			if property.Houses < state.Settings.MaxHouses && (cost == 0 || property.HousePrice < cost) {
+				cost = property.HousePrice
+			}
+		}
+	}
+	return cost
+}
+
+// rentExposure is the expected rent and tax the player pays on the next roll of the dice, cards are not followed.
+func rentExposure(state monopoly.GameState, playerID int, owners []int) float64 {
+	position := state.Players[playerID].CurrentPosition
+	exposure := 0.0
+	for sum := 2; sum <= 12; sum++ {
+		chance := float64(6-max(sum-7, 7-sum)) / 36
+		field := board.Fields[(position+sum)%fieldCount]
+		charge := field.Tax
+		if field.PropertyIndex >= 0 && owners[field.PropertyIndex] >= 0 && owners[field.PropertyIndex] != playerID {
+			charge = monopoly.Rent(state, field.PropertyIndex, owners)
+		}
+		exposure += chance * float64(charge)
+	}
+	return exposure
+}
@@ -0,0 +1,64 @@
+package neatnetwork
+
+import (
+	"monopoly/pkg/monopoly"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yaricom/goNEAT/v4/neat/genetics"
+)
+
+func TestLoadFeatures(t *testing.T) {
+	// player 1 owns the whole dark blue set, player 0 one of the oranges
+	state := testState(3, map[int]int{26: 1, 27: 1, 11: 0})
+	state.Players[0].CurrentPosition = 30
+	state.Properties[27].Houses = 1
+	ms := NewMonopolySensors()
+	ms.LoadFeatures(state, 0)
+
+	assert.InDelta(t, 1.0/3, ms[layout.setInputs["Orange"][0]], 0.0001)
+	assert.InDelta(t, 1.0, ms[layout.setInputs["Dark Blue"][1]], 0.0001)
+	assert.InDelta(t, 0.0, ms[layout.setInputs["Dark Blue"][0]], 0.0001)
+
+	assert.InDelta(t, 200.0/2000, ms[propertyInputs[27]["RENT"]], 0.0001, "Rent with one house on a full set")
+	assert.InDelta(t, 70.0/2000, ms[propertyInputs[26]["RENT"]], 0.0001, "Double rent of a full set")
+	assert.InDelta(t, 0.0, ms[propertyInputs[0]["RENT"]], 0.0001)
+	assert.InDelta(t, 9.0/39, ms[propertyInputs[27]["DISTANCE"]], 0.0001)
+	assert.InDelta(t, 11.0/39, ms[propertyInputs[0]["DISTANCE"]], 0.0001, "Distances are counted past GO")
+
+	assert.InDelta(t, 0.0, ms[layout.featureInputs["NEXT_HOUSE_COST"]], 0.0001, "Player 0 has no set to build on")
+	assert.Equal(t, 200, nextHouseCost(state, 1))
+
+	// 7 leads to Dark Blue1, 9 to Dark Blue2 and 8 to the luxury tax
+	expected := (6.0*70 + 4.0*200 + 5.0*100) / 36
+	assert.InDelta(t, expected, rentExposure(state, 0, monopoly.Owners(state)), 0.0001)
+	assert.InDelta(t, 48.0/2000, ms[layout.featureInputs["RENT_EXPOSURE"]], 0.0001)
+}
+
+func TestAddFeatureInputs(t *testing.T) {
+	base, err := LoadNEATPlayer("../../genomes/base_genome.yaml")
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.False(t, base.features)
+
+	genome, err := AddFeatureInputs(base.organism.Genotype)
+	if !assert.NoError(t, err) {
+		return
+	}
+	organism, err := genetics.NewOrganism(0.0, genome, 0)
+	if !assert.NoError(t, err) {
+		return
+	}
+	player, err := NewNEATMonopolyPlayer(organism)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.True(t, player.features)
+	inputs, outputs := countNodes(genome)
+	assert.Equal(t, len(layout.Inputs), inputs)
+	assert.Equal(t, len(layout.Outputs), outputs)
+
+	state := testState(2, map[int]int{1: 1})
+	assert.Equal(t, base.BuyDecision(0, state, 3), player.BuyDecision(0, state, 3), "The added inputs have no links")
+}
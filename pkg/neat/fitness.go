@@ -0,0 +1,363 @@
+package neatnetwork
+
+import (
+	"errors"
+	"fmt"
+	"monopoly/pkg/monopoly"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Fitness scores every game an organism plays, the fitness of the organism is the average score of its games.
+// The score is the weighted sum of the components. Every component is an arithmetic expression over the
+// variables of the finished game, so a new component only needs an entry in the fitness file, see fitness.yaml.
+type Fitness struct {
+	Components []FitnessComponent `yaml:"components"`
+}
+
+type FitnessComponent struct {
+	Name   string  `yaml:"name"`
+	Weight float64 `yaml:"weight"`
+	Expr   string  `yaml:"expr"`
+	eval   expression
+}
+
+// FitnessVariables describes the variables which can be used in the component expressions.
+var FitnessVariables = map[string]string{
+	"won":             "1 if the player won the game, 0 otherwise",
+	"draw":            "1 if the player was alive when the round limit was reached, 0 otherwise",
+	"alive":           "1 if the player did not go bankrupt, 0 otherwise",
+	"placement":       "place of the player: the winner first, then the alive players by net worth, then the bankrupt ones by rounds survived",
+	"players":         "number of players in the game",
+	"rounds":          "rounds played by the player",
+	"money":           "cash of the player at the end of the game",
+	"net_worth":       "money the player could raise by selling all houses and mortgaging all properties, 0 if bankrupt",
+	"net_worth_share": "net worth of the player divided by the net worth of all players",
+	"rent_earned":     "rent charged from the other players",
+	"monopolies":      "complete color sets owned at the end of the game, see monopoly.Monopolies",
+	"max_properties":  "most properties owned at once",
+	"max_houses":      "most houses owned at once",
+	"invalid_moves":   "actions chosen from outside of the available ones",
+}
+
+// ReadFitness reads the fitness components from a YAML file and compiles their expressions.
+func ReadFitness(path string) (*Fitness, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	decoder := yaml.NewDecoder(file)
+	decoder.KnownFields(true)
+	fitness := &Fitness{}
+	if err := decoder.Decode(fitness); err != nil {
+		return nil, fmt.Errorf("failed to read fitness file %s: %w", path, err)
+	}
+	if err := fitness.compile(); err != nil {
+		return nil, fmt.Errorf("invalid fitness file %s: %w", path, err)
+	}
+	return fitness, nil
+}
+
+func (f *Fitness) compile() error {
+	if len(f.Components) == 0 {
+		return errors.New("no fitness components")
+	}
+	names := map[string]bool{}
+	for i := range f.Components {
+		component := &f.Components[i]
+		if component.Name == "" {
+			return fmt.Errorf("component %d has no name", i)
+		}
+		if names[component.Name] {
+			return fmt.Errorf("component %s is defined twice", component.Name)
+		}
+		names[component.Name] = true
+		eval, err := parseExpression(component.Expr)
+		if err != nil {
+			return fmt.Errorf("component %s: %w", component.Name, err)
+		}
+		component.eval = eval
+	}
+	return nil
+}
+
+// Score returns the score of a player for the variables of its game, see GameVariables.
+// NEAT expects a non-negative fitness, a negative sum is scored 0.
+func (f *Fitness) Score(variables map[string]float64) float64 {
+	score := 0.0
+	for _, component := range f.Components {
+		if component.Weight != 0 {
+			score += component.Weight * component.eval(variables)
+		}
+	}
+	return max(score, 0)
+}
+
+// GameVariables returns the fitness variables of every player at the end of the game, indexed like state.Players.
+func GameVariables(f monopoly.FinishOption, winner int, state monopoly.GameState) []map[string]float64 {
+	owners := monopoly.Owners(state)
+	netWorth := make([]float64, len(state.Players))
+	totalNetWorth := 0.0
+	for i, player := range state.Players {
+		if !player.IsBankrupt {
+			netWorth[i] = float64(max(monopoly.LiquidationValue(state, player.ID), 0))
+		}
+		totalNetWorth += netWorth[i]
+	}
+	better := func(a int, b int) bool {
+		pa, pb := state.Players[a], state.Players[b]
+		switch {
+		case f == monopoly.WIN && (a == winner || b == winner):
+			return a == winner
+		case pa.IsBankrupt != pb.IsBankrupt:
+			return pb.IsBankrupt
+		case pa.IsBankrupt:
+			return pa.RoundsPlayed > pb.RoundsPlayed
+		}
+		return netWorth[a] > netWorth[b]
+	}
+
+	variables := make([]map[string]float64, len(state.Players))
+	for i, player := range state.Players {
+		placement := 1
+		for j := range state.Players {
+			if j != i && better(j, i) {
+				placement++
+			}
+		}
+		share := 0.0
+		if totalNetWorth > 0 {
+			share = netWorth[i] / totalNetWorth
+		}
+		variables[i] = map[string]float64{
+			"won":             boolVariable(f == monopoly.WIN && winner == i),
+			"draw":            boolVariable(f == monopoly.ROUND_LIMIT && !player.IsBankrupt),
+			"alive":           boolVariable(!player.IsBankrupt),
+			"placement":       float64(placement),
+			"players":         float64(len(state.Players)),
+			"rounds":          float64(player.RoundsPlayed),
+			"money":           float64(player.Money),
+			"net_worth":       netWorth[i],
+			"net_worth_share": share,
+			"rent_earned":     float64(player.RentEarned),
+			"monopolies":      float64(monopoly.Monopolies(player.ID, owners)),
+			"max_properties":  float64(player.MaxProperties),
+			"max_houses":      float64(player.MaxHouses),
+			"invalid_moves":   float64(player.InvalidMoves),
+		}
+	}
+	return variables
+}
+
+func boolVariable(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// expression is a compiled component expression.
+type expression func(variables map[string]float64) float64
+
+// parseExpression compiles an arithmetic expression over the fitness variables. It supports numbers,
+// + - * /, parentheses and the min and max functions. Division by zero gives 0.
+func parseExpression(src string) (expression, error) {
+	tokens, err := tokenize(src)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, errors.New("empty expression")
+	}
+	p := &expressionParser{tokens: tokens}
+	eval, err := p.sum()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("unexpected %q in %q", p.tokens[p.pos], src)
+	}
+	return eval, nil
+}
+
+func tokenize(src string) ([]string, error) {
+	var tokens []string
+	for i := 0; i < len(src); {
+		c := rune(src[i])
+		start := i
+		switch {
+		case unicode.IsSpace(c):
+			i++
+			continue
+		case strings.ContainsRune("+-*/(),", c):
+			i++
+		case unicode.IsDigit(c) || c == '.':
+			for i < len(src) && (unicode.IsDigit(rune(src[i])) || src[i] == '.') {
+				i++
+			}
+		case unicode.IsLetter(c) || c == '_':
+			for i < len(src) && (unicode.IsLetter(rune(src[i])) || unicode.IsDigit(rune(src[i])) || src[i] == '_') {
+				i++
+			}
+		default:
+			return nil, fmt.Errorf("unexpected %q in %q", c, src)
+		}
+		tokens = append(tokens, src[start:i])
+	}
+	return tokens, nil
+}
+
+type expressionParser struct {
+	tokens []string
+	pos    int
+}
+
+func (p *expressionParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+func (p *expressionParser) expect(token string) error {
+	if p.peek() != token {
+		return fmt.Errorf("expected %q, got %q", token, p.peek())
+	}
+	p.pos++
+	return nil
+}
+
+// sum := product (("+" | "-") product)*
+func (p *expressionParser) sum() (expression, error) {
+	left, err := p.product()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek() == "+" || p.peek() == "-" {
+		op := p.tokens[p.pos]
+		p.pos++
+		right, err := p.product()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		if op == "+" {
+			left = func(v map[string]float64) float64 { return l(v) + right(v) }
+		} else {
+			left = func(v map[string]float64) float64 { return l(v) - right(v) }
+		}
+	}
+	return left, nil
+}
+
+// product := unary (("*" | "/") unary)*
+func (p *expressionParser) product() (expression, error) {
+	left, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek() == "*" || p.peek() == "/" {
+		op := p.tokens[p.pos]
+		p.pos++
+		right, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		if op == "*" {
+			left = func(v map[string]float64) float64 { return l(v) * right(v) }
+		} else {
+			left = func(v map[string]float64) float64 {
+				divisor := right(v)
+				if divisor == 0 {
+					return 0
+				}
+				return l(v) / divisor
+			}
+		}
+	}
+	return left, nil
+}
+
+// unary := "-" unary | primary
+func (p *expressionParser) unary() (expression, error) {
+	if p.peek() != "-" {
+		return p.primary()
+	}
+	p.pos++
+	operand, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	return func(v map[string]float64) float64 { return -operand(v) }, nil
+}
+
+// primary := number | variable | function "(" sum ("," sum)* ")" | "(" sum ")"
+func (p *expressionParser) primary() (expression, error) {
+	token := p.peek()
+	switch {
+	case token == "":
+		return nil, errors.New("unexpected end of expression")
+	case token == "(":
+		p.pos++
+		inner, err := p.sum()
+		if err != nil {
+			return nil, err
+		}
+		return inner, p.expect(")")
+	case unicode.IsDigit(rune(token[0])) || token[0] == '.':
+		p.pos++
+		value, err := strconv.ParseFloat(token, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q", token)
+		}
+		return func(map[string]float64) float64 { return value }, nil
+	case token == "min" || token == "max":
+		p.pos++
+		return p.function(token)
+	case unicode.IsLetter(rune(token[0])) || token[0] == '_':
+		if _, ok := FitnessVariables[token]; !ok {
+			return nil, fmt.Errorf("unknown variable %q", token)
+		}
+		p.pos++
+		return func(v map[string]float64) float64 { return v[token] }, nil
+	}
+	return nil, fmt.Errorf("unexpected %q", token)
+}
+
+func (p *expressionParser) function(name string) (expression, error) {
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	var args []expression
+	for {
+		arg, err := p.sum()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+		if p.peek() != "," {
+			break
+		}
+		p.pos++
+	}
+	if err := p.expect(")"); err != nil {
+		return nil, err
+	}
+	return func(v map[string]float64) float64 {
+		values := make([]float64, len(args))
+		for i, arg := range args {
+			values[i] = arg(v)
+		}
+		if name == "min" {
+			return slices.Min(values)
+		}
+		return slices.Max(values)
+	}, nil
+}
@@ -0,0 +1,141 @@
+package neatnetwork
+
+import (
+	"monopoly/pkg/monopoly"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yaricom/goNEAT/v4/neat/genetics"
+)
+
+func TestParseExpression(t *testing.T) {
+	variables := map[string]float64{"won": 1, "rounds": 30, "placement": 2, "players": 4}
+	tests := []struct {
+		expr     string
+		expected float64
+	}{
+		{"rounds", 30},
+		{"2 + 3 * 4", 14},
+		{"(2 + 3) * 4", 20},
+		{"10 - 4 - 3", 3},
+		{"12 / 4 / 3", 1},
+		{"-rounds + 0.5", -29.5},
+		{"rounds * (1 - won)", 0},
+		{"(players - placement) / (players - 1)", 2.0 / 3},
+		{"min(rounds, 20, 25) + max(placement, 1)", 22},
+		{"rounds / (won - 1)", 0},
+	}
+	for _, test := range tests {
+		eval, err := parseExpression(test.expr)
+		if assert.NoError(t, err, test.expr) {
+			assert.InDelta(t, test.expected, eval(variables), 0.0001, test.expr)
+		}
+	}
+
+	for _, expr := range []string{"", "rounds +", "(rounds", "rounds)", "unknown * 2", "rounds % 2", "min()", "won rounds"} {
+		_, err := parseExpression(expr)
+		assert.Error(t, err, expr)
+	}
+}
+
+func TestGameVariables(t *testing.T) {
+	// player 0 owns the brown set, player 2 went bankrupt in round 20
+	state := testState(3, map[int]int{0: 0, 1: 0})
+	state.Players[0].Money = 500
+	state.Players[0].RentEarned = 40
+	state.Players[2].IsBankrupt = true
+	state.Players[2].InvalidMoves = 1
+	state.Players[2].RoundsPlayed = 20
+
+	variables := GameVariables(monopoly.ROUND_LIMIT, -1, state)
+	assert.Equal(t, 2.0, variables[0]["placement"])
+	assert.Equal(t, 1.0, variables[1]["placement"])
+	assert.Equal(t, 3.0, variables[2]["placement"])
+	assert.Equal(t, 560.0, variables[0]["net_worth"])
+	assert.InDelta(t, 560.0/2060, variables[0]["net_worth_share"], 0.0001)
+	assert.Equal(t, 0.0, variables[2]["net_worth_share"])
+	assert.Equal(t, 1.0, variables[0]["monopolies"])
+	assert.Equal(t, 40.0, variables[0]["rent_earned"])
+	assert.Equal(t, 1.0, variables[2]["invalid_moves"])
+	assert.Equal(t, []float64{1, 1, 0}, []float64{variables[0]["draw"], variables[1]["draw"], variables[2]["draw"]})
+	for _, v := range variables {
+		assert.Len(t, v, len(FitnessVariables))
+	}
+
+	variables = GameVariables(monopoly.WIN, 0, state)
+	assert.Equal(t, 1.0, variables[0]["placement"], "The winner is first whatever its net worth")
+	assert.Equal(t, 1.0, variables[0]["won"])
+	assert.Equal(t, 0.0, variables[1]["won"])
+}
+
+func TestReadFitness(t *testing.T) {
+	fitness, err := ReadFitness("../../fitness.yaml")
+	if !assert.NoError(t, err) {
+		return
+	}
+	// the shipped components keep the scoring of the old constants
+	state := testState(3, map[int]int{0: 0, 1: 0})
+	for _, player := range state.Players {
+		player.RoundsPlayed = 30
+		player.MaxProperties = 2
+	}
+	state.Players[1].MaxHouses = 3
+	state.Players[2].IsBankrupt = true
+	bots := make([]MonopolyPlayer, 3)
+	for i := range bots {
+		bots[i], err = NewBotPlayer("random")
+		if !assert.NoError(t, err) {
+			return
+		}
+	}
+	group, err := NewNEATPlayerGroup(0, bots, fitness)
+	if !assert.NoError(t, err) {
+		return
+	}
+	group.Finish(monopoly.WIN, 0, state)
+	assert.Equal(t, 2000.0, bots[0].GetScore())
+	assert.Equal(t, 30.0+2*3+3*10+50, bots[1].GetScore())
+	assert.Equal(t, 30.0+2*3, bots[2].GetScore())
+	assert.Equal(t, 1.0, bots[0].GetWins())
+
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"unknown_variable": "components:\n  - name: luck\n    weight: 1\n    expr: luck * 2\n",
+		"unknown_field":    "components:\n  - name: luck\n    weigth: 1\n    expr: won\n",
+		"duplicate":        "components:\n  - name: win\n    expr: won\n  - name: win\n    expr: alive\n",
+		"empty":            "components: []\n",
+	} {
+		path := filepath.Join(dir, name+".yaml")
+		assert.NoError(t, os.WriteFile(path, []byte(content), 0644))
+		_, err := ReadFitness(path)
+		assert.Error(t, err, name)
+	}
+}
+
+func TestNegativeFitness(t *testing.T) {
+	fitness := &Fitness{Components: []FitnessComponent{{Name: "invalid_moves", Weight: 1, Expr: "-invalid_moves"}}}
+	if !assert.NoError(t, fitness.compile()) {
+		return
+	}
+	assert.Zero(t, fitness.Score(map[string]float64{"invalid_moves": 3}), "A negative score should be cut to 0")
+
+	genome, err := ReadGenome("../../genomes/base_genome.yaml")
+	if !assert.NoError(t, err) {
+		return
+	}
+	evaluator := NewMonopolyEvaluator(DefaultTrainingConfig(), fitness)
+	var players []MonopolyPlayer
+	for range 3 {
+		player, err := NewNEATMonopolyPlayer(&genetics.Organism{Genotype: genome})
+		if !assert.NoError(t, err) {
+			return
+		}
+		players = append(players, player)
+	}
+	best := evaluator.calculateFitness(players)
+	if assert.NotNil(t, best, "A population without a positive fitness should still have a champion") {
+		assert.NotNil(t, best.GetOrganism())
+	}
+}
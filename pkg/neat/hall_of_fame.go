@@ -0,0 +1,116 @@
+package neatnetwork
+
+import (
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/yaricom/goNEAT/v4/neat/genetics"
+)
+
+// HALL_OF_FAME_DIR is the directory of the output directory the champions of the hall of fame are saved to.
+const HALL_OF_FAME_DIR = "hall_of_fame"
+
+// HallOfFame keeps the champions of past epochs, which take seats in the evaluation groups, so that the
+// population keeps beating the strategies it has already left behind. Every champion is saved as a genome
+// file named after its run and epoch, the hall is read back from them when the training is resumed.
+type HallOfFame struct {
+	dir       string
+	run       int
+	size      int // number of latest champions kept, 0 keeps all
+	champions []hallOfFameEntry
+}
+
+type hallOfFameEntry struct {
+	epoch  int
+	genome *genetics.Genome
+}
+
+// hallOfFamePlayer is a past champion playing in a group. Like the bots it has no fitness of its own.
+type hallOfFamePlayer struct {
+	*NEATMonopolyPlayer
+	epoch int
+}
+
+func (p *hallOfFamePlayer) GetName() string {
+	return fmt.Sprintf("Champion%d", p.epoch)
+}
+
+func (p *hallOfFamePlayer) GetId() int {
+	return -1
+}
+
+func (p *hallOfFamePlayer) GetOrganism() *genetics.Organism {
+	return nil
+}
+
+func newHallOfFame(outputDir string, run int, size int) *HallOfFame {
+	return &HallOfFame{
+		dir:  filepath.Join(outputDir, HALL_OF_FAME_DIR),
+		run:  run,
+		size: size,
+	}
+}
+
+func (h *HallOfFame) fileName(epoch int) string {
+	return filepath.Join(h.dir, fmt.Sprintf("run%d_epoch%d", h.run, epoch))
+}
+
+// load reads the champions of the run saved before the epoch.
+func (h *HallOfFame) load(before int) error {
+	for epoch := range before {
+		path := h.fileName(epoch)
+		if _, err := os.Stat(path); err != nil {
+			continue
+		}
+		genome, err := ReadGenome(path)
+		if err != nil {
+			return fmt.Errorf("failed to read hall of fame: %w", err)
+		}
+		h.champions = append(h.champions, hallOfFameEntry{epoch: epoch, genome: genome})
+	}
+	h.trim()
+	return nil
+}
+
+// Add saves the champion of the epoch. The genome is read back from its file, so the hall does not share it with the population.
+func (h *HallOfFame) Add(epoch int, champion *genetics.Genome) error {
+	if err := os.MkdirAll(h.dir, os.ModePerm); err != nil {
+		return err
+	}
+	path := h.fileName(epoch)
+	if err := WriteGenome(path, champion); err != nil {
+		return err
+	}
+	genome, err := ReadGenome(path)
+	if err != nil {
+		return err
+	}
+	h.champions = slices.DeleteFunc(h.champions, func(entry hallOfFameEntry) bool { return entry.epoch == epoch })
+	h.champions = append(h.champions, hallOfFameEntry{epoch: epoch, genome: genome})
+	h.trim()
+	return nil
+}
+
+func (h *HallOfFame) trim() {
+	if h.size > 0 && len(h.champions) > h.size {
+		h.champions = h.champions[len(h.champions)-h.size:]
+	}
+}
+
+func (h *HallOfFame) Len() int {
+	return len(h.champions)
+}
+
+// newPlayer creates a player of a random champion with its own network, as the champions play in many groups at once.
+// It is not safe for concurrent use, the network is built from the shared genome.
+func (h *HallOfFame) newPlayer(rng *rand.Rand) (MonopolyPlayer, error) {
+	entry := h.champions[rng.Intn(len(h.champions))]
+	player, err := NewNEATMonopolyPlayer(&genetics.Organism{Genotype: entry.genome, Generation: entry.epoch})
+	if err != nil {
+		return nil, err
+	}
+	return &hallOfFamePlayer{NEATMonopolyPlayer: player, epoch: entry.epoch}, nil
+}
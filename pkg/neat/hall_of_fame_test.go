@@ -0,0 +1,80 @@
+package neatnetwork
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHallOfFame(t *testing.T) {
+	genome, err := ReadGenome("../../genomes/base_genome.yaml")
+	if !assert.NoError(t, err) {
+		return
+	}
+	dir := t.TempDir()
+	hall := newHallOfFame(dir, 0, 2)
+	for epoch := range 3 {
+		assert.NoError(t, hall.Add(epoch, genome))
+	}
+	if assert.Equal(t, 2, hall.Len(), "The hall should keep only the latest champions") {
+		assert.Equal(t, 1, hall.champions[0].epoch)
+		assert.NotSame(t, genome, hall.champions[0].genome, "The hall should not share the genomes of the population")
+	}
+
+	resumed := newHallOfFame(dir, 0, 0)
+	assert.NoError(t, resumed.load(2))
+	if assert.Equal(t, 2, resumed.Len(), "A resumed hall should read only the champions of the epochs before") {
+		assert.Equal(t, 1, resumed.champions[1].epoch)
+	}
+	other := newHallOfFame(dir, 1, 0)
+	assert.NoError(t, other.load(3))
+	assert.Zero(t, other.Len(), "The champions of another run should not be read")
+
+	player, err := hall.newPlayer(rand.New(rand.NewSource(1)))
+	if assert.NoError(t, err) {
+		assert.Equal(t, -1, player.GetId())
+		assert.Nil(t, player.GetOrganism(), "A champion should not get a fitness")
+		assert.Contains(t, player.GetName(), "Champion")
+	}
+}
+
+func TestPrepareGroupsHallOfFame(t *testing.T) {
+	genome, err := ReadGenome("../../genomes/base_genome.yaml")
+	if !assert.NoError(t, err) {
+		return
+	}
+	config := DefaultTrainingConfig()
+	config.Opponents = OpponentsConfig{Bots: []string{"random"}, Seats: 1, HallOfFame: HallOfFameConfig{Share: 0.25}}
+	evaluator := NewMonopolyEvaluator(config, nil)
+	evaluator.hallOfFame = newHallOfFame(t.TempDir(), 0, 0)
+	var players []MonopolyPlayer
+	for range 4 {
+		player, err := NewBotPlayer("heuristic")
+		if !assert.NoError(t, err) {
+			return
+		}
+		players = append(players, player)
+	}
+
+	groups, err := evaluator.prepareGroups(players)
+	if assert.NoError(t, err) {
+		assert.Len(t, groups, 2, "An empty hall should leave its seats to the organisms")
+	}
+
+	assert.NoError(t, evaluator.hallOfFame.Add(0, genome))
+	groups, err = evaluator.prepareGroups(players)
+	if !assert.NoError(t, err) || !assert.Len(t, groups, 2) {
+		return
+	}
+	for _, group := range groups {
+		assert.Len(t, group, 4)
+		champions := 0
+		for _, player := range group {
+			if _, ok := player.(*hallOfFamePlayer); ok {
+				champions++
+			}
+		}
+		assert.Equal(t, 1, champions)
+	}
+}
@@ -0,0 +1,418 @@
+package neatnetwork
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"maps"
+	cfg "monopoly/pkg/config"
+	"monopoly/pkg/monopoly"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/yaricom/goNEAT/v4/neat/genetics"
+	neatmath "github.com/yaricom/goNEAT/v4/neat/math"
+	"github.com/yaricom/goNEAT/v4/neat/network"
+)
+
+// Layout names every input and output of the network in order. It is generated from the board,
+// so the property inputs always follow the property order of the game.
+// Sensors are only ever appended, a genome knowing a prefix of the layout still fits it.
+type Layout struct {
+	Inputs  []string
+	Outputs []string
+	// Features is the index of the first derived feature input, they are optional and follow all other inputs
+	Features int
+
+	propertyInputs            map[int]map[string]int
+	playerInputs              map[int]map[string]int
+	currPlayerInputs          map[string]int
+	baseInputs                map[string]int
+	availableStdActionInputs  map[monopoly.StdAction]int
+	availableJailActionInputs map[monopoly.JailAction]int
+	setInputs                 map[string]map[int]int
+	featureInputs             map[string]int
+	outputs                   map[string]int
+}
+
+// LAYOUT_STAMP is the keyword of the line storing the layout version in genome files
+const LAYOUT_STAMP = "layout"
+
+var stdActionInputNames = []struct {
+	action monopoly.StdAction
+	name   string
+}{
+	{monopoly.NOACTION, "NOACTION"},
+	{monopoly.MORTGAGE, "MORTGAGE"},
+	{monopoly.BUYOUT, "BUYOUT"},
+	{monopoly.SELLOFFER, "SELLOFFER"},
+	{monopoly.BUYOFFER, "BUYOFFER"},
+	{monopoly.BUYHOUSE, "BUYHOUSE"},
+	{monopoly.SELLHOUSE, "SELLHOUSE"},
+}
+
+var jailActionInputNames = []struct {
+	action monopoly.JailAction
+	name   string
+}{
+	{monopoly.ROLL_DICE, "ROLL_DICE"},
+	{monopoly.BAIL, "BAIL"},
+	{monopoly.CARD, "CARD"},
+}
+
+var outputNames = []string{
+	"BUY_DECISION", "BID_DECISION", "BUY_FROM_PLAYER", "SELL_TO_PLAYER",
+	"NO_ACTION", "MORTGAGE", "BUYOUT", "SELL_OFFER", "BUY_OFFER", "BUY_HOUSE", "SELL_HOUSE",
+	"PLAYER_1", "PLAYER_2", "PLAYER_3",
+	"PRICE",
+	"ROLL_DICE", "BAIL", "USE_CARD",
+}
+
+func newLayout(board monopoly.Board) *Layout {
+	var fields []monopoly.FieldInfo
+	for _, field := range board.Fields {
+		if field.PropertyIndex >= 0 {
+			fields = append(fields, field)
+		}
+	}
+	slices.SortFunc(fields, func(a, b monopoly.FieldInfo) int { return a.PropertyIndex - b.PropertyIndex })
+	names := make([]string, len(fields))
+	houses := make([]bool, len(fields))
+	for idx, field := range fields {
+		names[idx] = field.Name
+		houses[idx] = field.HousePrice > 0
+	}
+	return buildLayout(names, houses, colorSets(board))
+}
+
+// colorSets returns the names of the sets houses are built on, in order.
+func colorSets(board monopoly.Board) []string {
+	var sets []string
+	for set := range board.Sets {
+		if set != monopoly.RAILROAD && set != monopoly.UTILITY {
+			sets = append(sets, set)
+		}
+	}
+	slices.Sort(sets)
+	return sets
+}
+
+// buildLayout numbers the inputs and outputs, houses tells which properties have the HOUSES input.
+func buildLayout(properties []string, houses []bool, sets []string) *Layout {
+	l := &Layout{
+		propertyInputs:            map[int]map[string]int{},
+		playerInputs:              map[int]map[string]int{},
+		availableStdActionInputs:  map[monopoly.StdAction]int{},
+		availableJailActionInputs: map[monopoly.JailAction]int{},
+		setInputs:                 map[string]map[int]int{},
+		outputs:                   map[string]int{},
+	}
+	for id, name := range properties {
+		if houses[id] {
+			l.propertyInputs[id] = l.addInputs(name, "OWNER", "IS_MORTGAGED", "HOUSES")
+		} else {
+			l.propertyInputs[id] = l.addInputs(name, "OWNER", "IS_MORTGAGED")
+		}
+	}
+	for id := 1; id <= cfg.LAST_PLAYER_ID; id++ {
+		l.playerInputs[id] = l.addInputs(fmt.Sprintf("PLAYER_%d", id), "IS_ALIVE", "MONEY")
+	}
+	l.currPlayerInputs = l.addInputs("CURR_PLAYER", "IS_ALIVE", "IS_JAILED", "POSITION", "MONEY", "JAIL_CARDS")
+	l.baseInputs = l.addInputs("", "DECISION_CONTEXT", "PROPERTY_ID", "PRICE", "CURR_BID", "CURR_BID_WINNER", "CHARGE")
+	for _, input := range stdActionInputNames {
+		l.availableStdActionInputs[input.action] = l.addInput("AVAILABLE." + input.name)
+	}
+	// inputs added together with the jail decision
+	l.currPlayerInputs["ROUNDS_IN_JAIL"] = l.addInput("CURR_PLAYER.ROUNDS_IN_JAIL")
+	for _, input := range jailActionInputNames {
+		l.availableJailActionInputs[input.action] = l.addInput("AVAILABLE." + input.name)
+	}
+	// inputs added together with the opponent details
+	for id := 1; id <= cfg.LAST_PLAYER_ID; id++ {
+		details := l.addInputs(fmt.Sprintf("PLAYER_%d", id), "POSITION", "IS_JAILED", "ROUNDS_IN_JAIL", "JAIL_CARDS", "NET_WORTH", "MONOPOLIES")
+		maps.Copy(l.playerInputs[id], details)
+	}
+
+	// derived features, see LoadFeatures
+	l.Features = len(l.Inputs)
+	for _, set := range sets {
+		// the player making the decision is 0, the opponents 1-3
+		l.setInputs[set] = map[int]int{}
+		for id := 0; id <= cfg.LAST_PLAYER_ID; id++ {
+			l.setInputs[set][id] = l.addInput(fmt.Sprintf("SET.%s.PLAYER_%d", set, id))
+		}
+	}
+	for id, name := range properties {
+		maps.Copy(l.propertyInputs[id], l.addInputs(name, "RENT", "DISTANCE"))
+	}
+	l.featureInputs = l.addInputs("", "NEXT_HOUSE_COST", "RENT_EXPOSURE")
+
+	for idx, name := range outputNames {
+		l.outputs[name] = idx
+	}
+	l.Outputs = slices.Clone(outputNames)
+	return l
+}
+
+func (l *Layout) addInput(name string) int {
+	l.Inputs = append(l.Inputs, name)
+	return len(l.Inputs) - 1
+}
+
+func (l *Layout) addInputs(prefix string, names ...string) map[string]int {
+	inputs := map[string]int{}
+	for _, name := range names {
+		if prefix != "" {
+			inputs[name] = l.addInput(prefix + "." + name)
+		} else {
+			inputs[name] = l.addInput(name)
+		}
+	}
+	return inputs
+}
+
+// Version is a hash of the input and output names, any change of the layout changes it.
+func (l *Layout) Version() string {
+	hash := sha256.New()
+	for _, name := range l.Inputs {
+		fmt.Fprintln(hash, name)
+	}
+	fmt.Fprintln(hash, "--")
+	for _, name := range l.Outputs {
+		fmt.Fprintln(hash, name)
+	}
+	return hex.EncodeToString(hash.Sum(nil))[:12]
+}
+
+// Prefix returns the first inputs and outputs of the layout, the layout of a genome trained before the rest was added.
+func (l *Layout) Prefix(inputs int, outputs int) *Layout {
+	return &Layout{Inputs: l.Inputs[:inputs], Outputs: l.Outputs[:outputs], Features: min(l.Features, inputs)}
+}
+
+// GenomeVersion returns the version of the layout prefix the genome uses, an error if the genome does not fit the layout.
+func (l *Layout) GenomeVersion(genome *genetics.Genome) (string, error) {
+	inputs, outputs := countNodes(genome)
+	if inputs > len(l.Inputs) || outputs > len(l.Outputs) {
+		return "", fmt.Errorf("genome has %d inputs and %d outputs, the layout only %d and %d", inputs, outputs, len(l.Inputs), len(l.Outputs))
+	}
+	return l.Prefix(inputs, outputs).Version(), nil
+}
+
+// countNodes counts the inputs and outputs of the genome, bias nodes are loaded with sensors like inputs.
+func countNodes(genome *genetics.Genome) (int, int) {
+	inputs, outputs := 0, 0
+	for _, node := range genome.Nodes {
+		switch node.NeuronType {
+		case network.InputNeuron, network.BiasNeuron:
+			inputs++
+		case network.OutputNeuron:
+			outputs++
+		}
+	}
+	return inputs, outputs
+}
+
+// ReadGenome reads a genome file and refuses it if it was made for another layout.
+func ReadGenome(filePath string) (*genetics.Genome, error) {
+	genomeReader, err := genetics.NewGenomeReaderFromFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create genome reader: %w", err)
+	}
+	genome, err := genomeReader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read genome: %w", err)
+	}
+	stamp, err := readLayoutStamp(filePath)
+	if err != nil {
+		return nil, err
+	}
+	version, err := layout.GenomeVersion(genome)
+	if err != nil {
+		return nil, fmt.Errorf("genome %s: %w", filePath, err)
+	}
+	if stamp == "" {
+		return nil, fmt.Errorf("genome %s has no layout version, migrate it with --migrate-genome", filePath)
+	}
+	if stamp != version {
+		return nil, fmt.Errorf("genome %s has layout %s, the network expects %s", filePath, stamp, version)
+	}
+	return genome, nil
+}
+
+// readLayoutStamp returns the layout version stored in the genome file, empty if there is none.
+func readLayoutStamp(filePath string) (string, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		// "layout <version>" in plain genomes, "layout: <version>" in YAML genomes
+		if rest, ok := strings.CutPrefix(scanner.Text(), LAYOUT_STAMP); ok && (strings.HasPrefix(rest, " ") || strings.HasPrefix(rest, ":")) {
+			return strings.TrimSpace(strings.TrimPrefix(rest, ":")), nil
+		}
+	}
+	return "", scanner.Err()
+}
+
+// StampGenomeFile appends the layout version of the genome to its file, see ReadGenome.
+func StampGenomeFile(filePath string, genome *genetics.Genome) error {
+	version, err := layout.GenomeVersion(genome)
+	if err != nil {
+		return err
+	}
+	file, err := os.OpenFile(filePath, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	separator := " "
+	if isYAML(filePath) {
+		separator = ": "
+	}
+	_, err = fmt.Fprintf(file, "%s%s%s\n", LAYOUT_STAMP, separator, version)
+	return err
+}
+
+// WriteGenome writes the genome in the encoding given by the file extension and stamps it.
+func WriteGenome(filePath string, genome *genetics.Genome) error {
+	file, err := os.Create(filePath)
+	if err != nil {
+		return err
+	}
+	encoding := genetics.PlainGenomeEncoding
+	if isYAML(filePath) {
+		encoding = genetics.YAMLGenomeEncoding
+	}
+	writer, err := genetics.NewGenomeWriter(file, encoding)
+	if err == nil {
+		err = writer.WriteGenome(genome)
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	return StampGenomeFile(filePath, genome)
+}
+
+func isYAML(filePath string) bool {
+	return strings.HasSuffix(filePath, ".yaml") || strings.HasSuffix(filePath, ".yml")
+}
+
+// legacyLayout is the layout of the genomes saved before layouts were versioned. Its hand-numbered
+// property inputs gave the HOUSES input to the properties 7, 17, 20 and 25 instead of 6, 14, 19 and 22.
+func legacyLayout() *Layout {
+	current := newLayout(monopoly.GetBoard())
+	names := make([]string, len(current.propertyInputs))
+	houses := make([]bool, len(current.propertyInputs))
+	for id := range names {
+		owner := current.Inputs[current.propertyInputs[id]["OWNER"]]
+		names[id] = strings.TrimSuffix(owner, ".OWNER")
+		houses[id] = !slices.Contains([]int{2, 6, 10, 14, 19, 22}, id)
+	}
+	return buildLayout(names, houses, colorSets(monopoly.GetBoard()))
+}
+
+// MigrateGenomeFile rewrites a genome to the current layout without the derived features: a genome saved before layouts were
+// versioned or one trained before the latest inputs were added. Inputs are matched by name, the ones
+// missing from the genome are added without links and the links of legacy inputs the layout no longer
+// has are removed. Outputs are kept, the decisions of missing outputs fall back to fixed rules.
+func MigrateGenomeFile(filePath string) error {
+	stamp, err := readLayoutStamp(filePath)
+	if err != nil {
+		return err
+	}
+	genomeReader, err := genetics.NewGenomeReaderFromFile(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to create genome reader: %w", err)
+	}
+	genome, err := genomeReader.Read()
+	if err != nil {
+		return fmt.Errorf("failed to read genome: %w", err)
+	}
+	from := legacyLayout()
+	if stamp != "" {
+		version, err := layout.GenomeVersion(genome)
+		if err != nil || stamp != version {
+			return fmt.Errorf("genome %s has layout %s, which is not a part of the current layout", filePath, stamp)
+		}
+		if inputs, _ := countNodes(genome); inputs >= layout.Features {
+			return fmt.Errorf("genome %s already has all inputs of layout %s", filePath, layout.Version())
+		}
+		from = layout
+	}
+	migrated, err := migrateGenome(genome, from, layout.Prefix(layout.Features, len(layout.Outputs)))
+	if err != nil {
+		return fmt.Errorf("genome %s: %w", filePath, err)
+	}
+	return WriteGenome(filePath, migrated)
+}
+
+// migrateGenome renumbers the nodes, so that the inputs follow the target layout: the network
+// loads sensors into the inputs in the order of node ids.
+func migrateGenome(genome *genetics.Genome, from *Layout, to *Layout) (*genetics.Genome, error) {
+	targetIndex := map[string]int{}
+	for idx, name := range to.Inputs {
+		targetIndex[name] = idx
+	}
+	var inputs, outputs, hidden []*network.NNode
+	for _, node := range genome.Nodes {
+		switch node.NeuronType {
+		case network.InputNeuron, network.BiasNeuron:
+			inputs = append(inputs, node)
+		case network.OutputNeuron:
+			outputs = append(outputs, node)
+		default:
+			hidden = append(hidden, node)
+		}
+	}
+	if len(inputs) > len(from.Inputs) || len(outputs) > len(to.Outputs) || !slices.Equal(from.Outputs[:len(outputs)], to.Outputs[:len(outputs)]) {
+		return nil, fmt.Errorf("genome with %d inputs and %d outputs does not fit the layout", len(inputs), len(outputs))
+	}
+
+	mapped := map[int]*network.NNode{}
+	dropped := map[*network.NNode]bool{}
+	for idx, node := range inputs {
+		target, ok := targetIndex[from.Inputs[idx]]
+		if !ok {
+			dropped[node] = true
+			continue
+		}
+		mapped[target] = node
+	}
+	var nodes []*network.NNode
+	for idx := range to.Inputs {
+		node, ok := mapped[idx]
+		if !ok {
+			node = network.NewNNode(0, network.InputNeuron)
+			node.ActivationType = neatmath.NullActivation
+		}
+		nodes = append(nodes, node)
+	}
+	nodes = append(nodes, outputs...)
+	nodes = append(nodes, hidden...)
+	for id, node := range nodes {
+		node.Id = id
+	}
+
+	var genes []*genetics.Gene
+	for _, gene := range genome.Genes {
+		if !dropped[gene.Link.InNode] && !dropped[gene.Link.OutNode] {
+			genes = append(genes, gene)
+		}
+	}
+	return genetics.NewGenome(genome.Id, genome.Traits, nodes, genes), nil
+}
+
+// AddFeatureInputs returns the genome with the inputs of the derived features, added without links.
+func AddFeatureInputs(genome *genetics.Genome) (*genetics.Genome, error) {
+	if _, err := layout.GenomeVersion(genome); err != nil {
+		return nil, err
+	}
+	return migrateGenome(genome, layout, layout)
+}
@@ -0,0 +1,141 @@
+package neatnetwork
+
+import (
+	"bytes"
+	"monopoly/pkg/monopoly"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yaricom/goNEAT/v4/neat/genetics"
+	neatmath "github.com/yaricom/goNEAT/v4/neat/math"
+	"github.com/yaricom/goNEAT/v4/neat/network"
+)
+
+func TestLayoutFollowsBoard(t *testing.T) {
+	assert.Len(t, NewMonopolySensors(), len(layout.Inputs))
+	unique := map[string]bool{}
+	for _, name := range layout.Inputs {
+		assert.False(t, unique[name], "Input %s is not unique", name)
+		unique[name] = true
+	}
+
+	for _, field := range monopoly.GetBoard().Fields {
+		if field.PropertyIndex < 0 {
+			continue
+		}
+		inputs := propertyInputs[field.PropertyIndex]
+		assert.Equal(t, field.Name+".OWNER", layout.Inputs[inputs["OWNER"]])
+		_, houses := inputs["HOUSES"]
+		assert.Equal(t, field.Set != monopoly.RAILROAD && field.Set != monopoly.UTILITY, houses, "HOUSES input of %s", field.Name)
+	}
+	assert.Equal(t, "Pink1.HOUSES", layout.Inputs[propertyInputs[6]["HOUSES"]])
+	assert.NotContains(t, propertyInputs[7], "HOUSES")
+	assert.Equal(t, 78, playerInputs[1]["IS_ALIVE"])
+	assert.Equal(t, 102, currPlayerInputs["ROUNDS_IN_JAIL"])
+	assert.Equal(t, 105, availableJailActionInputs[monopoly.CARD])
+	assert.Equal(t, 17, outputs["USE_CARD"])
+}
+
+func TestLayoutVersion(t *testing.T) {
+	assert.Equal(t, layout.Version(), newLayout(monopoly.GetBoard()).Version())
+	assert.NotEqual(t, layout.Version(), layout.Prefix(102, 15).Version())
+	assert.NotEqual(t, layout.Prefix(102, 15).Version(), legacyLayout().Prefix(102, 15).Version())
+}
+
+func TestReadGenome(t *testing.T) {
+	for _, name := range []string{"base_genome.yaml", "trained", "100_wins", "first_good", "draw_machine"} {
+		genome, err := ReadGenome(filepath.Join("../../genomes", name))
+		if assert.NoError(t, err, name) {
+			// goNEAT refuses to spawn a population from a genome with the nodes out of order
+			assert.True(t, slices.IsSortedFunc(genome.Nodes, func(a, b *network.NNode) int { return a.Id - b.Id }), name)
+		}
+	}
+
+	base, err := os.ReadFile("../../genomes/base_genome.yaml")
+	if !assert.NoError(t, err) {
+		return
+	}
+	dir := t.TempDir()
+	unstamped := filepath.Join(dir, "unstamped.yaml")
+	version, err := readLayoutStamp("../../genomes/base_genome.yaml")
+	assert.NoError(t, err)
+	stamp := []byte("layout: " + version + "\n")
+	assert.NoError(t, os.WriteFile(unstamped, bytes.Replace(base, stamp, nil, 1), 0644))
+	_, err = ReadGenome(unstamped)
+	assert.ErrorContains(t, err, "--migrate-genome")
+
+	other := filepath.Join(dir, "other.yaml")
+	assert.NoError(t, os.WriteFile(other, bytes.Replace(base, stamp, []byte("layout: 000000000000\n"), 1), 0644))
+	_, err = ReadGenome(other)
+	assert.ErrorContains(t, err, "has layout 000000000000")
+}
+
+func TestMigrateGenome(t *testing.T) {
+	// a legacy genome linking the Utility1 OWNER input and its never loaded HOUSES input to BUY_DECISION
+	var nodes []*network.NNode
+	for id := range 102 {
+		node := network.NewNNode(id, network.InputNeuron)
+		node.ActivationType = neatmath.NullActivation
+		nodes = append(nodes, node)
+	}
+	output := network.NewNNode(102, network.OutputNeuron)
+	nodes = append(nodes, output)
+	genes := []*genetics.Gene{
+		genetics.NewGene(0.5, nodes[19], output, false, 1, 0),
+		genetics.NewGene(0.5, nodes[21], output, false, 2, 0),
+	}
+	filePath := filepath.Join(t.TempDir(), "legacy")
+	file, err := os.Create(filePath)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.NoError(t, genetics.NewGenome(1, nil, nodes, genes).Write(file))
+	assert.NoError(t, file.Close())
+
+	_, err = ReadGenome(filePath)
+	assert.Error(t, err)
+	assert.NoError(t, MigrateGenomeFile(filePath))
+	genome, err := ReadGenome(filePath)
+	if !assert.NoError(t, err) {
+		return
+	}
+	inputs, outputs := countNodes(genome)
+	assert.Equal(t, layout.Features, inputs)
+	assert.Equal(t, 1, outputs)
+	if assert.Len(t, genome.Genes, 1) {
+		assert.Equal(t, propertyInputs[7]["OWNER"], genome.Genes[0].Link.InNode.Id)
+		assert.Equal(t, layout.Features, genome.Genes[0].Link.OutNode.Id)
+	}
+	assert.Error(t, MigrateGenomeFile(filePath), "A genome with all inputs should not be migrated again")
+}
+
+func TestMigrateGenomeAddsInputs(t *testing.T) {
+	trained, err := os.ReadFile("../../genomes/trained")
+	if !assert.NoError(t, err) {
+		return
+	}
+	filePath := filepath.Join(t.TempDir(), "trained")
+	assert.NoError(t, os.WriteFile(filePath, trained, 0644))
+	assert.NoError(t, MigrateGenomeFile(filePath))
+
+	before, err := LoadNEATPlayer("../../genomes/trained")
+	if !assert.NoError(t, err) {
+		return
+	}
+	after, err := LoadNEATPlayer(filePath)
+	if !assert.NoError(t, err) {
+		return
+	}
+	inputs, _ := countNodes(after.organism.Genotype)
+	assert.Equal(t, layout.Features, inputs)
+
+	// the added inputs have no links, the decisions stay the same
+	ms := NewMonopolySensors()
+	ms.LoadState(testState(4, map[int]int{3: 1, 26: 2}), 0)
+	ms.LoadDecisionContext(BUY_DECISION)
+	ms.LoadPropertyId(5)
+	assert.Equal(t, before.GetDecision(ms), after.GetDecision(ms))
+}
@@ -0,0 +1,224 @@
+package neatnetwork
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"monopoly/pkg/monopoly"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/yaricom/goNEAT/v4/neat"
+	"github.com/yaricom/goNEAT/v4/neat/genetics"
+)
+
+// The metrics of every epoch are saved to the output directory as a JSON array and as a CSV table,
+// both are rewritten after every epoch.
+const (
+	METRICS_JSON = "metrics.json"
+	METRICS_CSV  = "metrics.csv"
+)
+
+// EpochMetrics sums up the evaluation of one epoch.
+type EpochMetrics struct {
+	Trial             int     `json:"trial"`
+	Epoch             int     `json:"epoch"`
+	BestFitness       float64 `json:"best_fitness"`
+	MeanFitness       float64 `json:"mean_fitness"`
+	MedianFitness     float64 `json:"median_fitness"`
+	Species           int     `json:"species"`
+	ChampionNodes     int     `json:"champion_nodes"`
+	ChampionGenes     int     `json:"champion_genes"`
+	Games             int     `json:"games"`               // finished games, every seating counts
+	AverageRounds     float64 `json:"average_rounds"`      // average length of the finished games
+	HeuristicGames    int     `json:"heuristic_games"`     // finished games with a heuristic bot at the table
+	HeuristicWinRate  float64 `json:"heuristic_win_rate"`  // share of the heuristic games won by an organism
+	HeuristicDrawRate float64 `json:"heuristic_draw_rate"` // share of the heuristic games without a winner
+	EvaluationSeconds float64 `json:"evaluation_seconds"`  // wall time of the evaluation
+}
+
+var metricsHeader = []string{
+	"trial", "epoch", "best_fitness", "mean_fitness", "median_fitness", "species", "champion_nodes", "champion_genes",
+	"games", "average_rounds", "heuristic_games", "heuristic_win_rate", "heuristic_draw_rate", "evaluation_seconds",
+}
+
+func (m EpochMetrics) record() []string {
+	f := func(value float64) string {
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	}
+	return []string{
+		strconv.Itoa(m.Trial), strconv.Itoa(m.Epoch), f(m.BestFitness), f(m.MeanFitness), f(m.MedianFitness),
+		strconv.Itoa(m.Species), strconv.Itoa(m.ChampionNodes), strconv.Itoa(m.ChampionGenes),
+		strconv.Itoa(m.Games), f(m.AverageRounds), strconv.Itoa(m.HeuristicGames), f(m.HeuristicWinRate),
+		f(m.HeuristicDrawRate), f(m.EvaluationSeconds),
+	}
+}
+
+// gameStats counts the finished games of an epoch, the groups add them concurrently.
+type gameStats struct {
+	mutex          sync.Mutex
+	games          int
+	rounds         int
+	heuristicGames int
+	heuristicWins  int
+	heuristicDraws int
+}
+
+func (s *gameStats) add(group *NEATPlayerGroup) {
+	if !group.gameFinished {
+		return
+	}
+	heuristic := slices.ContainsFunc(group.players, func(player MonopolyPlayer) bool {
+		return player.GetOrganism() == nil && player.GetName() == "heuristic"
+	})
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.games++
+	s.rounds += group.rounds
+	if !heuristic {
+		return
+	}
+	s.heuristicGames++
+	switch group.finish {
+	case monopoly.WIN:
+		if group.players[group.winner].GetOrganism() != nil {
+			s.heuristicWins++
+		}
+	case monopoly.DRAW, monopoly.ROUND_LIMIT:
+		s.heuristicDraws++
+	}
+}
+
+// epochMetrics collects the metrics of the evaluated population.
+func epochMetrics(trial int, epoch int, pop *genetics.Population, champion *genetics.Organism, stats *gameStats, duration time.Duration) EpochMetrics {
+	m := EpochMetrics{
+		Trial:             trial,
+		Epoch:             epoch,
+		BestFitness:       champion.Fitness,
+		Species:           len(pop.Species),
+		ChampionNodes:     len(champion.Genotype.Nodes),
+		ChampionGenes:     len(champion.Genotype.Genes),
+		Games:             stats.games,
+		HeuristicGames:    stats.heuristicGames,
+		EvaluationSeconds: duration.Seconds(),
+	}
+	fitness := make([]float64, 0, len(pop.Organisms))
+	for _, org := range pop.Organisms {
+		fitness = append(fitness, org.Fitness)
+		m.MeanFitness += org.Fitness / float64(len(pop.Organisms))
+	}
+	slices.Sort(fitness)
+	if n := len(fitness); n > 0 {
+		m.MedianFitness = (fitness[(n-1)/2] + fitness[n/2]) / 2
+	}
+	if stats.games > 0 {
+		m.AverageRounds = float64(stats.rounds) / float64(stats.games)
+	}
+	if stats.heuristicGames > 0 {
+		m.HeuristicWinRate = float64(stats.heuristicWins) / float64(stats.heuristicGames)
+		m.HeuristicDrawRate = float64(stats.heuristicDraws) / float64(stats.heuristicGames)
+	}
+	return m
+}
+
+// metricsLog keeps the metrics of all epochs of the experiment, it is read by the metrics endpoint
+// while the training adds to it.
+type metricsLog struct {
+	mutex  sync.RWMutex
+	dir    string
+	epochs []EpochMetrics
+}
+
+func newMetricsLog(dir string) *metricsLog {
+	return &metricsLog{dir: dir}
+}
+
+// load reads the metrics of a resumed experiment, the epochs from the checkpoint on are played again.
+func (l *metricsLog) load(trial int, generation int) error {
+	data, err := os.ReadFile(filepath.Join(l.dir, METRICS_JSON))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var epochs []EpochMetrics
+	if err := json.Unmarshal(data, &epochs); err != nil {
+		return fmt.Errorf("failed to read metrics: %w", err)
+	}
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.epochs = slices.DeleteFunc(epochs, func(m EpochMetrics) bool {
+		return m.Trial > trial || m.Trial == trial && m.Epoch >= generation
+	})
+	return nil
+}
+
+// add appends the metrics of the epoch and saves all of them.
+func (l *metricsLog) add(m EpochMetrics) error {
+	l.mutex.Lock()
+	l.epochs = append(l.epochs, m)
+	l.mutex.Unlock()
+	return l.save()
+}
+
+func (l *metricsLog) all() []EpochMetrics {
+	l.mutex.RLock()
+	defer l.mutex.RUnlock()
+	return append([]EpochMetrics{}, l.epochs...)
+}
+
+func (l *metricsLog) save() error {
+	epochs := l.all()
+	data, err := json.MarshalIndent(epochs, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(l.dir, METRICS_JSON), data, 0644); err != nil {
+		return err
+	}
+	file, err := os.Create(filepath.Join(l.dir, METRICS_CSV))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	writer := csv.NewWriter(file)
+	writer.Write(metricsHeader)
+	for _, m := range epochs {
+		writer.Write(m.record())
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// handler answers GET /metrics with the metrics of all epochs as JSON.
+func (l *metricsLog) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /metrics", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(l.all()); err != nil {
+			neat.ErrorLog(fmt.Sprintf("Failed to send metrics, reason: %s\n", err))
+		}
+	})
+	return mux
+}
+
+// serve runs the metrics endpoint until the context is done.
+func (l *metricsLog) serve(ctx context.Context, addr string) {
+	srv := &http.Server{Addr: addr, Handler: l.handler()}
+	go func() {
+		<-ctx.Done()
+		srv.Close()
+	}()
+	neat.InfoLog(fmt.Sprintf("Training metrics available at http://%s/metrics\n", addr))
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		neat.ErrorLog(fmt.Sprintf("Failed to serve metrics, reason: %s\n", err))
+	}
+}
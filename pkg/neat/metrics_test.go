@@ -0,0 +1,150 @@
+package neatnetwork
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"monopoly/pkg/monopoly"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yaricom/goNEAT/v4/experiment"
+	"github.com/yaricom/goNEAT/v4/neat"
+	"github.com/yaricom/goNEAT/v4/neat/genetics"
+)
+
+func TestEpochMetrics(t *testing.T) {
+	options, err := neat.ReadNeatOptionsFromFile("../../neat_options.yaml")
+	if !assert.NoError(t, err) {
+		return
+	}
+	options.PopSize = 4
+	genome, err := ReadGenome("../../genomes/base_genome.yaml")
+	if !assert.NoError(t, err) {
+		return
+	}
+	pop, err := genetics.NewPopulation(genome, options)
+	if !assert.NoError(t, err) {
+		return
+	}
+	for i, fitness := range []float64{4, 1, 10, 2} {
+		pop.Organisms[i].Fitness = fitness
+	}
+
+	organism, err := NewNEATMonopolyPlayer(pop.Organisms[0])
+	if !assert.NoError(t, err) {
+		return
+	}
+	heuristic, err := NewBotPlayer("heuristic")
+	if !assert.NoError(t, err) {
+		return
+	}
+	random, err := NewBotPlayer("random")
+	if !assert.NoError(t, err) {
+		return
+	}
+	stats := &gameStats{}
+	for _, game := range []struct {
+		players []MonopolyPlayer
+		finish  monopoly.FinishOption
+		winner  int
+		rounds  int
+	}{
+		{[]MonopolyPlayer{organism, heuristic}, monopoly.WIN, 0, 10},
+		{[]MonopolyPlayer{heuristic, organism}, monopoly.WIN, 0, 20},
+		{[]MonopolyPlayer{organism, heuristic}, monopoly.ROUND_LIMIT, 1, 50},
+		{[]MonopolyPlayer{organism, heuristic}, monopoly.CANCELLED, -1, 3},
+		{[]MonopolyPlayer{organism, random}, monopoly.WIN, 0, 40},
+	} {
+		group := &NEATPlayerGroup{players: game.players, finish: game.finish, winner: game.winner, rounds: game.rounds}
+		group.gameFinished = game.finish != monopoly.CANCELLED
+		stats.add(group)
+	}
+
+	m := epochMetrics(0, 3, pop, pop.Organisms[2], stats, 2*time.Second)
+	assert.Equal(t, 3, m.Epoch)
+	assert.Equal(t, 10.0, m.BestFitness)
+	assert.Equal(t, 4.25, m.MeanFitness)
+	assert.Equal(t, 3.0, m.MedianFitness)
+	assert.Equal(t, len(pop.Species), m.Species)
+	assert.Equal(t, len(genome.Nodes), m.ChampionNodes)
+	assert.Equal(t, 4, m.Games, "Cancelled games should not be counted")
+	assert.Equal(t, 30.0, m.AverageRounds)
+	assert.Equal(t, 3, m.HeuristicGames)
+	assert.InDelta(t, 1.0/3, m.HeuristicWinRate, 1e-9)
+	assert.InDelta(t, 1.0/3, m.HeuristicDrawRate, 1e-9)
+	assert.Equal(t, 2.0, m.EvaluationSeconds)
+}
+
+func TestMetricsLog(t *testing.T) {
+	dir := t.TempDir()
+	metrics := newMetricsLog(dir)
+	for trial := range 2 {
+		for epoch := range 3 {
+			assert.NoError(t, metrics.add(EpochMetrics{Trial: trial, Epoch: epoch, BestFitness: float64(epoch) + 0.5}))
+		}
+	}
+	data, err := os.ReadFile(filepath.Join(dir, METRICS_CSV))
+	if assert.NoError(t, err) {
+		lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+		if assert.Len(t, lines, 7) {
+			assert.Equal(t, strings.Join(metricsHeader, ","), lines[0])
+			assert.True(t, strings.HasPrefix(lines[3], "0,2,2.5,"), lines[3])
+		}
+	}
+
+	resumed := newMetricsLog(dir)
+	assert.NoError(t, resumed.load(1, 1))
+	assert.Len(t, resumed.all(), 4, "The epochs from the checkpoint on should be dropped")
+	assert.NoError(t, newMetricsLog(t.TempDir()).load(0, 0), "A new experiment has no metrics yet")
+
+	recorder := httptest.NewRecorder()
+	resumed.handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	var served []EpochMetrics
+	if assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &served)) {
+		assert.Equal(t, resumed.all(), served)
+	}
+}
+
+func TestCancelledEpochMetrics(t *testing.T) {
+	options, err := neat.ReadNeatOptionsFromFile("../../neat_options.yaml")
+	if !assert.NoError(t, err) {
+		return
+	}
+	options.PopSize = 8
+	genome, err := ReadGenome("../../genomes/base_genome.yaml")
+	if !assert.NoError(t, err) {
+		return
+	}
+	pop, err := genetics.NewPopulation(genome, options)
+	if !assert.NoError(t, err) {
+		return
+	}
+	fitness, err := ReadFitness("../../fitness.yaml")
+	if !assert.NoError(t, err) {
+		return
+	}
+	config := DefaultTrainingConfig()
+	config.OutputDir = t.TempDir()
+	config.Evaluation.GamesPerEpoch = 2
+	config.Opponents.HallOfFame.Share = 0.25
+	evaluator := NewMonopolyEvaluator(config, fitness)
+	evaluator.hallOfFame = newHallOfFame(config.OutputDir, 0, 0)
+
+	ctx, cancel := context.WithCancel(neat.NewContext(context.Background(), options))
+	cancel()
+	err = evaluator.GenerationEvaluate(ctx, pop, &experiment.Generation{Id: 0})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Empty(t, evaluator.metrics.all(), "A cancelled epoch should not be recorded")
+	assert.NoFileExists(t, filepath.Join(config.OutputDir, METRICS_JSON))
+	assert.NoFileExists(t, filepath.Join(config.OutputDir, "champions.txt"))
+	assert.Zero(t, evaluator.hallOfFame.Len())
+	assert.Nil(t, evaluator.lastChampion)
+}
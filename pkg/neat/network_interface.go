@@ -6,185 +6,26 @@ import (
 	"monopoly/pkg/monopoly"
 )
 
-var propertyInputs = map[int]map[string]int{
-	0: { // brown1
-		"OWNER":        0,
-		"IS_MORTGAGED": 1,
-		"HOUSES":       2,
-	},
-	1: { // brown2
-		"OWNER":        3,
-		"IS_MORTGAGED": 4,
-		"HOUSES":       5,
-	},
-	2: { // railway1
-		"OWNER":        6,
-		"IS_MORTGAGED": 7,
-	},
-	3: { // lightblue1
-		"OWNER":        8,
-		"IS_MORTGAGED": 9,
-		"HOUSES":       10,
-	},
-	4: { // lightblue2
-		"OWNER":        11,
-		"IS_MORTGAGED": 12,
-		"HOUSES":       13,
-	},
-	5: { // lightblue3
-		"OWNER":        14,
-		"IS_MORTGAGED": 15,
-		"HOUSES":       16,
-	},
-	6: { // railway2
-		"OWNER":        17,
-		"IS_MORTGAGED": 18,
-	},
-	7: { // pink1
-		"OWNER":        19,
-		"IS_MORTGAGED": 20,
-		"HOUSES":       21,
-	},
-	8: { // pink2
-		"OWNER":        22,
-		"IS_MORTGAGED": 23,
-		"HOUSES":       24,
-	},
-	9: { // pink3
-		"OWNER":        25,
-		"IS_MORTGAGED": 26,
-		"HOUSES":       27,
-	},
-	10: { // utility1
-		"OWNER":        28,
-		"IS_MORTGAGED": 29,
-	},
-	11: { // orange1
-		"OWNER":        30,
-		"IS_MORTGAGED": 31,
-		"HOUSES":       32,
-	},
-	12: { // orange2
-		"OWNER":        33,
-		"IS_MORTGAGED": 34,
-		"HOUSES":       35,
-	},
-	13: { // orange3
-		"OWNER":        36,
-		"IS_MORTGAGED": 37,
-		"HOUSES":       38,
-	},
-	14: { // railway3
-		"OWNER":        39,
-		"IS_MORTGAGED": 40,
-	},
-	15: { // red1
-		"OWNER":        41,
-		"IS_MORTGAGED": 42,
-		"HOUSES":       43,
-	},
-	16: { // red2
-		"OWNER":        44,
-		"IS_MORTGAGED": 45,
-		"HOUSES":       46,
-	},
-	17: { // red3
-		"OWNER":        47,
-		"IS_MORTGAGED": 48,
-		"HOUSES":       49,
-	},
-	18: { // yellow1
-		"OWNER":        50,
-		"IS_MORTGAGED": 51,
-		"HOUSES":       52,
-	},
-	19: { // utility2
-		"OWNER":        53,
-		"IS_MORTGAGED": 54,
-	},
-	20: { // yellow2
-		"OWNER":        55,
-		"IS_MORTGAGED": 56,
-		"HOUSES":       57,
-	},
-	21: { // yellow3
-		"OWNER":        58,
-		"IS_MORTGAGED": 59,
-		"HOUSES":       60,
-	},
-	22: { // railway4
-		"OWNER":        61,
-		"IS_MORTGAGED": 62,
-	},
-	23: { // green1
-		"OWNER":        63,
-		"IS_MORTGAGED": 64,
-		"HOUSES":       65,
-	},
-	24: { // green2
-		"OWNER":        66,
-		"IS_MORTGAGED": 67,
-		"HOUSES":       68,
-	},
-	25: { // green3
-		"OWNER":        69,
-		"IS_MORTGAGED": 70,
-		"HOUSES":       71,
-	},
-	26: { // blue1
-		"OWNER":        72,
-		"IS_MORTGAGED": 73,
-		"HOUSES":       74,
-	},
-	27: { // blue2
-		"OWNER":        75,
-		"IS_MORTGAGED": 76,
-		"HOUSES":       77,
-	},
-}
+// layout of the sensors and outputs, see Layout
+var layout = newLayout(monopoly.GetBoard())
 
-var playerInputs = map[int]map[string]int{
-	1: {
-		"IS_ALIVE": 78,
-		"MONEY":    79,
-	},
-	2: {
-		"IS_ALIVE": 80,
-		"MONEY":    81,
-	},
-	3: {
-		"IS_ALIVE": 82,
-		"MONEY":    83,
-	},
-}
+var (
+	propertyInputs = layout.propertyInputs // "OWNER", "IS_MORTGAGED" and "HOUSES" if houses can be built
+	playerInputs   = layout.playerInputs   // "IS_ALIVE", "MONEY" and the details of the opponents 1-3, see loadOpponentDetails
 
-// Inputs dedicated to the player making the decision, information is redundant
-var currPlayerInputs = map[string]int{
-	"IS_ALIVE":   84,
-	"IS_JAILED":  85,
-	"POSITION":   86,
-	"MONEY":      87,
-	"JAIL_CARDS": 88,
-}
+	// Inputs dedicated to the player making the decision, information is redundant
+	currPlayerInputs = layout.currPlayerInputs
 
-var baseInputs = map[string]int{
-	"DECISION_CONTEXT": 89, // Current decision context, for example bidding decision, buying decision
-	"PROPERTY_ID":      90, // In case of property-related decisions like bidding
-	"PRICE":            91, // In case of price-related decisions; normalized to 0.0 - 1.0, where 1.0 is MAX_MONEY
-	"CURR_BID":         92, // In case of bidding
-	"CURR_BID_WINNER":  93, // In case of bidding
-	"CHARGE":           94, // In case of charge that would result in player going bankrupt
-}
+	// DECISION_CONTEXT: current decision context, for example bidding decision, buying decision
+	// PROPERTY_ID: in case of property-related decisions like bidding
+	// PRICE: in case of price-related decisions, the current bid in bidding; normalized to 0.0 - 1.0, where 1.0 is MAX_MONEY
+	// CURR_BID, CURR_BID_WINNER: in case of bidding
+	// CHARGE: in case of charge that would result in player going bankrupt
+	baseInputs = layout.baseInputs
 
-var availableStdActionInputs = map[monopoly.StdAction]int{
-	monopoly.NOACTION:  95,
-	monopoly.MORTGAGE:  96,
-	monopoly.BUYOUT:    97,
-	monopoly.SELLOFFER: 98,
-	monopoly.BUYOFFER:  99,
-	monopoly.BUYHOUSE:  100,
-	monopoly.SELLHOUSE: 101,
-}
+	availableStdActionInputs  = layout.availableStdActionInputs
+	availableJailActionInputs = layout.availableJailActionInputs
+)
 
 type DecisionContext int
 
@@ -197,28 +38,12 @@ const (
 	STD_ACTION
 )
 
-var outputs = map[string]int{
-	"BUY_DECISION":    0, // yes / no
-	"BID_DECISION":    1, // yes / no
-	"BUY_FROM_PLAYER": 2, // yes / no
-	"SELL_TO_PLAYER":  3, // yes / no
-
-	// standard actions; highest score is the result (if applicable)
-	"NO_ACTION":  4,
-	"MORTGAGE":   5,
-	"BUYOUT":     6,
-	"SELL_OFFER": 7,
-	"BUY_OFFER":  8,
-	"BUY_HOUSE":  9,
-	"SELL_HOUSE": 10,
-
-	// in case of sell offer; if player is included in the offer;
-	"PLAYER_1": 11, // yes / no
-	"PLAYER_2": 12, // yes / no
-	"PLAYER_3": 13, // yes / no
-
-	"PRICE": 14, // in case of price-related actions; normalized to 0.0 - 1.0, where 1.0 is MAX_MONEY
-}
+// BUY_DECISION, BID_DECISION, BUY_FROM_PLAYER, SELL_TO_PLAYER: yes / no
+// NO_ACTION - SELL_HOUSE: standard actions; highest score is the result (if applicable)
+// PLAYER_1 - PLAYER_3: in case of sell offer; if player is included in the offer; yes / no
+// PRICE: in case of price-related actions and the valuation in bidding; normalized to 0.0 - 1.0, where 1.0 is MAX_MONEY
+// ROLL_DICE, BAIL, USE_CARD: jail actions; highest score among the available ones is the result
+var outputs = layout.outputs
 
 func GetStdActionOutputValues(output []float64) map[monopoly.StdAction]float64 {
 	return map[monopoly.StdAction]float64{
@@ -233,6 +58,14 @@ func GetStdActionOutputValues(output []float64) map[monopoly.StdAction]float64 {
 
 }
 
+func GetJailActionOutputValues(output []float64) map[monopoly.JailAction]float64 {
+	return map[monopoly.JailAction]float64{
+		monopoly.ROLL_DICE: output[outputs["ROLL_DICE"]],
+		monopoly.BAIL:      output[outputs["BAIL"]],
+		monopoly.CARD:      output[outputs["USE_CARD"]],
+	}
+}
+
 func GetPlayerOutputValues(output []float64) map[int]float64 {
 	return map[int]float64{
 		1: output[outputs["PLAYER_1"]],
@@ -249,21 +82,22 @@ func GetPriceOutputValue(output []float64) int {
 type MonopolySensors []float64
 
 func NewMonopolySensors() MonopolySensors {
-	return make([]float64, 102)
+	return make([]float64, len(layout.Inputs))
 }
 
 func (s MonopolySensors) LoadState(state monopoly.GameState, playerID int) {
-	id := 0
+	owners := monopoly.Owners(state)
 	for index, player := range state.Players {
 		if index == playerID {
 			s.loadCurrentPlayerState(player)
 		} else {
+			id := getNewPlayerId(index, playerID)
 			s.loadPlayerState(id, player)
-			id++
+			s.loadOpponentDetails(id, player, state, owners, state.Players[playerID].CurrentPosition)
 		}
 	}
 	for idx, property := range state.Properties {
-		s.loadPropertyState(idx, property, playerID)
+		s.loadPropertyState(idx, property, playerID, state.Settings.MaxHouses)
 	}
 }
 
@@ -273,13 +107,25 @@ func (s MonopolySensors) loadPlayerState(id int, player *monopoly.Player) {
 	s[playerInputs[id]["MONEY"]] = normalize(player.Money, 0, cfg.MAX_MONEY, false)
 }
 
-func (s MonopolySensors) loadPropertyState(propertyId int, property *monopoly.Property, currPlayerId int) {
+// loadOpponentDetails loads where the opponent stands, counted in fields ahead of the player making the decision,
+// its jail status, the net worth deciding the game at the round limit and the number of its complete sets.
+func (s MonopolySensors) loadOpponentDetails(id int, player *monopoly.Player, state monopoly.GameState, owners []int, position int) {
+	distance := (player.CurrentPosition - position + cfg.LAST_FIELD_ID + 1) % (cfg.LAST_FIELD_ID + 1)
+	s[playerInputs[id]["POSITION"]] = normalize(distance, 0, cfg.LAST_FIELD_ID, false)
+	s[playerInputs[id]["IS_JAILED"]] = fromBool(player.IsJailed)
+	s[playerInputs[id]["ROUNDS_IN_JAIL"]] = normalize(player.RoundsInJail, 0, cfg.MAX_JAIL_ROUNDS, false)
+	s[playerInputs[id]["JAIL_CARDS"]] = normalize(player.JailCards, 0, cfg.MAX_JAIL_CARDS, false)
+	s[playerInputs[id]["NET_WORTH"]] = normalize(monopoly.LiquidationValue(state, player.ID), 0, cfg.MAX_MONEY, false)
+	s[playerInputs[id]["MONOPOLIES"]] = normalize(monopoly.Monopolies(player.ID, owners), 0, cfg.MAX_MONOPOLIES, false)
+}
+
+func (s MonopolySensors) loadPropertyState(propertyId int, property *monopoly.Property, currPlayerId int, maxHouses int) {
 	s[propertyInputs[propertyId]["IS_MORTGAGED"]] = fromBool(property.IsMortgaged)
 	if property.Owner != nil {
 		s[propertyInputs[propertyId]["OWNER"]] = normalize(getNewPlayerId(property.Owner.ID, currPlayerId), 0, cfg.LAST_PLAYER_ID, true)
 	}
 	if property.CanBuildHouse {
-		s[propertyInputs[propertyId]["HOUSES"]] = normalize(property.Houses, 0, cfg.MAX_HOUSES, false)
+		s[propertyInputs[propertyId]["HOUSES"]] = normalize(property.Houses, 0, maxHouses, false)
 	}
 }
 
@@ -289,6 +135,7 @@ func (s MonopolySensors) loadCurrentPlayerState(player *monopoly.Player) {
 	s[currPlayerInputs["POSITION"]] = normalize(player.CurrentPosition, 0, cfg.LAST_FIELD_ID, false)
 	s[currPlayerInputs["MONEY"]] = normalize(player.Money, 0, cfg.MAX_MONEY, false)
 	s[currPlayerInputs["JAIL_CARDS"]] = normalize(player.JailCards, 0, cfg.MAX_JAIL_CARDS, false)
+	s[currPlayerInputs["ROUNDS_IN_JAIL"]] = normalize(player.RoundsInJail, 0, cfg.MAX_JAIL_ROUNDS, false)
 }
 
 func (s MonopolySensors) LoadDecisionContext(ctx DecisionContext) {
@@ -318,6 +165,12 @@ func (s MonopolySensors) LoadAvailableStdActions(actions []monopoly.StdAction) {
 	}
 }
 
+func (s MonopolySensors) LoadAvailableJailActions(actions []monopoly.JailAction) {
+	for _, action := range actions {
+		s[availableJailActionInputs[action]] = 1.0
+	}
+}
+
 func fromBool(value bool) float64 {
 	if value {
 		return 1.0
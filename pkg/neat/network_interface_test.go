@@ -1,12 +1,58 @@
 package neatnetwork
 
 import (
+	"fmt"
+	cfg "monopoly/pkg/config"
 	"monopoly/pkg/monopoly"
+	"slices"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/yaricom/goNEAT/v4/neat/network"
 )
 
+func testState(players int, owners map[int]int) monopoly.GameState {
+	state := monopoly.GameState{Settings: cfg.NewGameSettings()}
+	for id := range players {
+		state.Players = append(state.Players, monopoly.NewPlayer(id, fmt.Sprint(id), 1500))
+	}
+	for _, field := range monopoly.GetBoard().Fields {
+		if field.PropertyIndex >= 0 {
+			state.Properties = append(state.Properties, monopoly.NewProperty(field.FieldIndex, field.PropertyIndex, field.Name, field.Price, field.HousePrice, field.HousePrice > 0, field.Set))
+		}
+	}
+	slices.SortFunc(state.Properties, func(a, b *monopoly.Property) int { return a.PropertyIndex - b.PropertyIndex })
+	for propertyId, owner := range owners {
+		state.Properties[propertyId].Owner = state.Players[owner]
+		state.Players[owner].Properties = append(state.Players[owner].Properties, propertyId)
+	}
+	return state
+}
+
+func TestLoadState(t *testing.T) {
+	state := testState(4, map[int]int{0: 0, 1: 0, 26: 3})
+	state.Players[0].Money = 500
+	state.Players[0].CurrentPosition = 35
+	state.Players[0].IsJailed = true
+	state.Players[0].JailCards = 1
+	state.Players[1].CurrentPosition = 30
+	state.Players[3].IsBankrupt = true
+	ms := NewMonopolySensors()
+	ms.LoadState(state, 1)
+
+	// players 0, 2 and 3 are the opponents 1, 2 and 3 of player 1
+	assert.InDelta(t, 0.25, ms[playerInputs[1]["MONEY"]], 0.0001)
+	assert.InDelta(t, 0.0, ms[playerInputs[3]["IS_ALIVE"]], 0.0001)
+	assert.InDelta(t, 5.0/39, ms[playerInputs[1]["POSITION"]], 0.0001, "Player 0 stands 5 fields ahead")
+	assert.InDelta(t, 10.0/39, ms[playerInputs[2]["POSITION"]], 0.0001, "Player 2 stands 10 fields ahead after GO")
+	assert.InDelta(t, 1.0, ms[playerInputs[1]["IS_JAILED"]], 0.0001)
+	assert.InDelta(t, 0.1, ms[playerInputs[1]["JAIL_CARDS"]], 0.0001)
+	assert.InDelta(t, float64(500+30+30)/2000, ms[playerInputs[1]["NET_WORTH"]], 0.0001)
+	assert.InDelta(t, 1.0/8, ms[playerInputs[1]["MONOPOLIES"]], 0.0001)
+	assert.InDelta(t, 0.0, ms[playerInputs[3]["MONOPOLIES"]], 0.0001)
+	assert.InDelta(t, 0.5, ms[propertyInputs[0]["OWNER"]], 0.0001, "The first opponent should not overwrite the property inputs")
+}
+
 func TestLoadPlayerState(t *testing.T) {
 	var tests = []struct {
 		id                   int
@@ -70,7 +116,7 @@ func TestLoadPropertyState(t *testing.T) {
 			Houses:        tt.houses,
 			CanBuildHouse: true,
 		}
-		ms.loadPropertyState(tt.id, &property, 0)
+		ms.loadPropertyState(tt.id, &property, 0, cfg.MAX_HOUSES)
 		assert.InDelta(t, tt.expectedOwnerValue, ms[tt.expectedOwnerInput], 0.0001)
 		assert.InDelta(t, tt.expectedIsMortgaged, ms[tt.expectedIsMortgagedInpt], 0.0001)
 		assert.InDelta(t, tt.expectedHousesValue, ms[tt.expectedHousesInput], 0.0001)
@@ -378,3 +424,76 @@ func TestGetPriceOutput(t *testing.T) {
 	price := GetPriceOutputValue(output)
 	assert.InDelta(t, 300, price, 0.0001)
 }
+
+func TestLoadAvailableJailActions(t *testing.T) {
+	var tests = []struct {
+		availableActions []monopoly.JailAction
+		expectedRollDice float64
+		expectedBail     float64
+		expectedCard     float64
+	}{
+		{[]monopoly.JailAction{monopoly.BAIL}, 0.0, 1.0, 0.0},
+		{[]monopoly.JailAction{monopoly.BAIL, monopoly.ROLL_DICE}, 1.0, 1.0, 0.0},
+		{[]monopoly.JailAction{monopoly.BAIL, monopoly.CARD, monopoly.ROLL_DICE}, 1.0, 1.0, 1.0},
+	}
+	for _, tt := range tests {
+		ms := NewMonopolySensors()
+		ms.LoadAvailableJailActions(tt.availableActions)
+		assert.InDelta(t, tt.expectedRollDice, ms[103], 0.0001)
+		assert.InDelta(t, tt.expectedBail, ms[104], 0.0001)
+		assert.InDelta(t, tt.expectedCard, ms[105], 0.0001)
+	}
+
+	ms := NewMonopolySensors()
+	player := monopoly.NewPlayer(0, "", 0)
+	player.RoundsInJail = 2
+	ms.loadCurrentPlayerState(player)
+	assert.InDelta(t, 0.6667, ms[102], 0.0001)
+}
+
+func TestGetJailOutputs(t *testing.T) {
+	output := make([]float64, 18)
+	output[15] = 0.1 // ROLL_DICE
+	output[16] = 0.2 // BAIL
+	output[17] = 0.3 // USE_CARD
+	jailActionValues := GetJailActionOutputValues(output)
+	assert.InDelta(t, 0.1, jailActionValues[monopoly.ROLL_DICE], 0.0001)
+	assert.InDelta(t, 0.2, jailActionValues[monopoly.BAIL], 0.0001)
+	assert.InDelta(t, 0.3, jailActionValues[monopoly.CARD], 0.0001)
+}
+
+func TestBaseGenomeJailDecision(t *testing.T) {
+	player, err := LoadNEATPlayer("../../genomes/base_genome.yaml")
+	if !assert.NoError(t, err) {
+		return
+	}
+	inputs := 0
+	for _, node := range player.organism.Genotype.Nodes {
+		if node.NeuronType == network.InputNeuron {
+			inputs++
+		}
+	}
+	assert.Equal(t, layout.Features, inputs)
+	assert.Equal(t, len(outputs), len(player.network.Outputs))
+
+	state := testState(2, nil)
+	available := []monopoly.JailAction{monopoly.BAIL, monopoly.ROLL_DICE}
+	assert.Contains(t, available, player.GetJailAction(0, state, available))
+	assert.Equal(t, monopoly.BAIL, player.GetJailAction(0, state, []monopoly.JailAction{monopoly.BAIL}))
+
+	// genomes without the jail outputs keep the fixed rule
+	trained, err := LoadNEATPlayer("../../genomes/trained")
+	if !assert.NoError(t, err) {
+		return
+	}
+	state.Round = 30
+	assert.Equal(t, monopoly.ROLL_DICE, trained.GetJailAction(0, state, available))
+}
+
+func TestNextBid(t *testing.T) {
+	assert.Equal(t, 110, nextBid(100, 300, 1500))
+	assert.Equal(t, 300, nextBid(290, 300, 1500))
+	assert.Equal(t, 0, nextBid(300, 300, 1500), "The bot should not bid over its valuation")
+	assert.Equal(t, 0, nextBid(100, 300, 100), "The bot should not bid more than it has")
+	assert.Equal(t, 10, nextBid(0, 2000, 1500))
+}
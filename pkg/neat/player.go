@@ -3,6 +3,8 @@ package neatnetwork
 import (
 	"errors"
 	"fmt"
+	"maps"
+	"monopoly/pkg/bots"
 	"monopoly/pkg/monopoly"
 	"slices"
 	"sync"
@@ -19,14 +21,14 @@ type MonopolyPlayer interface {
 	BuyFromPlayerDecision(player int, state monopoly.GameState, propertyId int, price int) bool
 	SellToPlayerDecision(player int, state monopoly.GameState, propertyId int, price int) bool
 	BiddingDecision(player int, state monopoly.GameState, propertyId int, currentPrice int, currentWinner int) int
-	AddScore(points int)
-	AddWin()
-	AddDraw()
-	GetWins() int
-	GetDraws() int
+	AddScore(points float64)
+	AddWin(share float64) // share of the game, see NEATPlayerGroup
+	AddDraw(share float64)
+	GetWins() float64
+	GetDraws() float64
 	GetName() string
 	GetId() int
-	GetScore() int
+	GetScore() float64
 	GetOrganism() *genetics.Organism
 }
 
@@ -34,10 +36,11 @@ type NEATMonopolyPlayer struct {
 	network   *network.Network
 	organism  *genetics.Organism
 	max_depth int
-	score     int
+	score     float64
 	mutex     sync.Mutex
-	wins      int
-	draws     int
+	wins      float64
+	draws     float64
+	features  bool // whether the network has the derived feature inputs
 }
 
 func NewNEATMonopolyPlayer(organism *genetics.Organism) (*NEATMonopolyPlayer, error) {
@@ -54,14 +57,40 @@ func NewNEATMonopolyPlayer(organism *genetics.Organism) (*NEATMonopolyPlayer, er
 		return nil, errors.New("Invalid network depth: " + fmt.Sprint(max_depth))
 	}
 
+	inputs, _ := countNodes(organism.Genotype)
 	return &NEATMonopolyPlayer{
 		network:   network,
 		organism:  organism,
 		max_depth: max_depth,
 		score:     0,
+		features:  inputs > layout.Features,
 	}, nil
 }
 
+const DEFAULT_GENOME = "./genomes/trained"
+
+func init() {
+	bots.Register("neat", func(opts bots.Options) (bots.Bot, error) {
+		if err := opts.Allow("path"); err != nil {
+			return nil, err
+		}
+		return LoadNEATPlayer(opts.String("path", DEFAULT_GENOME))
+	})
+}
+
+// LoadNEATPlayer creates a player from a genome file, e.g. a champion saved during training.
+func LoadNEATPlayer(filePath string) (*NEATMonopolyPlayer, error) {
+	genome, err := ReadGenome(filePath)
+	if err != nil {
+		return nil, err
+	}
+	organism, err := genetics.NewOrganism(0.0, genome, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create organism from genome: %w", err)
+	}
+	return NewNEATMonopolyPlayer(organism)
+}
+
 func (p *NEATMonopolyPlayer) GetName() string {
 	return fmt.Sprintf("Bot%d", p.organism.Genotype.Id)
 }
@@ -70,7 +99,7 @@ func (p *NEATMonopolyPlayer) GetId() int {
 	return p.organism.Genotype.Id
 }
 
-func (p *NEATMonopolyPlayer) GetScore() int {
+func (p *NEATMonopolyPlayer) GetScore() float64 {
 	p.mutex.Lock()
 	defer p.mutex.Unlock()
 	return p.score
@@ -99,9 +128,17 @@ func (p *NEATMonopolyPlayer) GetDecision(input []float64) []float64 {
 	return output
 }
 
+// loadState loads the game state and the derived features if the network has their inputs.
+func (p *NEATMonopolyPlayer) loadState(sensors MonopolySensors, state monopoly.GameState, player int) {
+	sensors.LoadState(state, player)
+	if p.features {
+		sensors.LoadFeatures(state, player)
+	}
+}
+
 func (p *NEATMonopolyPlayer) GetStdAction(player int, state monopoly.GameState, availableActions monopoly.FullActionList) monopoly.ActionDetails {
 	sensors := NewMonopolySensors()
-	sensors.LoadState(state, player)
+	p.loadState(sensors, state, player)
 	sensors.LoadDecisionContext(STD_ACTION)
 	if state.Charge > 0 {
 		sensors.LoadCharge(state.Charge)
@@ -110,7 +147,9 @@ func (p *NEATMonopolyPlayer) GetStdAction(player int, state monopoly.GameState,
 
 	var result monopoly.ActionDetails
 	propertyActions := transformAvailableActionsList(availableActions)
-	for propertyId, availableActions := range propertyActions {
+	// the properties are tried in the order of their ids, not in the random order of the map
+	for _, propertyId := range slices.Sorted(maps.Keys(propertyActions)) {
+		availableActions := propertyActions[propertyId]
 		sensors.LoadAvailableStdActions(availableActions)
 		sensors.LoadPropertyId(propertyId)
 		sensors.LoadPrice(state.Properties[propertyId].Price)
@@ -134,8 +173,8 @@ func (p *NEATMonopolyPlayer) GetStdAction(player int, state monopoly.GameState,
 
 			playerOutputs := GetPlayerOutputValues(outputList)
 			result.Players = []int{}
-			for pID, val := range playerOutputs {
-				if val > 0.5 {
+			for _, pID := range slices.Sorted(maps.Keys(playerOutputs)) {
+				if playerOutputs[pID] > 0.5 {
 					result.Players = append(result.Players, getOriginalPlayerId(pID, player))
 				}
 			}
@@ -153,6 +192,28 @@ func (p *NEATMonopolyPlayer) GetStdAction(player int, state monopoly.GameState,
 }
 
 func (p *NEATMonopolyPlayer) GetJailAction(player int, state monopoly.GameState, available []monopoly.JailAction) monopoly.JailAction {
+	sensors := NewMonopolySensors()
+	p.loadState(sensors, state, player)
+	sensors.LoadDecisionContext(JAIL_DECISION)
+	sensors.LoadAvailableJailActions(available)
+	outputList := p.GetDecision(sensors)
+	if len(outputList) <= outputs["USE_CARD"] {
+		// genomes trained before the jail outputs were added
+		return fixedJailAction(state, available)
+	}
+
+	jailActionOutValues := GetJailActionOutputValues(outputList)
+	result := available[0]
+	for _, action := range available {
+		if jailActionOutValues[action] > jailActionOutValues[result] {
+			result = action
+		}
+	}
+	return result
+}
+
+// fixedJailAction leaves jail early in the game and stays there later on.
+func fixedJailAction(state monopoly.GameState, available []monopoly.JailAction) monopoly.JailAction {
 	if !slices.Contains(available, monopoly.ROLL_DICE) {
 		if slices.Contains(available, monopoly.CARD) {
 			return monopoly.CARD
@@ -170,7 +231,7 @@ func (p *NEATMonopolyPlayer) GetJailAction(player int, state monopoly.GameState,
 
 func (p *NEATMonopolyPlayer) BuyDecision(player int, state monopoly.GameState, propertyId int) bool {
 	sensors := NewMonopolySensors()
-	sensors.LoadState(state, player)
+	p.loadState(sensors, state, player)
 	sensors.LoadDecisionContext(BUY_DECISION)
 	sensors.LoadPropertyId(propertyId)
 	sensors.LoadPrice(state.Properties[propertyId].Price)
@@ -180,7 +241,7 @@ func (p *NEATMonopolyPlayer) BuyDecision(player int, state monopoly.GameState, p
 
 func (p *NEATMonopolyPlayer) BuyFromPlayerDecision(player int, state monopoly.GameState, propertyId int, price int) bool {
 	sensors := NewMonopolySensors()
-	sensors.LoadState(state, player)
+	p.loadState(sensors, state, player)
 	sensors.LoadDecisionContext(BUY_FROM_PLAYER)
 	sensors.LoadPropertyId(propertyId)
 	sensors.LoadPrice(price)
@@ -191,7 +252,7 @@ func (p *NEATMonopolyPlayer) BuyFromPlayerDecision(player int, state monopoly.Ga
 
 func (p *NEATMonopolyPlayer) SellToPlayerDecision(player int, state monopoly.GameState, propertyId int, price int) bool {
 	sensors := NewMonopolySensors()
-	sensors.LoadState(state, player)
+	p.loadState(sensors, state, player)
 	sensors.LoadDecisionContext(SELL_TO_PLAYER)
 	sensors.LoadPropertyId(propertyId)
 	sensors.LoadPrice(price)
@@ -200,19 +261,29 @@ func (p *NEATMonopolyPlayer) SellToPlayerDecision(player int, state monopoly.Gam
 	return outputList[outputs["SELL_TO_PLAYER"]] > 0.5
 }
 
+// BiddingDecision reads the valuation of the property from the PRICE output, the bot keeps
+// raising by the smallest step while BID_DECISION is on and the price stays within the valuation.
 func (p *NEATMonopolyPlayer) BiddingDecision(player int, state monopoly.GameState, propertyId int, currentPrice int, currentWinner int) int {
 	sensors := NewMonopolySensors()
-	sensors.LoadState(state, player)
+	p.loadState(sensors, state, player)
 	sensors.LoadDecisionContext(BIDDING_DECISION)
 	sensors.LoadPropertyId(propertyId)
-	sensors.LoadPrice(state.Properties[propertyId].Price)
+	sensors.LoadPrice(currentPrice)
 	sensors.LoadBiddingInputs(currentPrice, currentWinner, player)
 	outputList := p.GetDecision(sensors)
-	decision := outputList[outputs["BIDDING_DECISION"]] > 0.5
-	if !decision {
-		return 0.0
+	if outputList[outputs["BID_DECISION"]] <= 0.5 {
+		return 0
+	}
+	return nextBid(currentPrice, GetPriceOutputValue(outputList), state.Players[player].Money)
+}
+
+// nextBid returns the smallest raise of the current price, 0 if it exceeds the valuation or the money.
+func nextBid(currentPrice int, valuation int, money int) int {
+	bid := currentPrice + bots.BID_STEP
+	if bid > valuation || bid > money {
+		return 0
 	}
-	return currentPrice + 10
+	return bid
 }
 
 func transformAvailableActionsList(actions monopoly.FullActionList) map[int][]monopoly.StdAction {
@@ -248,30 +319,30 @@ func transformAvailableActionsList(actions monopoly.FullActionList) map[int][]mo
 	return propertyActions
 }
 
-func (p *NEATMonopolyPlayer) AddScore(points int) {
+func (p *NEATMonopolyPlayer) AddScore(points float64) {
 	p.mutex.Lock()
 	p.score += points
 	p.mutex.Unlock()
 }
 
-func (p *NEATMonopolyPlayer) AddWin() {
+func (p *NEATMonopolyPlayer) AddWin(share float64) {
 	p.mutex.Lock()
-	p.wins++
+	p.wins += share
 	p.mutex.Unlock()
 }
 
-func (p *NEATMonopolyPlayer) AddDraw() {
+func (p *NEATMonopolyPlayer) AddDraw(share float64) {
 	p.mutex.Lock()
-	p.draws++
+	p.draws += share
 	p.mutex.Unlock()
 }
 
-func (p *NEATMonopolyPlayer) GetWins() int {
+func (p *NEATMonopolyPlayer) GetWins() float64 {
 	p.mutex.Lock()
 	defer p.mutex.Unlock()
 	return p.wins
 }
-func (p *NEATMonopolyPlayer) GetDraws() int {
+func (p *NEATMonopolyPlayer) GetDraws() float64 {
 	p.mutex.Lock()
 	defer p.mutex.Unlock()
 	return p.draws
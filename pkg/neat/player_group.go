@@ -2,7 +2,6 @@ package neatnetwork
 
 import (
 	"fmt"
-	cfg "monopoly/pkg/config"
 	"monopoly/pkg/monopoly"
 )
 
@@ -10,9 +9,14 @@ type NEATPlayerGroup struct {
 	Id           int
 	players      []MonopolyPlayer
 	gameFinished bool
+	fitness      *Fitness
+	weight       float64 // share of the game in the round, a group played in several seatings splits the scores, wins and draws of a game
+	finish       monopoly.FinishOption
+	winner       int
+	rounds       int
 }
 
-func NewNEATPlayerGroup(id int, players []MonopolyPlayer) (*NEATPlayerGroup, error) {
+func NewNEATPlayerGroup(id int, players []MonopolyPlayer, fitness *Fitness) (*NEATPlayerGroup, error) {
 	if len(players) <= 0 || len(players) > 4 {
 		errorMsg := fmt.Sprintf("Invalid number of players: %d. Expected between 1 and 4.", len(players))
 		return nil, fmt.Errorf(errorMsg)
@@ -21,6 +25,8 @@ func NewNEATPlayerGroup(id int, players []MonopolyPlayer) (*NEATPlayerGroup, err
 		Id:           id,
 		players:      players,
 		gameFinished: false,
+		fitness:      fitness,
+		weight:       1,
 	}, nil
 }
 
@@ -87,37 +93,24 @@ func (t *NEATPlayerGroup) BiddingDecision(player int, state monopoly.GameState,
 }
 
 func (t *NEATPlayerGroup) Finish(f monopoly.FinishOption, winner int, state monopoly.GameState) {
-	pointsMap := map[int]int{
-		0: 0,
-		1: 0,
-		2: 0,
-		3: 0,
-	}
-	if t.gameFinished {
+	if t.gameFinished || f == monopoly.CANCELLED {
 		return
 	}
 	t.gameFinished = true
-	for i, player := range state.Players {
-		pointsMap[i] += cfg.ROUND_SCORE * player.RoundsPlayed
-		// fmt.Printf("Max properties: %d", player.MaxProperties)
-		pointsMap[i] += player.MaxProperties * cfg.POINTS_PER_PROPERTY
-		pointsMap[i] += player.MaxHouses * cfg.POINTS_PER_HOUSE
-		if !player.IsBankrupt {
-			pointsMap[i] += cfg.ALIVE_BONUS
-		}
-	}
+	t.finish = f
+	t.winner = winner
+	t.rounds = state.Round
 	if f == monopoly.WIN {
-		pointsMap[winner] = cfg.FIRST_PLACE_SCORE
-		t.players[winner].AddWin()
+		t.players[winner].AddWin(t.weight)
 	}
 	if f == monopoly.ROUND_LIMIT {
 		for i, p := range state.Players {
 			if !p.IsBankrupt {
-				t.players[i].AddDraw()
+				t.players[i].AddDraw(t.weight)
 			}
 		}
 	}
-	for i, p := range t.players {
-		p.AddScore(pointsMap[i])
+	for i, variables := range GameVariables(f, winner, state) {
+		t.players[i].AddScore(t.weight * t.fitness.Score(variables))
 	}
 }
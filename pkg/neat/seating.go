@@ -0,0 +1,65 @@
+package neatnetwork
+
+// Seatings of the evaluation groups. The first seat moves first, so a group played in a single order
+// hands someone the advantage of the first move. The other seatings play every group in several orders
+// with the same dice (duplicate games), so that the luck of the seat and of the dice cancels out.
+const (
+	SEATING_RANDOM    = "random"    // one game in the order of the shuffled group
+	SEATING_ROTATIONS = "rotations" // every player takes every seat once, the neighbours stay the same
+	SEATING_BALANCED  = "balanced"  // every player takes every seat and follows every other player equally often
+)
+
+var Seatings = []string{SEATING_RANDOM, SEATING_ROTATIONS, SEATING_BALANCED}
+
+// seatOrders returns the orders a group of the size plays in, every order lists the group indexes of the
+// players by seat.
+func seatOrders(seating string, size int) [][]int {
+	switch seating {
+	case SEATING_ROTATIONS:
+		return rotations(identityOrder(size))
+	case SEATING_BALANCED:
+		// Williams design: 0, 1, n-1, 2, n-2, ... and its rotations, for odd sizes also the reversed orders
+		first := make([]int, size)
+		for i := 1; i < size; i++ {
+			if i%2 == 1 {
+				first[i] = (i + 1) / 2
+			} else {
+				first[i] = size - i/2
+			}
+		}
+		orders := rotations(first)
+		if size%2 == 1 {
+			for _, order := range rotations(first) {
+				reversed := make([]int, size)
+				for seat, player := range order {
+					reversed[size-1-seat] = player
+				}
+				orders = append(orders, reversed)
+			}
+		}
+		return orders
+	}
+	return [][]int{identityOrder(size)}
+}
+
+func identityOrder(size int) []int {
+	order := make([]int, size)
+	for i := range order {
+		order[i] = i
+	}
+	return order
+}
+
+// rotations shifts every player of the order by 0 to size-1 places.
+func rotations(first []int) [][]int {
+	size := len(first)
+	var orders [][]int
+	for shift := range size {
+		order := make([]int, size)
+		for seat, player := range first {
+			order[seat] = (player + shift) % size
+		}
+		orders = append(orders, order)
+	}
+	return orders
+}
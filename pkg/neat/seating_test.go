@@ -0,0 +1,78 @@
+package neatnetwork
+
+import (
+	"monopoly/pkg/monopoly"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSeatOrders(t *testing.T) {
+	for size := 2; size <= 4; size++ {
+		assert.Equal(t, [][]int{identityOrder(size)}, seatOrders(SEATING_RANDOM, size))
+
+		for _, seating := range []string{SEATING_ROTATIONS, SEATING_BALANCED} {
+			orders := seatOrders(seating, size)
+			// every player takes every seat equally often
+			seats := make([][]int, size)
+			for i := range seats {
+				seats[i] = make([]int, size)
+			}
+			for _, order := range orders {
+				assert.ElementsMatch(t, identityOrder(size), order, "%s %v", seating, order)
+				for seat, player := range order {
+					seats[player][seat]++
+				}
+			}
+			for player := range seats {
+				for seat := range seats[player] {
+					assert.Equal(t, len(orders)/size, seats[player][seat], "%s: player %d in seat %d", seating, player, seat)
+				}
+			}
+		}
+
+		// in the balanced orders every player follows every other player equally often
+		orders := seatOrders(SEATING_BALANCED, size)
+		follows := map[[2]int]int{}
+		for _, order := range orders {
+			for seat := 1; seat < size; seat++ {
+				follows[[2]int{order[seat-1], order[seat]}]++
+			}
+		}
+		assert.Len(t, follows, size*(size-1), "size %d", size)
+		for pair, count := range follows {
+			assert.Equal(t, len(orders)/size, count, "size %d: %d after %d", size, pair[1], pair[0])
+		}
+	}
+	assert.Len(t, seatOrders(SEATING_ROTATIONS, 3), 3)
+	assert.Len(t, seatOrders(SEATING_BALANCED, 3), 6)
+	assert.Len(t, seatOrders(SEATING_BALANCED, 4), 4)
+}
+
+func TestSeatingsShareTheGame(t *testing.T) {
+	fitness, err := ReadFitness("../../fitness.yaml")
+	if !assert.NoError(t, err) {
+		return
+	}
+	players := make([]MonopolyPlayer, 2)
+	for i := range players {
+		players[i], err = NewBotPlayer("random")
+		if !assert.NoError(t, err) {
+			return
+		}
+	}
+	// a group played in two seatings: player 0 wins one game, the other ends at the round limit
+	state := testState(2, map[int]int{})
+	for _, f := range []monopoly.FinishOption{monopoly.WIN, monopoly.ROUND_LIMIT} {
+		group, err := NewNEATPlayerGroup(0, players, fitness)
+		if !assert.NoError(t, err) {
+			return
+		}
+		group.weight = 0.5
+		group.Finish(f, 0, state)
+	}
+	assert.Equal(t, 0.5, players[0].GetWins())
+	assert.Equal(t, 0.5, players[0].GetDraws())
+	assert.Equal(t, 0.0, players[1].GetWins())
+	assert.Equal(t, 0.5, players[1].GetDraws())
+}
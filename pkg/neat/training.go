@@ -2,68 +2,112 @@ package neatnetwork
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"math/rand"
-	cfg "monopoly/pkg/config"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"syscall"
+	"time"
 
 	"github.com/yaricom/goNEAT/v4/experiment"
 	"github.com/yaricom/goNEAT/v4/neat"
 	"github.com/yaricom/goNEAT/v4/neat/genetics"
 )
 
-func TrainNetwork(seed int64, neatOptionsFile string, genomeFile string, outputDir string) {
-	rng := rand.New(rand.NewSource(seed))
-	neatOptions, err := neat.ReadNeatOptionsFromFile(neatOptionsFile)
+// TrainNetwork runs the training experiment described by the config file, see ReadTrainingConfig.
+func TrainNetwork(configFile string) {
+	config, err := ReadTrainingConfig(configFile)
+	if err != nil {
+		log.Fatal("Failed to load training config:", err)
+	}
+	train(config, false)
+}
+
+// ResumeTraining continues the experiment saved in the output directory from its last checkpoint,
+// with the training config saved next to it.
+func ResumeTraining(outputDir string) {
+	config, err := ReadTrainingConfig(filepath.Join(outputDir, "training.yaml"))
+	if err != nil {
+		log.Fatal("Failed to load training config:", err)
+	}
+	config.OutputDir = outputDir
+	train(config, true)
+}
+
+func train(config TrainingConfig, resume bool) {
+	neatOptions, err := neat.ReadNeatOptionsFromFile(config.NeatOptions)
 	if err != nil {
 		log.Fatal("Failed to load NEAT options:", err)
 	}
-	genomeReader, err := genetics.NewGenomeReaderFromFile(genomeFile)
+	fitness, err := ReadFitness(config.Fitness)
 	if err != nil {
-		log.Fatal("Failed to create genome reader:", err)
+		log.Fatal("Failed to load fitness:", err)
+	}
+	var resumeFrom *checkpoint
+	if resume {
+		resumeFrom, err = loadCheckpoint(config.OutputDir, neatOptions)
+		if err != nil {
+			log.Fatal("Failed to load checkpoint:", err)
+		}
 	}
-	startGenome, err := genomeReader.Read()
+	startGenome, err := ReadGenome(config.StartGenome)
 	if err != nil {
 		log.Fatal("Failed to read start genome:", err)
 	}
+	if config.DerivedFeatures {
+		startGenome, err = AddFeatureInputs(startGenome)
+		if err != nil {
+			log.Fatal("Failed to add the derived feature inputs:", err)
+		}
+	}
 
-	err = os.MkdirAll(outputDir, os.ModePerm)
+	err = os.MkdirAll(config.OutputDir, os.ModePerm)
 	if err != nil {
 		log.Fatal("Failed to create output directory:", err)
 	}
+	if err = config.Save(); err != nil {
+		log.Fatal("Failed to save training config:", err)
+	}
 	exp := experiment.Experiment{
 		Id:       0,
-		Trials:   make(experiment.Trials, neatOptions.NumRuns),
-		RandSeed: seed,
+		RandSeed: config.Seed,
+	}
+	evaluator := NewMonopolyEvaluator(config, fitness)
+	if resumeFrom != nil {
+		if err := evaluator.metrics.load(resumeFrom.state.Trial, resumeFrom.state.Generation); err != nil {
+			log.Fatal("Failed to load metrics:", err)
+		}
 	}
-	evaluator := NewMonopolyEvaluator(outputDir, cfg.GROUP_SIZE, rng)
 	errChan := make(chan error)
 	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if config.Logging.MetricsAddr != "" {
+		go evaluator.metrics.serve(ctx, config.Logging.MetricsAddr)
+	}
 
 	go func() {
-		err = exp.Execute(neat.NewContext(ctx, neatOptions), startGenome, evaluator, nil)
-		if err != nil {
-			errChan <- err
-		} else {
-			errChan <- nil
-		}
+		errChan <- runExperiment(neat.NewContext(ctx, neatOptions), &exp, startGenome, evaluator, resumeFrom)
 	}()
 
-	go func(cancel context.CancelFunc) {
+	go func() {
 		fmt.Println("Press ctrl+C to stop the experiment...")
 		signalChan := make(chan os.Signal, 1)
 		signal.Notify(signalChan, syscall.SIGINT, syscall.SIGTERM, syscall.SIGQUIT, syscall.SIGHUP)
 		select {
 		case <-signalChan:
+			fmt.Printf("Stopping, continue the experiment with --resume %s\n", config.OutputDir)
 			cancel()
-		case <-errChan:
-			return
+		case <-ctx.Done():
 		}
-	}(cancel)
+	}()
 	err = <-errChan
+	if errors.Is(err, context.Canceled) {
+		fmt.Println("Experiment stopped.")
+		return
+	}
 	if err != nil {
 		log.Fatal("Experiment failed:", err)
 	}
@@ -74,3 +118,107 @@ func TrainNetwork(seed int64, neatOptionsFile string, genomeFile string, outputD
 		fmt.Printf("Best organism found in epoch %d: ID %d with fitness %f\n", epoch, best.Genotype.Id, best.Fitness)
 	}
 }
+
+// runExperiment follows experiment.Execute, but it saves checkpoints between the generations and can
+// start from one. The generations played before the checkpoint are not in the statistics of the experiment.
+// The reproduction of goNEAT draws from the global random source of math/rand and cannot be given one, so the
+// global source is seeded for every population and epoch from the seed of the experiment, see seedReproduction.
+// With the sequential epoch executor the same config evolves the same populations, a resumed experiment only
+// numbers its new nodes and genes after the ones of its population.
+func runExperiment(ctx context.Context, exp *experiment.Experiment, startGenome *genetics.Genome, evaluator *MonopolyEvaluator, resumeFrom *checkpoint) error {
+	opts, found := neat.FromContext(ctx)
+	if !found {
+		return neat.ErrNEATOptionsNotFound
+	}
+	if !seedReproduction(evaluator.config.Seed) {
+		neat.WarnLog("The global random source cannot be seeded (GODEBUG=randseednop=1), the reproduction will not be repeatable")
+	}
+	firstRun := 0
+	if resumeFrom != nil {
+		firstRun = resumeFrom.state.Trial
+	}
+	for run := firstRun; run < opts.NumRuns; run++ {
+		trialStartTime := time.Now()
+		var pop *genetics.Population
+		firstGeneration := 0
+		if resumeFrom != nil && run == resumeFrom.state.Trial {
+			neat.InfoLog(fmt.Sprintf(">>>>> Resuming run %d from generation %d", run, resumeFrom.state.Generation))
+			pop = resumeFrom.population
+			firstGeneration = resumeFrom.state.Generation
+			evaluator.lastChampion = resumeFrom.lastChampion
+			evaluator.lastChampFitness = resumeFrom.state.LastChampFitness
+		} else {
+			neat.InfoLog(">>>>> Spawning new population")
+			seedReproduction(deriveSeed(evaluator.config.Seed, run))
+			var err error
+			pop, err = genetics.NewPopulation(startGenome, opts)
+			if err != nil {
+				return err
+			}
+			evaluator.lastChampion = nil
+			evaluator.lastChampFitness = 0
+		}
+		if _, err := pop.Verify(); err != nil {
+			return err
+		}
+		evaluator.hallOfFame = nil
+		if hallOfFame := evaluator.config.Opponents.HallOfFame; hallOfFame.Share > 0 {
+			evaluator.hallOfFame = newHallOfFame(evaluator.config.OutputDir, run, hallOfFame.Size)
+			if err := evaluator.hallOfFame.load(firstGeneration); err != nil {
+				return err
+			}
+		}
+
+		var epochExecutor genetics.PopulationEpochExecutor = &genetics.SequentialPopulationEpochExecutor{}
+		if opts.EpochExecutorType == neat.EpochExecutorTypeParallel {
+			epochExecutor = &genetics.ParallelPopulationEpochExecutor{}
+		}
+		trial := experiment.Trial{Id: run}
+		for generationId := firstGeneration; generationId < opts.NumGenerations; generationId++ {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			default:
+			}
+			if generationId != firstGeneration && generationId%evaluator.config.Logging.CheckpointEvery == 0 {
+				if err := evaluator.saveCheckpoint(pop, run, generationId); err != nil {
+					return fmt.Errorf("failed to save checkpoint: %w", err)
+				}
+			}
+
+			neat.InfoLog(fmt.Sprintf(">>>>> Generation:%3d\tRun: %d\n", generationId, run))
+			generation := experiment.Generation{
+				Id:      generationId,
+				TrialId: run,
+			}
+			genStartTime := time.Now()
+			if err := evaluator.GenerationEvaluate(ctx, pop, &generation); err != nil {
+				return err
+			}
+			generation.Executed = time.Now()
+			if !generation.Solved {
+				seedReproduction(deriveSeed(evaluator.config.Seed, run, generationId))
+				if err := epochExecutor.NextEpoch(ctx, generationId, pop); err != nil {
+					return err
+				}
+			}
+			generation.Duration = generation.Executed.Sub(genStartTime)
+			trial.Generations = append(trial.Generations, generation)
+			if generation.Solved {
+				break
+			}
+		}
+		trial.Duration = time.Since(trialStartTime)
+		exp.Trials = append(exp.Trials, trial)
+	}
+	return nil
+}
+
+// seedReproduction seeds the global random source used by the reproduction of goNEAT and reports whether it
+// took the seed. rand.Seed is a no-op with GODEBUG=randseednop=1, the default of modules from go 1.24 on.
+func seedReproduction(seed int64) bool {
+	rand.Seed(seed)
+	seeded := rand.Int63() == rand.New(rand.NewSource(seed)).Int63()
+	rand.Seed(seed)
+	return seeded
+}
@@ -0,0 +1,164 @@
+package neatnetwork
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	cfg "monopoly/pkg/config"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// TrainingConfig describes a training experiment, see training.yaml. Settings left out of the file keep
+// the defaults of DefaultTrainingConfig. The resolved config is saved to the output directory of the experiment.
+type TrainingConfig struct {
+	Seed            int64             `yaml:"seed"`
+	NeatOptions     string            `yaml:"neat_options"`     // goNEAT options, e.g. the population size and the number of epochs
+	StartGenome     string            `yaml:"start_genome"`     // genome the first population is created from
+	Fitness         string            `yaml:"fitness"`          // fitness components, see ReadFitness
+	OutputDir       string            `yaml:"output_dir"`       // populations, champions and game logs are saved here
+	DerivedFeatures bool              `yaml:"derived_features"` // whether the start genome gets the derived feature inputs, e.g. set completion and rents
+	Evaluation      EvaluationConfig  `yaml:"evaluation"`
+	Game            cfg.GameSettings  `yaml:"game"`
+	Opponents       OpponentsConfig   `yaml:"opponents"`
+	Logging         TrainingLogConfig `yaml:"logging"`
+}
+
+type EvaluationConfig struct {
+	GamesPerEpoch int    `yaml:"games_per_epoch"` // number of games every organism has to play during one epoch
+	GroupSize     int    `yaml:"group_size"`      // number of players in each game
+	Threads       int    `yaml:"threads"`         // maximum number of threads used to evaluate organisms
+	Seating       string `yaml:"seating"`         // seat orders every group plays in with the same dice, see Seatings
+}
+
+// OpponentsConfig sets the bots and the past champions which play in the groups next to the organisms.
+type OpponentsConfig struct {
+	Bots       []string         `yaml:"bots"`  // specs of the bots, see bots.New; every bot seat takes one of them at random
+	Seats      int              `yaml:"seats"` // number of seats in every group taken by the bots
+	HallOfFame HallOfFameConfig `yaml:"hall_of_fame"`
+}
+
+// HallOfFameConfig sets the seats taken by the champions of past epochs, see HallOfFame.
+type HallOfFameConfig struct {
+	Share float64 `yaml:"share"` // fraction of the seats of every group taken by the champions, 0 disables the hall of fame
+	Size  int     `yaml:"size"`  // number of latest champions kept, 0 keeps all
+}
+
+// Seats returns the number of seats of a group taken by the champions.
+func (c HallOfFameConfig) Seats(groupSize int) int {
+	return int(math.Round(c.Share * float64(groupSize)))
+}
+
+type TrainingLogConfig struct {
+	PrintEvery      int    `yaml:"print_every"`      // saves game logs and the population every N epochs
+	LoggedGames     int    `yaml:"logged_games"`     // number of rounds of games logged in the last epoch
+	CheckpointEvery int    `yaml:"checkpoint_every"` // saves a checkpoint to resume the training from every N epochs
+	MetricsAddr     string `yaml:"metrics_addr"`     // address of the HTTP endpoint serving the metrics of the epochs, empty for none
+}
+
+func DefaultTrainingConfig() TrainingConfig {
+	return TrainingConfig{
+		Seed:        0,
+		NeatOptions: "neat_options.yaml",
+		StartGenome: "./genomes/base_genome.yaml",
+		Fitness:     "fitness.yaml",
+		OutputDir:   "output",
+		Evaluation: EvaluationConfig{
+			GamesPerEpoch: 1000,
+			GroupSize:     4,
+			Threads:       200,
+			Seating:       SEATING_RANDOM,
+		},
+		Game: cfg.NewGameSettings(),
+		Opponents: OpponentsConfig{
+			Bots: []string{"heuristic"},
+			HallOfFame: HallOfFameConfig{
+				Share: 0,
+				Size:  50,
+			},
+		},
+		Logging: TrainingLogConfig{
+			PrintEvery:      50,
+			LoggedGames:     10,
+			CheckpointEvery: 1,
+		},
+	}
+}
+
+// ReadTrainingConfig reads a training config file over the defaults.
+func ReadTrainingConfig(path string) (TrainingConfig, error) {
+	config := DefaultTrainingConfig()
+	file, err := os.Open(path)
+	if err != nil {
+		return config, err
+	}
+	defer file.Close()
+	decoder := yaml.NewDecoder(file)
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&config); err != nil {
+		return config, fmt.Errorf("failed to read training config %s: %w", path, err)
+	}
+	if err := config.validate(); err != nil {
+		return config, fmt.Errorf("invalid training config %s: %w", path, err)
+	}
+	return config, nil
+}
+
+func (c TrainingConfig) validate() error {
+	switch {
+	case c.Evaluation.GamesPerEpoch <= 0:
+		return errors.New("games_per_epoch has to be positive")
+	case c.Evaluation.GroupSize < 2 || c.Evaluation.GroupSize > cfg.MAX_PLAYERS:
+		return fmt.Errorf("group_size has to be between 2 and %d", cfg.MAX_PLAYERS)
+	case c.Evaluation.Threads <= 0:
+		return errors.New("threads has to be positive")
+	case !slices.Contains(Seatings, c.Evaluation.Seating):
+		return fmt.Errorf("seating has to be one of %s", strings.Join(Seatings, ", "))
+	case c.Opponents.Seats < 0 || c.Opponents.Seats >= c.Evaluation.GroupSize:
+		return errors.New("opponent seats have to leave at least one seat of a group to the organisms")
+	case c.Opponents.Seats > 0 && len(c.Opponents.Bots) == 0:
+		return errors.New("opponent seats need at least one bot")
+	case c.Opponents.HallOfFame.Share < 0 || c.Opponents.HallOfFame.Share > 1:
+		return errors.New("hall of fame share has to be between 0 and 1")
+	case c.Opponents.HallOfFame.Size < 0:
+		return errors.New("hall of fame size cannot be negative")
+	case c.Opponents.HallOfFame.Share > 0 && c.Opponents.HallOfFame.Seats(c.Evaluation.GroupSize) == 0:
+		return errors.New("hall of fame share is too small to take a seat of a group")
+	case c.Opponents.Seats+c.Opponents.HallOfFame.Seats(c.Evaluation.GroupSize) >= c.Evaluation.GroupSize:
+		return errors.New("opponent and hall of fame seats have to leave at least one seat of a group to the organisms")
+	case c.Logging.PrintEvery <= 0:
+		return errors.New("print_every has to be positive")
+	case c.Logging.CheckpointEvery <= 0:
+		return errors.New("checkpoint_every has to be positive")
+	case c.Game.MaxRounds <= 0:
+		return errors.New("max_rounds has to be positive")
+	case c.Game.JailPosition != cfg.JAIL_POSITION:
+		// the jail square is a field of the board, the game only sends the players there
+		return fmt.Errorf("jail_position cannot be changed from %d", cfg.JAIL_POSITION)
+	case c.Game.MaxHouses < 1 || c.Game.MaxHouses > cfg.MAX_HOUSES:
+		return fmt.Errorf("max_houses has to be between 1 and %d, the rents of the board", cfg.MAX_HOUSES)
+	case c.Game.MaxStdActionsPerTurn <= 0:
+		return errors.New("max_std_actions_per_turn has to be positive")
+	case c.Game.StartPassMoney < 0 || c.Game.JailBail < 0 || c.Game.MinPrice < 0 || c.Game.MaxOfferTries < 0:
+		return errors.New("start_pass_money, jail_bail, min_price and max_offer_tries cannot be negative")
+	}
+	for _, spec := range c.Opponents.Bots {
+		if _, err := NewBotPlayer(spec); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Save writes the config to the output directory, next to the results of the experiment.
+func (c TrainingConfig) Save() error {
+	data, err := yaml.Marshal(c)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(c.OutputDir, "training.yaml"), data, 0644)
+}
@@ -0,0 +1,126 @@
+package neatnetwork
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yaricom/goNEAT/v4/neat"
+)
+
+func TestReadTrainingConfig(t *testing.T) {
+	config, err := ReadTrainingConfig("../../training.yaml")
+	if assert.NoError(t, err) {
+		assert.Equal(t, DefaultTrainingConfig(), config, "The shipped config should hold the defaults")
+	}
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "training.yaml")
+	content := "seed: 7\nevaluation:\n  group_size: 3\ngame:\n  max_rounds: 20\nopponents:\n  bots: [random, heuristic]\n  seats: 1\n"
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	config, err = ReadTrainingConfig(path)
+	if assert.NoError(t, err) {
+		assert.Equal(t, int64(7), config.Seed)
+		assert.Equal(t, 3, config.Evaluation.GroupSize)
+		assert.Equal(t, DefaultTrainingConfig().Evaluation.GamesPerEpoch, config.Evaluation.GamesPerEpoch)
+		assert.Equal(t, 20, config.Game.MaxRounds)
+		assert.Equal(t, DefaultTrainingConfig().Game.JailBail, config.Game.JailBail)
+		assert.Equal(t, []string{"random", "heuristic"}, config.Opponents.Bots)
+	}
+
+	config.OutputDir = dir
+	assert.NoError(t, config.Save())
+	saved, err := ReadTrainingConfig(filepath.Join(dir, "training.yaml"))
+	if assert.NoError(t, err) {
+		assert.Equal(t, config, saved)
+	}
+
+	for name, content := range map[string]string{
+		"unknown_field": "evaluation:\n  games: 10\n",
+		"group_size":    "evaluation:\n  group_size: 5\n",
+		"all_bots":      "evaluation:\n  group_size: 2\nopponents:\n  seats: 2\n",
+		"no_bots":       "opponents:\n  bots: []\n  seats: 1\n",
+		"unknown_bot":   "opponents:\n  bots: [nobody]\n",
+		"seating":       "evaluation:\n  seating: shuffled\n",
+		"share":         "opponents:\n  hall_of_fame:\n    share: 1.5\n",
+		"all_champions": "evaluation:\n  group_size: 4\nopponents:\n  seats: 2\n  hall_of_fame:\n    share: 0.5\n",
+		"full_share":    "opponents:\n  hall_of_fame:\n    share: 1\n",
+		"no_champion":   "evaluation:\n  group_size: 4\nopponents:\n  hall_of_fame:\n    share: 0.1\n",
+		"jail_position": "game:\n  jail_position: 20\n",
+		"max_houses":    "game:\n  max_houses: 6\n",
+		"std_actions":   "game:\n  max_std_actions_per_turn: 0\n",
+		"min_price":     "game:\n  min_price: -1\n",
+	} {
+		path := filepath.Join(dir, name+".yaml")
+		assert.NoError(t, os.WriteFile(path, []byte(content), 0644))
+		_, err := ReadTrainingConfig(path)
+		assert.Error(t, err, name)
+	}
+}
+
+func TestPrepareGroups(t *testing.T) {
+	config := DefaultTrainingConfig()
+	config.Opponents = OpponentsConfig{Bots: []string{"random"}, Seats: 1}
+	evaluator := NewMonopolyEvaluator(config, nil)
+	var players []MonopolyPlayer
+	for range 7 {
+		player, err := NewBotPlayer("heuristic")
+		if !assert.NoError(t, err) {
+			return
+		}
+		players = append(players, player)
+	}
+	groups, err := evaluator.prepareGroups(players)
+	if !assert.NoError(t, err) {
+		return
+	}
+	if assert.Len(t, groups, 3) {
+		assert.Len(t, groups[0], 4)
+		assert.Len(t, groups[2], 2)
+	}
+	for _, group := range groups {
+		bots := 0
+		for _, player := range group {
+			if player.GetName() == "random" {
+				bots++
+			}
+		}
+		assert.Equal(t, 1, bots)
+	}
+}
+
+func TestStartGroupSeed(t *testing.T) {
+	options, err := neat.ReadNeatOptionsFromFile("../../neat_options.yaml")
+	if !assert.NoError(t, err) {
+		return
+	}
+	fitness, err := ReadFitness("../../fitness.yaml")
+	if !assert.NoError(t, err) {
+		return
+	}
+	config := DefaultTrainingConfig()
+	config.Seed = 11
+	config.OutputDir = t.TempDir()
+	config.Game.MaxRounds = 30
+	evaluator := NewMonopolyEvaluator(config, fitness)
+	ctx := neat.NewContext(context.Background(), options)
+	play := func() []float64 {
+		var players []MonopolyPlayer
+		for _, spec := range []string{"heuristic", "random", "heuristic"} {
+			player, err := NewBotPlayer(spec)
+			if !assert.NoError(t, err) {
+				return nil
+			}
+			players = append(players, player)
+		}
+		assert.NoError(t, evaluator.startGroup(ctx, GroupDetails{Trial: 0, Epoch: 2, Round: 5, GroupID: 1, Players: players}))
+		var scores []float64
+		for _, player := range players {
+			scores = append(scores, player.GetScore())
+		}
+		return scores
+	}
+	assert.Equal(t, play(), play(), "A game should be seeded by its ids")
+}
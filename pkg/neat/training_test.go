@@ -0,0 +1,56 @@
+package neatnetwork
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yaricom/goNEAT/v4/experiment"
+	"github.com/yaricom/goNEAT/v4/neat"
+)
+
+func TestSeededEpochs(t *testing.T) {
+	if !seedReproduction(1) {
+		t.Skip("the global random source cannot be seeded, GODEBUG=randseednop=1")
+	}
+	options, err := neat.ReadNeatOptionsFromFile("../../neat_options.yaml")
+	if !assert.NoError(t, err) {
+		return
+	}
+	options.PopSize = 12
+	options.NumRuns = 1
+	options.NumGenerations = 3
+	options.EpochExecutorType = neat.EpochExecutorTypeSequential
+	genome, err := ReadGenome("../../genomes/base_genome.yaml")
+	if !assert.NoError(t, err) {
+		return
+	}
+	fitness, err := ReadFitness("../../fitness.yaml")
+	if !assert.NoError(t, err) {
+		return
+	}
+	config := DefaultTrainingConfig()
+	config.Seed = 5
+	config.Evaluation.GamesPerEpoch = 2
+	config.Game.MaxRounds = 20
+
+	run := func() ([]EpochMetrics, string) {
+		config.OutputDir = t.TempDir()
+		evaluator := NewMonopolyEvaluator(config, fitness)
+		exp := experiment.Experiment{}
+		assert.NoError(t, runExperiment(neat.NewContext(context.Background(), options), &exp, genome, evaluator, nil))
+		metrics := evaluator.metrics.all()
+		for i := range metrics {
+			metrics[i].EvaluationSeconds = 0
+		}
+		if !assert.Len(t, exp.Trials, 1) || !assert.Len(t, exp.Trials[0].Generations, options.NumGenerations) {
+			return metrics, ""
+		}
+		return metrics, genomeText(t, exp.Trials[0].Generations[options.NumGenerations-1].Champion.Genotype)
+	}
+	metrics, champion := run()
+	repeatedMetrics, repeatedChampion := run()
+	assert.Len(t, metrics, options.NumGenerations)
+	assert.Equal(t, metrics, repeatedMetrics, "The same seed should evolve the same populations")
+	assert.Equal(t, champion, repeatedChampion)
+}
@@ -0,0 +1,132 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	cfg "monopoly/pkg/config"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	CHAT_ALL        = -1 // ChatMessage.To value of messages for every player
+	MAX_CHAT_LENGTH = 300
+	MAX_TRADE_ITEMS = cfg.LAST_PROPERTY_ID + 1
+)
+
+type ClientMessageType int
+
+const (
+	ClientResponse ClientMessageType = iota // answer to the pending ActionRequest
+	ClientChat
+	ClientUndo // takes back the player's last decision instead of answering, see ActionRequest.CanUndo
+)
+
+// ClientMessage is everything a human player sends to the server once seated.
+// Chat messages can be sent at any time, also while the server waits for a response.
+type ClientMessage struct {
+	Type     ClientMessageType
+	Response json.RawMessage
+	Chat     *ChatMessage
+}
+
+// TradeProposal is a deal suggested in the chat. It is not binding, the players
+// carry it out with the SELLOFFER and BUYOFFER actions once they agree.
+type TradeProposal struct {
+	Give  []int // properties the sender gives away
+	Take  []int // properties the sender wants from the recipient
+	Money int   // cash the sender adds, negative if the sender asks for cash
+}
+
+type ChatMessage struct {
+	From  int // set by the server
+	To    int // player ID or CHAT_ALL
+	Text  string
+	Trade *TradeProposal
+}
+
+func (s *ConsoleServer) validateChat(from int, msg *ChatMessage) error {
+	if msg.To != CHAT_ALL {
+		if _, ok := s.PlayersInfoMap[msg.To]; !ok || msg.To == from {
+			return fmt.Errorf("invalid recipient %d", msg.To)
+		}
+	}
+	msg.Text = strings.TrimSpace(msg.Text)
+	if utf8.RuneCountInString(msg.Text) > MAX_CHAT_LENGTH {
+		return fmt.Errorf("message longer than %d characters", MAX_CHAT_LENGTH)
+	}
+	if msg.Trade == nil {
+		if msg.Text == "" {
+			return fmt.Errorf("empty message")
+		}
+		return nil
+	}
+	if msg.To == CHAT_ALL {
+		return fmt.Errorf("trade proposals need a single recipient")
+	}
+	if len(msg.Trade.Give) > MAX_TRADE_ITEMS || len(msg.Trade.Take) > MAX_TRADE_ITEMS {
+		return fmt.Errorf("too many properties in trade proposal")
+	}
+	for _, propertyId := range append(msg.Trade.Give, msg.Trade.Take...) {
+		if propertyId < 0 || propertyId > cfg.LAST_PROPERTY_ID {
+			return fmt.Errorf("invalid property %d in trade proposal", propertyId)
+		}
+	}
+	return nil
+}
+
+// relayChat delivers a chat message to its recipients and back to the sender. Messages to bots are
+// only echoed, bots do not read the chat.
+func (s *ConsoleServer) relayChat(from int, msg ChatMessage) {
+	msg.From = from
+	if err := s.validateChat(from, &msg); err != nil {
+		s.PlayersInfoMap[from].conn.Send(ActionRequest{Type: StateUpdate, PlayerId: from, Message: "Chat not sent: " + err.Error()})
+		return
+	}
+	fmt.Printf("Chat from player %d to %d: %q\n", from, msg.To, msg.Text)
+	for id, info := range s.PlayersInfoMap {
+		if !info.isHuman || (msg.To != CHAT_ALL && id != msg.To && id != from) {
+			continue
+		}
+		if err := info.conn.Send(ActionRequest{Type: ChatUpdate, PlayerId: id, Chat: &msg}); err != nil {
+			fmt.Printf("Error sending chat to player %d: %v\n", id, err)
+		}
+	}
+}
+
+// startReaders reads the messages of every human player for the rest of the game.
+// Responses are passed to request, chat messages are relayed right away.
+func (s *ConsoleServer) startReaders() {
+	for id, info := range s.PlayersInfoMap {
+		if info.isHuman {
+			go s.readMessages(id, info)
+		}
+	}
+}
+
+func (s *ConsoleServer) readMessages(id int, info PlayerInfo) {
+	defer close(info.responses)
+	for {
+		var msg ClientMessage
+		if err := info.conn.Receive(&msg); err != nil {
+			if s.ctx.Err() == nil && !s.closed.Load() {
+				fmt.Printf("Error reading from player %d: %v\n", id, err)
+			}
+			return
+		}
+		switch msg.Type {
+		case ClientResponse, ClientUndo:
+			select {
+			case info.responses <- msg:
+			case <-s.ctx.Done():
+				return
+			}
+		case ClientChat:
+			if msg.Chat != nil {
+				s.relayChat(id, *msg.Chat)
+			}
+		default:
+			fmt.Printf("Unknown message type %d from player %d\n", msg.Type, id)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package server
+
+import (
+	"encoding/json"
+	"net"
+	"sync"
+
+	"github.com/gorilla/websocket"
+)
+
+// clientConn is a connection to a human player, independent of the transport used.
+type clientConn interface {
+	Send(v interface{}) error
+	Receive(v interface{}) error
+	Close() error
+}
+
+type tcpConn struct {
+	conn    net.Conn
+	encoder *json.Encoder
+	decoder *json.Decoder
+	mutex   sync.Mutex
+}
+
+func newTCPConn(conn net.Conn) *tcpConn {
+	return &tcpConn{
+		conn:    conn,
+		encoder: json.NewEncoder(conn),
+		decoder: json.NewDecoder(conn),
+	}
+}
+
+func (c *tcpConn) Send(v interface{}) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.encoder.Encode(v)
+}
+
+func (c *tcpConn) Receive(v interface{}) error {
+	return c.decoder.Decode(v)
+}
+
+func (c *tcpConn) Close() error {
+	return c.conn.Close()
+}
+
+type wsConn struct {
+	conn  *websocket.Conn
+	mutex sync.Mutex
+}
+
+func newWSConn(conn *websocket.Conn) *wsConn {
+	return &wsConn{conn: conn}
+}
+
+func (c *wsConn) Send(v interface{}) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.conn.WriteJSON(v)
+}
+
+func (c *wsConn) Receive(v interface{}) error {
+	return c.conn.ReadJSON(v)
+}
+
+func (c *wsConn) Close() error {
+	return c.conn.Close()
+}
@@ -0,0 +1,423 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"monopoly/pkg/monopoly"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+)
+
+const TABLE_SEATS = 4
+
+var validTableID = regexp.MustCompile(`^[A-Za-z0-9_-]{1,32}$`)
+
+// JoinRequest is the first message sent by a client. HumanPlayers is only used when
+// the table does not exist yet, in which case it is created with that many human seats.
+// Password is required if the server has one, Token if the table has invite tokens.
+// AllowUndo makes a new table casual, letting players take back their last decision.
+type JoinRequest struct {
+	TableID      string
+	HumanPlayers int
+	AllowUndo    bool
+	Password     string
+	Token        string
+}
+
+// JoinResponse is the answer to JoinRequest, sent once the client got a seat.
+// The player who created a table with invite tokens receives the tokens for the other seats.
+type JoinResponse struct {
+	TableID  string
+	PlayerId int
+	Invites  []string
+	Error    string
+}
+
+type TableInfo struct {
+	ID           string
+	HumanPlayers int
+	AllowUndo    bool
+	FreeSeats    int
+	Started      bool
+}
+
+// GameManager hosts many tables at once. Every table has its own game, context and seats,
+// and is removed from the manager as soon as its game is over. When ctx is done, all games
+// are cancelled.
+type GameManager struct {
+	// SnapshotDir is where the state of cancelled games is saved, empty disables snapshots
+	SnapshotDir string
+	// TLSConfig enables TLS on the TCP and web endpoints, nil serves plaintext
+	TLSConfig *tls.Config
+	// Password is required from every joining client, empty disables it
+	Password string
+	// InviteTokens makes every new table generate a single-use token per human seat
+	InviteTokens bool
+
+	ctx       context.Context
+	newBots   func(ctx context.Context, count int) []PlayerIO
+	newLogger func(tableID string) monopoly.Logger
+	tables    map[string]*Table
+	nextID    int
+	mutex     sync.Mutex
+	running   sync.WaitGroup
+}
+
+// NewGameManager creates the manager, newBots gets the context of the table the bots are seated at.
+func NewGameManager(ctx context.Context, newBots func(ctx context.Context, count int) []PlayerIO, newLogger func(tableID string) monopoly.Logger) *GameManager {
+	return &GameManager{
+		ctx:       ctx,
+		newBots:   newBots,
+		newLogger: newLogger,
+		tables:    make(map[string]*Table),
+	}
+}
+
+// CreateTable opens a new table, the remaining seats are taken by bots. An empty id is replaced by a generated one.
+// The game starts as soon as all human players join. At a table with allowUndo, players can take back their
+// last decision as long as nothing random happened since.
+func (m *GameManager) CreateTable(id string, humanPlayers int, allowUndo bool) (*Table, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return m.createTable(id, humanPlayers, allowUndo)
+}
+
+func (m *GameManager) createTable(id string, humanPlayers int, allowUndo bool) (*Table, error) {
+	if humanPlayers < 0 || humanPlayers > TABLE_SEATS {
+		return nil, fmt.Errorf("number of human players must be between 0 and %d", TABLE_SEATS)
+	}
+	if id == "" {
+		for {
+			m.nextID++
+			id = fmt.Sprintf("table-%d", m.nextID)
+			if _, ok := m.tables[id]; !ok {
+				break
+			}
+		}
+	}
+	if !validTableID.MatchString(id) {
+		return nil, fmt.Errorf("table name may only contain letters, digits, '-' and '_' (at most 32 characters)")
+	}
+	if _, ok := m.tables[id]; ok {
+		return nil, fmt.Errorf("table %s already exists", id)
+	}
+	ctx, cancel := context.WithCancel(m.ctx)
+	server := NewConsoleServer(ctx, humanPlayers, m.newBots(ctx, TABLE_SEATS-humanPlayers))
+	t := &Table{
+		ID:           id,
+		HumanPlayers: humanPlayers,
+		AllowUndo:    allowUndo,
+		server:       server,
+		ctx:          ctx,
+		cancel:       cancel,
+		freeSeats:    slices.Clone(server.humanSeats),
+		joinCh:       make(chan seatRequest),
+		full:         make(chan struct{}),
+		done:         make(chan struct{}),
+	}
+	if m.InviteTokens {
+		t.invites = make(map[string]int)
+		for _, seat := range t.server.humanSeats {
+			t.invites[newInviteToken()] = seat
+		}
+	}
+	m.tables[id] = t
+	m.running.Add(1)
+	go func() {
+		defer m.running.Done()
+		t.run(m.newLogger(id), m.SnapshotDir, func() { m.removeTable(id) })
+	}()
+	fmt.Printf("Table %s created, waiting for %d players...\n", id, humanPlayers)
+	return t, nil
+}
+
+// Wait blocks until games at all tables are over.
+func (m *GameManager) Wait() {
+	m.running.Wait()
+}
+
+func (m *GameManager) removeTable(id string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	delete(m.tables, id)
+}
+
+// Table returns the table with the given id, or nil if there is none.
+func (m *GameManager) Table(id string) *Table {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return m.tables[id]
+}
+
+// Tables lists all tables, sorted by id.
+func (m *GameManager) Tables() []TableInfo {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	infos := make([]TableInfo, 0, len(m.tables))
+	for _, t := range m.tables {
+		infos = append(infos, t.Info())
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].ID < infos[j].ID
+	})
+	return infos
+}
+
+// ListenTCP accepts consoleCLI clients until the listener fails.
+func (m *GameManager) ListenTCP(addr string) error {
+	var ln net.Listener
+	var err error
+	if m.TLSConfig != nil {
+		ln, err = tls.Listen("tcp", addr, m.TLSConfig)
+	} else {
+		ln, err = net.Listen("tcp", addr)
+	}
+	if err != nil {
+		return err
+	}
+	go func() {
+		<-m.ctx.Done()
+		ln.Close()
+	}()
+	fmt.Printf("Server listening on %s\n", addr)
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			return err
+		}
+		go m.handleConn(newTCPConn(conn))
+	}
+}
+
+// ListenWeb serves the browser board and its WebSocket endpoint.
+func (m *GameManager) ListenWeb(addr string) error {
+	srv := &http.Server{Addr: addr, Handler: newWebHandler(m), TLSConfig: m.TLSConfig}
+	go func() {
+		<-m.ctx.Done()
+		srv.Close()
+	}()
+	if m.TLSConfig != nil {
+		fmt.Printf("Board viewer available at https://localhost%s\n", addr)
+		return srv.ListenAndServeTLS("", "")
+	}
+	fmt.Printf("Board viewer available at http://localhost%s\n", addr)
+	return srv.ListenAndServe()
+}
+
+// handleConn reads the join request and hands the connection over to the requested table.
+func (m *GameManager) handleConn(conn clientConn) {
+	var req JoinRequest
+	if err := conn.Receive(&req); err != nil {
+		fmt.Println("Error reading join request:", err)
+		conn.Close()
+		return
+	}
+	if !checkPassword(m.Password, req.Password) {
+		// slow down password guessing
+		time.Sleep(time.Second)
+		conn.Send(JoinResponse{TableID: req.TableID, Error: "wrong password"})
+		conn.Close()
+		return
+	}
+	m.mutex.Lock()
+	t, ok := m.tables[req.TableID]
+	var err error
+	if !ok {
+		if req.HumanPlayers > 0 {
+			t, err = m.createTable(req.TableID, req.HumanPlayers, req.AllowUndo)
+		} else {
+			err = fmt.Errorf("table %s does not exist", req.TableID)
+		}
+	}
+	m.mutex.Unlock()
+	if err != nil {
+		conn.Send(JoinResponse{TableID: req.TableID, Error: err.Error()})
+		conn.Close()
+		return
+	}
+	t.join(seatRequest{conn: conn, token: req.Token, creator: !ok})
+}
+
+type seatRequest struct {
+	conn    clientConn
+	token   string
+	creator bool // creator of a table takes a seat without a token and receives the remaining ones
+}
+
+type Table struct {
+	ID           string
+	HumanPlayers int
+	AllowUndo    bool
+	server       *ConsoleServer
+	ctx          context.Context
+	cancel       context.CancelFunc
+	joinCh       chan seatRequest
+	freeSeats    []int
+	invites      map[string]int // unused invite token -> seat, nil if the table has no invites
+	full         chan struct{}
+	done         chan struct{}
+	seated       int
+	started      bool
+	mutex        sync.Mutex
+}
+
+func (t *Table) Info() TableInfo {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return TableInfo{
+		ID:           t.ID,
+		HumanPlayers: t.HumanPlayers,
+		AllowUndo:    t.AllowUndo,
+		FreeSeats:    t.HumanPlayers - t.seated,
+		Started:      t.started,
+	}
+}
+
+// Done is closed when the table's game is over.
+func (t *Table) Done() <-chan struct{} {
+	return t.done
+}
+
+// Invites returns the unused invite tokens, or nil if the table does not use them.
+func (t *Table) Invites() []string {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if t.invites == nil {
+		return nil
+	}
+	tokens := make([]string, 0, len(t.invites))
+	for token := range t.invites {
+		tokens = append(tokens, token)
+	}
+	sort.Strings(tokens)
+	return tokens
+}
+
+// Close cancels the table's game.
+func (t *Table) Close() {
+	t.cancel()
+}
+
+func (t *Table) join(req seatRequest) {
+	conn := req.conn
+	select {
+	case t.joinCh <- req:
+	case <-t.full:
+		conn.Send(JoinResponse{TableID: t.ID, Error: fmt.Sprintf("table %s is full", t.ID)})
+		conn.Close()
+	case <-t.ctx.Done():
+		conn.Send(JoinResponse{TableID: t.ID, Error: fmt.Sprintf("table %s is closed", t.ID)})
+		conn.Close()
+	}
+}
+
+func (t *Table) run(logger monopoly.Logger, snapshotDir string, cleanup func()) {
+	defer close(t.done)
+	defer cleanup()
+	defer t.cancel()
+	defer t.server.closeConnections()
+
+	for t.seated < t.HumanPlayers {
+		select {
+		case req := <-t.joinCh:
+			id, token, invites, err := t.pickSeat(req)
+			if err != nil {
+				req.conn.Send(JoinResponse{TableID: t.ID, Error: err.Error()})
+				req.conn.Close()
+				continue
+			}
+			if err := req.conn.Send(JoinResponse{TableID: t.ID, PlayerId: id, Invites: invites}); err != nil {
+				fmt.Println("Error sending player ID:", err)
+				req.conn.Close()
+				continue
+			}
+			t.occupySeat(id, token)
+			t.server.seatHuman(id, req.conn)
+			fmt.Printf("Table %s: player %d joined\n", t.ID, id)
+		case <-t.ctx.Done():
+			t.server.Finish(monopoly.CANCELLED, -1, monopoly.GameState{})
+			return
+		}
+	}
+	close(t.full)
+	t.mutex.Lock()
+	t.started = true
+	t.mutex.Unlock()
+	fmt.Printf("Table %s: all players connected!\n", t.ID)
+
+	defer func() {
+		if r := recover(); r != nil {
+			fmt.Printf("Table %s: game aborted: %v\n", t.ID, r)
+		}
+	}()
+	t.server.startReaders()
+	game := monopoly.NewGame(t.ctx, t.server, t.server.Logger(logger), 0)
+	if t.AllowUndo {
+		game.EnableUndo()
+	}
+	t.server.game = game
+	game.Start()
+	if t.ctx.Err() != nil && snapshotDir != "" {
+		t.saveSnapshot(snapshotDir)
+	}
+}
+
+// pickSeat chooses a free seat for req, the seat is taken by occupySeat once the player is notified.
+// For tables with invites, token is the invite token assigned to the seat.
+func (t *Table) pickSeat(req seatRequest) (id int, token string, invites []string, err error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if t.invites == nil {
+		return t.freeSeats[0], "", nil, nil
+	}
+	if !req.creator {
+		seat, ok := t.invites[req.token]
+		if !ok {
+			return 0, "", nil, fmt.Errorf("invalid invite token")
+		}
+		return seat, req.token, nil, nil
+	}
+	for candidate, seat := range t.invites {
+		if token == "" || candidate < token {
+			token, id = candidate, seat
+		}
+	}
+	for candidate := range t.invites {
+		if candidate != token {
+			invites = append(invites, candidate)
+		}
+	}
+	sort.Strings(invites)
+	return id, token, invites, nil
+}
+
+func (t *Table) occupySeat(id int, token string) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	delete(t.invites, token)
+	t.freeSeats = slices.DeleteFunc(t.freeSeats, func(seat int) bool {
+		return seat == id
+	})
+	t.seated++
+}
+
+func (t *Table) saveSnapshot(dir string) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		fmt.Printf("Table %s: cannot save snapshot: %v\n", t.ID, err)
+		return
+	}
+	path := filepath.Join(dir, fmt.Sprintf("%s_%s.json", t.ID, time.Now().Format("20060102_150405")))
+	if err := monopoly.SaveSnapshot(path, t.server.finalState); err != nil {
+		fmt.Printf("Table %s: cannot save snapshot: %v\n", t.ID, err)
+		return
+	}
+	fmt.Printf("Table %s: snapshot saved to %s\n", t.ID, path)
+}
@@ -1,11 +1,12 @@
 package server
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"math/rand"
 	"monopoly/pkg/monopoly"
-	"net"
+	"sync/atomic"
 	"time"
 )
 
@@ -18,6 +19,9 @@ const (
 	BuyFromPlayerDecision
 	SellToPlayerDecision
 	BiddingDecision
+	StateUpdate  // game event, no response expected
+	GameFinished // sent once when the game is over, no response expected
+	ChatUpdate   // chat message from a player, no response expected
 )
 
 type ActionRequest struct {
@@ -28,6 +32,11 @@ type ActionRequest struct {
 	JailActionList []monopoly.JailAction
 	PropertyId     int
 	Price          int
+	Message        string
+	FinishOption   monopoly.FinishOption
+	Winner         int          // winner of the game, or the highest bidder of an auction (-1 if none)
+	Chat           *ChatMessage `json:",omitempty"`
+	CanUndo        bool         // the player may answer with ClientUndo
 }
 
 type PlayerIO interface {
@@ -40,16 +49,25 @@ type PlayerIO interface {
 }
 
 type PlayerInfo struct {
-	isHuman bool
-	conn    net.Conn
-	bot     PlayerIO
+	isHuman   bool
+	conn      clientConn
+	responses chan ClientMessage // closed when the connection is lost
+	bot       PlayerIO
 }
 
 type ConsoleServer struct {
 	PlayersInfoMap map[int]PlayerInfo
+	humanSeats     []int
+	ctx            context.Context
+	game           *monopoly.Game
+	lastHuman      int // human player who was asked last, only they may undo
+	finalState     monopoly.GameState
+	closed         atomic.Bool
 }
 
-func NewConsoleServer(humanPlayers int, bots []PlayerIO) *ConsoleServer {
+// NewConsoleServer seats the bots at random places. The remaining seats are taken by human
+// players as they join the table, see GameManager. Requests to human players are interrupted when ctx is done.
+func NewConsoleServer(ctx context.Context, humanPlayers int, bots []PlayerIO) *ConsoleServer {
 	r := rand.New(rand.NewSource(time.Now().UnixNano()))
 	botPlayers := len(bots)
 	totalPlayers := humanPlayers + botPlayers
@@ -62,35 +80,78 @@ func NewConsoleServer(humanPlayers int, bots []PlayerIO) *ConsoleServer {
 			bot:     bots[i],
 		}
 	}
+	return &ConsoleServer{
+		PlayersInfoMap: playerMap,
+		humanSeats:     perm[botPlayers:],
+		ctx:            ctx,
+		lastHuman:      -1,
+	}
+}
 
-	ln, err := net.Listen("tcp", ":12345")
-	if err != nil {
-		panic(err)
+func (s *ConsoleServer) seatHuman(id int, conn clientConn) {
+	s.PlayersInfoMap[id] = PlayerInfo{
+		isHuman:   true,
+		conn:      conn,
+		responses: make(chan ClientMessage),
 	}
+}
 
-	fmt.Printf("Server listening on :12345, waiting for %d players...\n", humanPlayers)
-	for i := range humanPlayers {
-		conn, err := ln.Accept()
-		if err != nil {
-			fmt.Println("Connection error:", err)
-			continue
-		}
-		id := perm[botPlayers+i]
-		playerMap[id] = PlayerInfo{
-			isHuman: true,
-			conn:    conn,
+func (s *ConsoleServer) closeConnections() {
+	s.closed.Store(true)
+	for _, playerInfo := range s.PlayersInfoMap {
+		if playerInfo.isHuman {
+			playerInfo.conn.Close()
 		}
-		fmt.Printf("Player %d joined\n", id)
+	}
+}
 
-		encoder := json.NewEncoder(conn)
-		if err := encoder.Encode(id); err != nil {
-			fmt.Println("Error sending player ID")
-			panic(err)
+// request sends req to a human player and waits for the response. If the game context is done
+// in the meantime, the wait is interrupted and monopoly.ErrGameCancelled is raised.
+// At casual tables the player can take back their last decision instead, which raises monopoly.ErrUndo.
+func (s *ConsoleServer) request(playerInfo PlayerInfo, req ActionRequest, resp interface{}) {
+	if s.ctx.Err() != nil {
+		panic(monopoly.ErrGameCancelled)
+	}
+	// undoing is not possible once another human made a decision
+	req.CanUndo = s.game != nil && s.lastHuman == req.PlayerId && s.game.CanUndo(req.PlayerId)
+	s.lastHuman = req.PlayerId
+	if err := playerInfo.conn.Send(req); err != nil {
+		fmt.Println("Error sending request to player:", err)
+		panic(err)
+	}
+	for {
+		select {
+		case msg, ok := <-playerInfo.responses:
+			if !ok {
+				panic("Cannot read response from player")
+			}
+			if msg.Type == ClientUndo {
+				if !req.CanUndo {
+					continue
+				}
+				fmt.Printf("Player %d takes back their last decision\n", req.PlayerId)
+				panic(monopoly.ErrUndo)
+			}
+			if err := json.Unmarshal(msg.Response, resp); err != nil {
+				fmt.Println("Error decoding response:", err)
+				panic("Cannot read response from player")
+			}
+			return
+		case <-s.ctx.Done():
+			panic(monopoly.ErrGameCancelled)
 		}
 	}
-	fmt.Println("All players connected!")
-	return &ConsoleServer{
-		PlayersInfoMap: playerMap,
+}
+
+func (s *ConsoleServer) broadcast(req ActionRequest) {
+	for id, info := range s.PlayersInfoMap {
+		if !info.isHuman {
+			continue
+		}
+		req.PlayerId = id
+		if err := info.conn.Send(req); err != nil {
+			fmt.Printf("Error sending event to player %d: %v\n", id, err)
+		}
 	}
 }
 
@@ -118,19 +179,8 @@ func (s *ConsoleServer) GetStdAction(player int, state monopoly.GameState, avail
 		State:         state,
 		StdActionList: availableActions,
 	}
-	encoder := json.NewEncoder(playerInfo.conn)
-	decoder := json.NewDecoder(playerInfo.conn)
-	if err := encoder.Encode(req); err != nil {
-		fmt.Println("Error sending request to player:", err)
-		panic(err)
-	}
-
 	var resp monopoly.ActionDetails
-	err := decoder.Decode(&resp)
-	if err != nil {
-		fmt.Println("Error decoding response:", err)
-		panic("Cannot read response from player")
-	}
+	s.request(playerInfo, req, &resp)
 	fmt.Printf("Player %d chose action: %s\n", player, monopoly.StdActionNames[resp.Action])
 	return resp
 }
@@ -146,19 +196,8 @@ func (s *ConsoleServer) GetJailAction(player int, state monopoly.GameState, avai
 		State:          state,
 		JailActionList: available,
 	}
-	encoder := json.NewEncoder(playerInfo.conn)
-	decoder := json.NewDecoder(playerInfo.conn)
-	if err := encoder.Encode(req); err != nil {
-		fmt.Println("Error sending request to player:", err)
-		panic(err)
-	}
-
 	var resp monopoly.JailAction
-	err := decoder.Decode(&resp)
-	if err != nil {
-		fmt.Println("Error decoding response:", err)
-		panic("Cannot read response from player")
-	}
+	s.request(playerInfo, req, &resp)
 	fmt.Printf("Player %d chose jail action: %s\n", player, monopoly.JailActionNames[resp])
 	return resp
 }
@@ -175,19 +214,8 @@ func (s *ConsoleServer) BuyDecision(player int, state monopoly.GameState, proper
 		PropertyId: propertyId,
 	}
 
-	encoder := json.NewEncoder(playerInfo.conn)
-	decoder := json.NewDecoder(playerInfo.conn)
-	if err := encoder.Encode(req); err != nil {
-		fmt.Println("Error sending request to player:", err)
-		panic(err)
-	}
-
 	var resp bool
-	err := decoder.Decode(&resp)
-	if err != nil {
-		fmt.Println("Error decoding response:", err)
-		panic("Cannot read response from player")
-	}
+	s.request(playerInfo, req, &resp)
 	fmt.Printf("Player %d decided to buy: %t\n", player, resp)
 	return resp
 }
@@ -204,19 +232,8 @@ func (s *ConsoleServer) BuyFromPlayerDecision(player int, state monopoly.GameSta
 		PropertyId: propertyId,
 		Price:      price,
 	}
-	encoder := json.NewEncoder(playerInfo.conn)
-	decoder := json.NewDecoder(playerInfo.conn)
-	if err := encoder.Encode(req); err != nil {
-		fmt.Println("Error sending request to player:", err)
-		panic(err)
-	}
-
 	var resp bool
-	err := decoder.Decode(&resp)
-	if err != nil {
-		fmt.Println("Error decoding response:", err)
-		panic("Cannot read response from player")
-	}
+	s.request(playerInfo, req, &resp)
 	fmt.Printf("Player %d decided to buy from another player: %t\n", player, resp)
 	return resp
 }
@@ -233,19 +250,8 @@ func (s *ConsoleServer) SellToPlayerDecision(player int, state monopoly.GameStat
 		PropertyId: propertyId,
 		Price:      price,
 	}
-	encoder := json.NewEncoder(playerInfo.conn)
-	decoder := json.NewDecoder(playerInfo.conn)
-	if err := encoder.Encode(req); err != nil {
-		fmt.Println("Error sending request to player:", err)
-		panic(err)
-	}
-
 	var resp bool
-	err := decoder.Decode(&resp)
-	if err != nil {
-		fmt.Println("Error decoding response:", err)
-		panic("Cannot read response from player")
-	}
+	s.request(playerInfo, req, &resp)
 	fmt.Printf("Player %d decided to sell to another player: %t\n", player, resp)
 	return resp
 }
@@ -261,20 +267,10 @@ func (s *ConsoleServer) BiddingDecision(player int, state monopoly.GameState, pr
 		State:      state,
 		PropertyId: propertyId,
 		Price:      currentPrice,
+		Winner:     currentWinner,
 	}
-	encoder := json.NewEncoder(playerInfo.conn)
-	decoder := json.NewDecoder(playerInfo.conn)
-	if err := encoder.Encode(req); err != nil {
-		fmt.Println("Error sending request to player:", err)
-		panic(err)
-	}
-
 	var resp int
-	err := decoder.Decode(&resp)
-	if err != nil {
-		fmt.Println("Error decoding response:", err)
-		panic("Cannot read response from player")
-	}
+	s.request(playerInfo, req, &resp)
 	fmt.Printf("Player %d made a bid: %d\n", player, resp)
 	return resp
 }
@@ -287,10 +283,48 @@ func (s *ConsoleServer) Finish(f monopoly.FinishOption, winner int, state monopo
 		fmt.Println("Game ended in a draw!")
 	case monopoly.ROUND_LIMIT:
 		fmt.Printf("Game ended due to round limit. Player with ID %d wins!\n", winner)
+	case monopoly.CANCELLED:
+		fmt.Println("Game cancelled!")
 	}
-	for _, playerInfo := range s.PlayersInfoMap {
-		if playerInfo.isHuman {
-			playerInfo.conn.Close()
-		}
-	}
+	s.finalState = state
+	s.broadcast(ActionRequest{
+		Type:         GameFinished,
+		State:        state,
+		FinishOption: f,
+		Winner:       winner,
+	})
+	s.closeConnections()
+}
+
+// Logger wraps the game logger so that every logged event is also sent to the human players.
+func (s *ConsoleServer) Logger(inner monopoly.Logger) monopoly.Logger {
+	return &broadcastLogger{server: s, inner: inner}
+}
+
+type broadcastLogger struct {
+	server *ConsoleServer
+	inner  monopoly.Logger
+	state  monopoly.GameState
+}
+
+func (l *broadcastLogger) Log(message string) {
+	l.inner.Log(message)
+	l.server.broadcast(ActionRequest{Type: StateUpdate, Message: message, State: l.state})
+}
+
+func (l *broadcastLogger) LogState(state monopoly.GameState) {
+	l.inner.LogState(state)
+	l.state = state
+}
+
+func (l *broadcastLogger) LogWithState(message string, state monopoly.GameState) {
+	l.inner.LogWithState(message, state)
+	l.state = state
+	l.server.broadcast(ActionRequest{Type: StateUpdate, Message: message, State: state})
+}
+
+func (l *broadcastLogger) Error(message string, state monopoly.GameState) {
+	l.inner.Error(message, state)
+	l.state = state
+	l.server.broadcast(ActionRequest{Type: StateUpdate, Message: "ERROR: " + message, State: state})
 }
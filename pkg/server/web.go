@@ -0,0 +1,52 @@
+package server
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"monopoly/pkg/monopoly"
+	"net/http"
+
+	"github.com/gorilla/websocket"
+)
+
+//go:embed web
+var webFiles embed.FS
+
+var upgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+}
+
+// newWebHandler serves the browser board, the static board description, the list of open tables
+// and the WebSocket endpoint which speaks the same protocol as the TCP endpoint.
+func newWebHandler(m *GameManager) http.Handler {
+	static, err := fs.Sub(webFiles, "web")
+	if err != nil {
+		panic(err)
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/", http.FileServer(http.FS(static)))
+	mux.HandleFunc("/board", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(monopoly.GetBoard()); err != nil {
+			fmt.Println("Error sending board:", err)
+		}
+	})
+	mux.HandleFunc("/tables", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(m.Tables()); err != nil {
+			fmt.Println("Error sending tables:", err)
+		}
+	})
+	mux.HandleFunc("/ws", func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			fmt.Println("WebSocket upgrade error:", err)
+			return
+		}
+		m.handleConn(newWSConn(conn))
+	})
+	return mux
+}
@@ -0,0 +1,459 @@
+"use strict";
+
+// Must match server.RequestType
+const REQUEST = {
+  GET_STD_ACTION: 0,
+  GET_JAIL_ACTION: 1,
+  BUY_DECISION: 2,
+  BUY_FROM_PLAYER_DECISION: 3,
+  SELL_TO_PLAYER_DECISION: 4,
+  BIDDING_DECISION: 5,
+  STATE_UPDATE: 6,
+  GAME_FINISHED: 7,
+  CHAT_UPDATE: 8,
+};
+
+// Must match server.ClientMessageType and server.CHAT_ALL
+const CLIENT_RESPONSE = 0;
+const CLIENT_CHAT = 1;
+const CLIENT_UNDO = 2;
+const CHAT_ALL = -1;
+
+// Must match monopoly.StdAction and monopoly.JailAction
+const STD_ACTION_NAMES = ["NO ACTION", "MORTGAGE", "BUY OUT", "SELL OFFER", "BUY OFFER", "BUY HOUSE", "SELL HOUSE"];
+const STD_ACTION_LISTS = {
+  1: "MortgageList",
+  2: "BuyOutList",
+  3: "SellPropertyList",
+  4: "BuyPropertyList",
+  5: "BuyHouseList",
+  6: "SellHouseList",
+};
+const SELLOFFER = 3;
+const BUYOFFER = 4;
+const JAIL_ACTION_NAMES = ["ROLL DICE", "PAY BAIL", "USE CARD"];
+
+// Must match monopoly.FinishOption
+const FINISH_DRAW = 1;
+const FINISH_CANCELLED = 3;
+
+const SET_COLORS = {
+  "Brown": "#8b4513",
+  "Light Blue": "#aae0fa",
+  "Pink": "#d93a96",
+  "Orange": "#f7941d",
+  "Red": "#ed1b24",
+  "Yellow": "#fef200",
+  "Green": "#1fb25a",
+  "Dark Blue": "#0072bb",
+};
+const PLAYER_COLORS = ["#e6194b", "#4363d8", "#f58231", "#911eb4"];
+
+let board = null;
+let playerId = null;
+let socket = null;
+let state = null;
+let canUndo = false;
+
+function gridPosition(fieldIndex) {
+  if (fieldIndex <= 10) {
+    return { row: 11, col: 11 - fieldIndex };
+  }
+  if (fieldIndex <= 20) {
+    return { row: 11 - (fieldIndex - 10), col: 1 };
+  }
+  if (fieldIndex <= 30) {
+    return { row: 1, col: 1 + (fieldIndex - 20) };
+  }
+  return { row: 1 + (fieldIndex - 30), col: 11 };
+}
+
+function el(tag, attrs, ...children) {
+  const node = document.createElement(tag);
+  Object.assign(node, attrs || {});
+  for (const child of children) {
+    node.append(child);
+  }
+  return node;
+}
+
+function propertyName(propertyId) {
+  if (state && state.Properties) {
+    return state.Properties[propertyId].Name;
+  }
+  const field = board.Fields.find((f) => f.PropertyIndex === propertyId);
+  return field ? field.Name : "Property " + propertyId;
+}
+
+function playerName(id) {
+  if (state && state.Players && state.Players[id]) {
+    return state.Players[id].Name;
+  }
+  return "Player " + id;
+}
+
+function createBoard() {
+  const container = document.getElementById("board");
+  for (const field of board.Fields) {
+    const pos = gridPosition(field.FieldIndex);
+    const cell = el("div", { className: "field", id: "field-" + field.FieldIndex });
+    cell.style.gridRow = pos.row;
+    cell.style.gridColumn = pos.col;
+    if (field.Set && SET_COLORS[field.Set]) {
+      const color = el("div", { className: "color" });
+      color.style.background = SET_COLORS[field.Set];
+      cell.append(color);
+    }
+    cell.append(el("div", { className: "name", textContent: field.Name }));
+    if (field.Price > 0) {
+      cell.append(el("div", { textContent: field.Price + "$" }));
+    }
+    if (field.Tax > 0) {
+      cell.append(el("div", { textContent: "pay " + field.Tax + "$" }));
+    }
+    cell.append(el("div", { className: "houses" }));
+    cell.append(el("div", { className: "tokens" }));
+    cell.append(el("div", { className: "owner" }));
+    container.append(cell);
+  }
+}
+
+function render() {
+  if (!state || !state.Players) {
+    return;
+  }
+  for (const field of board.Fields) {
+    const cell = document.getElementById("field-" + field.FieldIndex);
+    const tokens = cell.querySelector(".tokens");
+    tokens.replaceChildren();
+    state.Players.forEach((player, idx) => {
+      if (!player.IsBankrupt && player.CurrentPosition === field.FieldIndex) {
+        const token = el("span", { className: "token", title: player.Name });
+        token.style.background = PLAYER_COLORS[idx];
+        tokens.append(token);
+      }
+    });
+    if (field.PropertyIndex < 0) {
+      continue;
+    }
+    const property = state.Properties[field.PropertyIndex];
+    cell.classList.toggle("mortgaged", property.IsMortgaged);
+    cell.querySelector(".owner").style.background = property.Owner ? PLAYER_COLORS[property.Owner.ID] : "transparent";
+    let houses = "";
+    if (property.Houses === 5) {
+      houses = "HOTEL";
+    } else if (property.Houses > 0) {
+      houses = "⌂".repeat(property.Houses);
+    }
+    cell.querySelector(".houses").textContent = houses;
+  }
+  renderPlayers();
+}
+
+function renderPlayers() {
+  const table = el("table", {},
+    el("tr", {}, el("th", {}, ""), el("th", {}, "Player"), el("th", {}, "Cash"), el("th", {}, "Properties"), el("th", {}, "Status")));
+  state.Players.forEach((player, idx) => {
+    const token = el("span", { className: "token" });
+    token.style.background = PLAYER_COLORS[idx];
+    let status = "";
+    if (player.IsBankrupt) {
+      status = "BANKRUPT";
+    } else if (player.IsJailed) {
+      status = "IN JAIL";
+    }
+    if (player.JailCards > 0) {
+      status += " cards: " + player.JailCards;
+    }
+    const name = idx === playerId ? player.Name + " (you)" : player.Name;
+    const properties = (player.Properties || []).map((id) => {
+      const p = state.Properties[id];
+      return p.Name + (p.IsMortgaged ? " (M)" : "");
+    }).join(", ");
+    table.append(el("tr", {}, el("td", {}, token), el("td", {}, name), el("td", {}, player.Money + "$"),
+      el("td", {}, properties), el("td", {}, status)));
+  });
+  document.getElementById("players").replaceChildren(table);
+}
+
+function log(message) {
+  const list = document.getElementById("log");
+  list.prepend(el("li", { textContent: message }));
+  while (list.children.length > 200) {
+    list.lastChild.remove();
+  }
+}
+
+function setStatus(text) {
+  document.getElementById("status").textContent = text;
+}
+
+function respond(value) {
+  const decision = document.getElementById("decision");
+  decision.replaceChildren();
+  decision.classList.remove("active");
+  socket.send(JSON.stringify({ Type: CLIENT_RESPONSE, Response: value }));
+  setStatus("Waiting for other players...");
+}
+
+function chatName(id) {
+  if (id === CHAT_ALL) {
+    return "everyone";
+  }
+  return id === playerId ? "you" : playerName(id);
+}
+
+function logChat(chat) {
+  let line = "[chat] " + chatName(chat.From) + " to " + chatName(chat.To);
+  if (chat.Trade) {
+    const side = (properties, money) => {
+      const parts = (properties || []).map(propertyName);
+      if (money > 0) {
+        parts.push(money + "$");
+      }
+      return parts.length ? parts.join(" + ") : "nothing";
+    };
+    line = "[trade] " + chatName(chat.From) + " to " + chatName(chat.To) + ": " +
+      side(chat.Trade.Give, chat.Trade.Money) + " for " + side(chat.Trade.Take, -chat.Trade.Money);
+  }
+  if (chat.Text) {
+    line += ": " + chat.Text;
+  }
+  log(line);
+}
+
+function showChat() {
+  const form = document.getElementById("chat");
+  if (form.classList.contains("active")) {
+    return;
+  }
+  const recipients = [el("option", { value: CHAT_ALL, textContent: "Everyone" })];
+  state.Players.forEach((player) => {
+    if (player.ID !== playerId) {
+      recipients.push(el("option", { value: player.ID, textContent: player.Name }));
+    }
+  });
+  document.getElementById("chat-to").replaceChildren(...recipients);
+  form.onsubmit = (event) => {
+    event.preventDefault();
+    const text = document.getElementById("chat-text");
+    if (text.value.trim() === "") {
+      return;
+    }
+    const to = parseInt(document.getElementById("chat-to").value, 10);
+    socket.send(JSON.stringify({ Type: CLIENT_CHAT, Chat: { To: to, Text: text.value } }));
+    text.value = "";
+  };
+  form.classList.add("active");
+}
+
+function showDecision(title, ...children) {
+  const decision = document.getElementById("decision");
+  decision.replaceChildren(el("div", {}, el("b", { textContent: title })), ...children);
+  if (canUndo) {
+    decision.append(button("Undo last decision", undo));
+  }
+  decision.classList.add("active");
+  setStatus("Your turn!");
+}
+
+function undo() {
+  const decision = document.getElementById("decision");
+  decision.replaceChildren();
+  decision.classList.remove("active");
+  socket.send(JSON.stringify({ Type: CLIENT_UNDO }));
+  setStatus("Taking back your last decision...");
+}
+
+function button(label, onClick) {
+  return el("button", { textContent: label, onclick: onClick });
+}
+
+function yesNo(title, onAnswer) {
+  showDecision(title, button("Yes", () => onAnswer(true)), button("No", () => onAnswer(false)));
+}
+
+function priceInput(title, initial, onSubmit) {
+  const input = el("input", { type: "number", min: 0, value: initial });
+  showDecision(title, input, button("OK", () => {
+    const price = parseInt(input.value, 10);
+    if (!Number.isNaN(price) && price >= 0) {
+      onSubmit(price);
+    }
+  }));
+}
+
+function chooseProperty(title, properties, onChoose) {
+  const buttons = properties.map((id) => button(propertyName(id), () => onChoose(id)));
+  showDecision(title, ...buttons);
+}
+
+function choosePlayers(onChoose) {
+  const boxes = [];
+  state.Players.forEach((player, idx) => {
+    if (idx !== playerId && !player.IsBankrupt) {
+      const box = el("input", { type: "checkbox", checked: true, value: idx });
+      boxes.push(box);
+    }
+  });
+  const labels = boxes.map((box) => el("label", {}, box, playerName(parseInt(box.value, 10))));
+  showDecision("Offer to:", ...labels, button("OK", () => {
+    onChoose(boxes.filter((box) => box.checked).map((box) => parseInt(box.value, 10)));
+  }));
+}
+
+function promptStdAction(req) {
+  const actions = req.StdActionList.Actions || [];
+  let title = "Choose an action";
+  if (req.State.Charge > 0) {
+    title = "You have to pay " + req.State.Charge + "$. Raise money:";
+  }
+  const buttons = actions.map((action) => button(STD_ACTION_NAMES[action], () => {
+    const details = { Action: action, PropertyId: 0, Price: 0, Players: [] };
+    if (!STD_ACTION_LISTS[action]) {
+      respond(details);
+      return;
+    }
+    const list = req.StdActionList[STD_ACTION_LISTS[action]] || [];
+    chooseProperty(STD_ACTION_NAMES[action] + ": choose property", list, (propertyId) => {
+      details.PropertyId = propertyId;
+      if (action === SELLOFFER) {
+        choosePlayers((players) => {
+          details.Players = players;
+          priceInput("Price", state.Properties[propertyId].Price, (price) => {
+            details.Price = price;
+            respond(details);
+          });
+        });
+      } else if (action === BUYOFFER) {
+        priceInput("Price", state.Properties[propertyId].Price, (price) => {
+          details.Price = price;
+          respond(details);
+        });
+      } else {
+        respond(details);
+      }
+    });
+  }));
+  showDecision(title, ...buttons);
+}
+
+function handleRequest(req) {
+  if (req.State && req.State.Players) {
+    state = req.State;
+    render();
+    showChat();
+  }
+  const currentName = state ? playerName(state.CurrentPlayerIdx) : "";
+  if (req.Type !== REQUEST.CHAT_UPDATE && req.Type !== REQUEST.STATE_UPDATE) {
+    canUndo = req.CanUndo;
+  }
+  switch (req.Type) {
+    case REQUEST.STATE_UPDATE:
+      log(req.Message);
+      break;
+    case REQUEST.CHAT_UPDATE:
+      logChat(req.Chat);
+      break;
+    case REQUEST.GAME_FINISHED:
+      if (req.FinishOption === FINISH_DRAW) {
+        setStatus("Game ended in a draw!");
+      } else if (req.FinishOption === FINISH_CANCELLED) {
+        setStatus("Game was cancelled by the server.");
+      } else {
+        setStatus("Game over. " + playerName(req.Winner) + " wins!");
+      }
+      break;
+    case REQUEST.GET_STD_ACTION:
+      promptStdAction(req);
+      break;
+    case REQUEST.GET_JAIL_ACTION:
+      showDecision("You are in jail", ...req.JailActionList.map((action) =>
+        button(JAIL_ACTION_NAMES[action], () => respond(action))));
+      break;
+    case REQUEST.BUY_DECISION:
+      yesNo("Buy " + propertyName(req.PropertyId) + " for " + state.Properties[req.PropertyId].Price + "$?", respond);
+      break;
+    case REQUEST.BUY_FROM_PLAYER_DECISION:
+      yesNo(currentName + " offers you " + propertyName(req.PropertyId) + " for " + req.Price + "$. Buy?", respond);
+      break;
+    case REQUEST.SELL_TO_PLAYER_DECISION:
+      yesNo(currentName + " wants to buy " + propertyName(req.PropertyId) + " for " + req.Price + "$. Sell?", respond);
+      break;
+    case REQUEST.BIDDING_DECISION: {
+      const title = "Auction for " + propertyName(req.PropertyId) + ". Current price: " + req.Price + "$";
+      const input = el("input", { type: "number", min: req.Price + 1, value: req.Price + 10 });
+      showDecision(title, input, button("Bid", () => {
+        const bid = parseInt(input.value, 10);
+        if (!Number.isNaN(bid)) {
+          respond(bid);
+        }
+      }), button("Pass", () => respond(0)));
+      break;
+    }
+    default:
+      log("Unknown request type: " + req.Type);
+  }
+}
+
+function connect(tableId, humanPlayers) {
+  const password = document.getElementById("join-password").value;
+  const token = document.getElementById("join-token").value;
+  const allowUndo = document.getElementById("table-undo").checked;
+  document.getElementById("join").remove();
+  const protocol = location.protocol === "https:" ? "wss://" : "ws://";
+  socket = new WebSocket(protocol + location.host + "/ws");
+  socket.onopen = () => {
+    socket.send(JSON.stringify({ TableID: tableId, HumanPlayers: humanPlayers, Password: password, Token: token, AllowUndo: allowUndo }));
+    setStatus("Waiting for a seat at " + tableId + "...");
+  };
+  socket.onmessage = (event) => {
+    const message = JSON.parse(event.data);
+    if (playerId === null) {
+      if (message.Error) {
+        setStatus("Cannot join table: " + message.Error);
+        return;
+      }
+      playerId = message.PlayerId;
+      setStatus("Joined " + message.TableID + " as player " + playerId + ". Waiting for the game to start...");
+      for (const invite of message.Invites || []) {
+        const link = location.origin + location.pathname + "?table=" + encodeURIComponent(message.TableID) +
+          "&token=" + encodeURIComponent(invite);
+        log("Invite link for another player: " + link);
+      }
+      return;
+    }
+    handleRequest(message);
+  };
+  socket.onclose = () => log("Disconnected from server");
+}
+
+function showTables() {
+  const tableInput = document.getElementById("table-id");
+  const humansInput = document.getElementById("table-humans");
+  document.getElementById("join-button").onclick = () => {
+    connect(tableInput.value, parseInt(humansInput.value, 10) || 0);
+  };
+  fetch("tables")
+    .then((resp) => resp.json())
+    .then((tables) => {
+      const items = tables.filter((t) => !t.Started).map((t) => el("li", {
+        textContent: t.ID + " (" + t.FreeSeats + "/" + t.HumanPlayers + " seats free" + (t.AllowUndo ? ", undo allowed" : "") + ")",
+        onclick: () => connect(t.ID, 0),
+      }));
+      document.getElementById("tables").replaceChildren(...items);
+    });
+}
+
+fetch("board")
+  .then((resp) => resp.json())
+  .then((data) => {
+    board = data;
+    createBoard();
+    const params = new URLSearchParams(location.search);
+    document.getElementById("join-token").value = params.get("token") || "";
+    if (params.get("table")) {
+      document.getElementById("table-id").value = params.get("table");
+    }
+    showTables();
+  });